@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// commitFunction routes a plain git commit to git cc in the shell. git never
+// runs an alias named after one of its own commands, so git commit can't be
+// replaced by an alias.
+const commitFunction = `# git commit prompts with git cc, GIT_CC_SKIP=1 or any other flag runs git commit
+git() {
+  if [ "$1" = commit ] && [ -z "$GIT_CC_SKIP" ]; then
+    shift
+    for arg; do
+      case "$arg" in -a|--all|--amend) ;; *) command git commit "$@"; return ;; esac
+    done
+    command git cc commit "$@"
+  else
+    command git "$@"
+  fi
+}`
+
+var installAliasCmd = &cobra.Command{
+	Use:   "install-alias",
+	Short: "Set up git cc as a git alias",
+	Long: `Set up an alias running this git-cc binary, so git cc works even when
+git-cc isn't on the PATH, in the repository config or with --global for
+every repository. Existing aliases are never overwritten unless --force is
+given.
+
+git doesn't allow aliases to replace its own commands. --commit prints a
+shell function routing a plain git commit to git cc instead, to be added to
+your shell's startup file; setting GIT_CC_SKIP or passing flags other than
+--all and --amend runs git commit as before.`,
+	Example: `  git-cc install-alias --global
+  git-cc install-alias --name c
+  git-cc install-alias --commit >> ~/.bashrc`,
+	Args:        cobra.NoArgs,
+	Annotations: outsideRepositoryCommand,
+	Run:         installAlias,
+}
+
+func init() {
+	installAliasCmd.Flags().String("name", "cc", "Name of the alias")
+	installAliasCmd.Flags().Bool("global", false, "Install the alias into the global git config instead of the repository's")
+	installAliasCmd.Flags().Bool("commit", false, "Print a shell function running git cc for a plain git commit")
+	installAliasCmd.Flags().Bool("uninstall", false, "Remove the alias installed by git-cc")
+	installAliasCmd.Flags().Bool("force", false, "Overwrite an existing alias")
+	rootCmd.AddCommand(installAliasCmd)
+}
+
+func installAlias(cmd *cobra.Command, args []string) {
+	if commit, _ := cmd.Flags().GetBool("commit"); commit {
+		fmt.Println(commitFunction)
+		return
+	}
+
+	name, _ := cmd.Flags().GetString("name")
+	global, _ := cmd.Flags().GetBool("global")
+	uninstall, _ := cmd.Flags().GetBool("uninstall")
+	force, _ := cmd.Flags().GetBool("force")
+
+	scope := "--local"
+	if global {
+		scope = "--global"
+	} else if repo == nil {
+		pterm.Error.Println("not in a git repository, use --global to install the alias for all of them")
+		exit(exitcode.Failure)
+	}
+	key := "alias." + name
+
+	existing, err := gitConfigValue(scope, key)
+	if err != nil {
+		pterm.Error.Println("Failed to read git config:", err)
+		exit(exitcode.Failure)
+	}
+
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		pterm.Error.Println("Failed to locate the git-cc binary:", err)
+		exit(exitcode.Failure)
+	}
+	alias := "!" + shellQuote(exe)
+
+	if uninstall {
+		if existing == "" {
+			pterm.Info.Printfln("No %s alias installed", name)
+			return
+		}
+		if existing != alias && !strings.Contains(existing, "git-cc") && !force {
+			pterm.Error.Printfln("alias %s runs %q, which is not git-cc, use --force to remove it anyway", name, existing)
+			exit(exitcode.Failure)
+		}
+		if _, err := gitOutput("config", scope, "--unset", key); err != nil {
+			pterm.Error.Println("Failed to remove alias:", err)
+			exit(exitcode.Failure)
+		}
+		pterm.Success.Printfln("Removed alias git %s", name)
+		return
+	}
+
+	switch {
+	case existing == alias:
+		pterm.Info.Printfln("Alias git %s is already installed", name)
+		return
+	case existing != "" && !force:
+		pterm.Error.Printfln("alias %s already runs %q, use --force to overwrite it or --name to pick another name", name, existing)
+		exit(exitcode.Failure)
+	}
+
+	if _, err := gitOutput("config", scope, key, alias); err != nil {
+		pterm.Error.Println("Failed to install alias:", err)
+		exit(exitcode.Failure)
+	}
+	pterm.Success.Printfln("Installed alias git %s running %s", name, exe)
+}
+
+// gitConfigValue returns the value of key in the config given by scope, or
+// an empty string when it isn't set
+func gitConfigValue(scope, key string) (string, error) {
+	out, err := gitOutput("config", scope, "--get", key)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return "", nil
+	}
+	return strings.TrimSpace(out), err
+}
+
+// shellQuote quotes s for sh, which runs the aliases starting with !
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var amendCmd = &cobra.Command{
+	Use:   "amend",
+	Short: "Change parts of the last commit message",
+	Long: `Change parts of the last commit message given by flags, keeping everything
+else as it is.
+
+With --no-edit the message is rewritten straight away without any prompts
+and without adding staged changes to the commit. Otherwise the prompts start
+pre-filled with the changed message, like git cc --amend.`,
+	Example: `  git cc amend --no-edit --scope api
+  git cc amend --no-edit --add-footer "Refs: PROJ-1"
+  git cc amend --no-edit --type fix --remove-footer Refs`,
+	Args: cobra.NoArgs,
+	Run:  amendLast,
+}
+
+func init() {
+	amendCmd.Flags().String("type", "", "New commit type")
+	amendCmd.Flags().String("scope", "", "New scope, empty to remove it")
+	amendCmd.Flags().String("message", "", "New short description")
+	amendCmd.Flags().String("body", "", "New long description")
+	amendCmd.Flags().Bool("breaking", false, "Mark or unmark the commit as a breaking change")
+	amendCmd.Flags().StringArray("breaking-note", nil, "New breaking change note, implies --breaking, may be repeated")
+	amendCmd.Flags().StringArray("add-footer", nil, "Footer to add as \"Token: value\", may be repeated")
+	amendCmd.Flags().StringArray("remove-footer", nil, "Token of footers to remove, may be repeated")
+	amendCmd.Flags().Bool("no-edit", false, "Rewrite the message without prompting")
+	registerTypeCompletion(amendCmd, "type", "scope")
+	amendCmd.RegisterFlagCompletionFunc("add-footer", completeFooters)
+	rootCmd.AddCommand(amendCmd)
+}
+
+func amendLast(cmd *cobra.Command, args []string) {
+	if err := loadAmendDefaults(); err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+	data, err := applyAmendFlags(cmd, promptDefaults)
+	if err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+
+	if noEdit, _ := cmd.Flags().GetBool("no-edit"); !noEdit {
+		promptDefaults = data
+		amend = true
+		if err := runCommit(); errors.Is(err, errCommitAborted) {
+			pterm.Info.Println(tr("Commit aborted"))
+			exit(exitcode.Aborted)
+		} else if err != nil {
+			commitFailed(err)
+		}
+		return
+	}
+
+	if err := validateCommitData(data); err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+	target, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		pterm.Error.Println("Failed to read commit:", err)
+		exit(exitcode.Failure)
+	}
+
+	message, err := renderCommitMessage(data)
+	if err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+	if err := rewriteMessage(target, message); err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.CommitFailed)
+	}
+	header, _, _ := strings.Cut(message, "\n")
+	pterm.Success.Printfln("Amended %s", header)
+}
+
+// applyAmendFlags changes the parts of data given by the flags of cmd
+func applyAmendFlags(cmd *cobra.Command, data CommitPromptData) (CommitPromptData, error) {
+	flags := cmd.Flags()
+	if flags.Changed("type") {
+		data.Type, _ = flags.GetString("type")
+	}
+	if flags.Changed("scope") {
+		data.Scope, _ = flags.GetString("scope")
+	}
+	if flags.Changed("message") {
+		data.ShortDescription, _ = flags.GetString("message")
+	}
+	if flags.Changed("body") {
+		data.LongDescription, _ = flags.GetString("body")
+	}
+	if flags.Changed("breaking") {
+		data.BreakingChange, _ = flags.GetBool("breaking")
+		if !data.BreakingChange {
+			data.BreakingChangeNote, data.BreakingChangeNotes = "", nil
+		}
+	}
+	if flags.Changed("breaking-note") {
+		data.BreakingChangeNote = ""
+		data.BreakingChangeNotes, _ = flags.GetStringArray("breaking-note")
+		data.BreakingChange = true
+	}
+
+	remove, _ := flags.GetStringArray("remove-footer")
+	var footers []trailer
+	for _, t := range data.Footers {
+		if !containsFold(remove, t.Token) {
+			footers = append(footers, t)
+		}
+	}
+	add, _ := flags.GetStringArray("add-footer")
+	for _, value := range add {
+		t, err := parseTrailerArg(value)
+		if err != nil {
+			return data, err
+		}
+		footers = setTrailer(footers, t, false)
+	}
+	data.Footers = footers
+
+	return data, nil
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate [commit]",
+	Short: "Add or change trailers of an existing unpushed commit",
+	Long: `Add or change trailers (ticket references, co-authors, reviewers, ...) of an
+existing commit which hasn't been pushed yet. HEAD is amended directly,
+older commits are rewritten and the commits on top of them rebased.
+
+Without --trailer the trailers are prompted for interactively.`,
+	Example: `  git cc annotate --trailer "Refs: PROJ-123"
+  git cc annotate HEAD~2 --trailer "Reviewed-by: Jane Doe <jane@example.com>"
+  git cc annotate --replace --trailer "Refs: PROJ-124"`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  annotate,
+}
+
+func init() {
+	annotateCmd.Flags().StringArray("trailer", nil, "Trailer to add as \"Token: value\", may be repeated")
+	annotateCmd.Flags().Bool("replace", false, "Replace existing trailers with the same token instead of adding another one")
+	annotateCmd.Flags().Bool("force", false, "Rewrite the commit even if it has already been pushed")
+	rootCmd.AddCommand(annotateCmd)
+}
+
+func annotate(cmd *cobra.Command, args []string) {
+	rev := "HEAD"
+	if len(args) > 0 {
+		rev = args[0]
+	}
+	replace, _ := cmd.Flags().GetBool("replace")
+	force, _ := cmd.Flags().GetBool("force")
+	values, _ := cmd.Flags().GetStringArray("trailer")
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		pterm.Error.Printfln("unknown revision %s: %s", rev, err)
+		exit(exitcode.Failure)
+	}
+	target, err := repo.CommitObject(*hash)
+	if err != nil {
+		pterm.Error.Println("Failed to read commit:", err)
+		exit(exitcode.Failure)
+	}
+
+	if !inCheckedOutHistory(*hash) {
+		pterm.Error.Printfln("%s isn't part of the checked out history, check out its branch first", hash.String()[:7])
+		exit(exitcode.Failure)
+	}
+	if !force {
+		if remotes, _ := gitOutput("branch", "--remotes", "--contains", hash.String()); strings.TrimSpace(remotes) != "" {
+			pterm.Error.Printfln("%s has already been pushed, rewriting it would rewrite published history (use --force to do it anyway)", hash.String()[:7])
+			exit(exitcode.Failure)
+		}
+	}
+
+	var trailers []trailer
+	for _, value := range values {
+		t, err := parseTrailerArg(value)
+		if err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Failure)
+		}
+		trailers = append(trailers, t)
+	}
+	if len(trailers) == 0 {
+		trailers = promptForTrailers()
+	}
+	if len(trailers) == 0 {
+		pterm.Info.Println("No trailers given, nothing to do")
+		return
+	}
+
+	header, rest, _ := strings.Cut(strings.TrimSpace(target.Message), "\n")
+	body, existing := splitTrailers(rest)
+	for _, t := range trailers {
+		existing = setTrailer(existing, t, replace)
+	}
+	message := strings.TrimSpace(header + "\n\n" + joinTrailers(body, existing))
+
+	if err := rewriteMessage(target, message); err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.CommitFailed)
+	}
+	pterm.Success.Printfln("Annotated %s", header)
+}
+
+// parseTrailerArg parses a trailer given as "Token: value", "Token=value"
+// or "Token #value"
+func parseTrailerArg(value string) (trailer, error) {
+	if match := footerPattern.FindStringSubmatch(value); match != nil {
+		return trailer{Token: match[1], Separator: match[2], Value: match[3]}, nil
+	}
+	if token, v, ok := strings.Cut(value, "="); ok && !strings.ContainsAny(token, " :") && token != "" {
+		return trailer{Token: token, Separator: ": ", Value: strings.TrimSpace(v)}, nil
+	}
+	return trailer{}, fmt.Errorf("invalid trailer %q, expected \"Token: value\"", value)
+}
+
+// promptForTrailers asks for trailers until the user is done, suggesting
+// the configured footer_keys
+func promptForTrailers() []trailer {
+	var trailers []trailer
+	options := append(append([]string{}, viper.GetStringSlice("footer_keys")...), tr("Other"))
+	for {
+		token, _ := ui.Select(tr("Trailer"), options, "")
+		if token == tr("Other") {
+			token, _ = ui.Input(tr("Trailer Token"), "")
+			token = strings.ReplaceAll(strings.TrimSpace(token), " ", "-")
+		}
+		value, _ := ui.Input(token, "")
+		value = strings.TrimSpace(value)
+		if token != "" && !footerTokenPattern.MatchString(token) {
+			pterm.Warning.Println(tr("Skipping %q, a trailer token is a single word such as Refs", token))
+		} else if token != "" && value != "" {
+			// Fixes #123 uses the # separator the spec allows
+			separator := ": "
+			if issue, ok := strings.CutPrefix(value, "#"); ok && issue != "" {
+				separator, value = " #", issue
+			}
+			trailers = append(trailers, trailer{Token: token, Separator: separator, Value: value})
+		}
+
+		more, _ := ui.Confirm(tr("Add another trailer"), false)
+		if !more {
+			return trailers
+		}
+	}
+}
+
+// rewriteMessage replaces the message of target. HEAD is amended in place,
+// an older commit is recreated with the new message and the commits on top
+// of it are rebased onto the new one.
+func rewriteMessage(target *object.Commit, message string) error {
+	file, err := writeMessageFile(message + "\n")
+	if err != nil {
+		return err
+	}
+	defer atExit(func() { removeMessageFile(file) })()
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	if head.Hash() == target.Hash {
+		// --only leaves anything staged out of the amended commit
+		cmd := exec.Command("git", "commit", "--amend", "--only", "--quiet", "-F", file)
+		cmd.Dir = gitRoot
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		passTerminal(cmd)
+		return cmd.Run()
+	}
+
+	// rebasing onto a commit of another branch would rewrite the wrong history
+	if !inCheckedOutHistory(target.Hash) {
+		return fmt.Errorf("%s isn't part of the checked out history, check out its branch first", target.Hash.String()[:7])
+	}
+
+	args := []string{"commit-tree", target.TreeHash.String(), "-F", file}
+	for _, parent := range target.ParentHashes {
+		args = append(args, "-p", parent.String())
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = gitRoot
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+target.Author.Name,
+		"GIT_AUTHOR_EMAIL="+target.Author.Email,
+		"GIT_AUTHOR_DATE="+target.Author.When.Format("2006-01-02T15:04:05-07:00"),
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to rewrite commit: %w", err)
+	}
+	rewritten := strings.TrimSpace(string(out))
+
+	cmd = exec.Command("git", "rebase", "--quiet", "--autostash", "--rebase-merges", "--onto", rewritten, target.Hash.String())
+	cmd.Dir = gitRoot
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to rebase onto the rewritten commit: %w", err)
+	}
+	return nil
+}
+
+// inCheckedOutHistory reports whether hash is HEAD or one of its ancestors
+func inCheckedOutHistory(hash plumbing.Hash) bool {
+	_, err := gitQuiet("merge-base", "--is-ancestor", hash.String(), "HEAD")
+	return err == nil
+}
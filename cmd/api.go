@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+const (
+	// retries for rate limited or failing API requests
+	apiRetries = 3
+	// longest wait for a rate limit to reset before giving up, prompts
+	// shouldn't hang on an exhausted quota
+	apiMaxBackoff = 10 * time.Second
+)
+
+var apiClient = &http.Client{Timeout: 15 * time.Second}
+
+// apiError is a response of an API rejecting a request
+type apiError struct {
+	method string
+	url    string
+	status string
+	code   int
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.method, e.url, e.status)
+}
+
+// apiGet fetches url from a hosted API. Responses are cached per repository
+// for api_cache_ttl so interactive lookups stay fast, rate limits are
+// respected with backoff, and a stale cached response is served when the
+// API can't be reached.
+func apiGet(url string, token string) ([]byte, error) {
+	if token != "" {
+		return apiGetAuthorized(url, "Bearer "+token)
+	}
+	return apiGetAuthorized(url, "")
+}
+
+// apiGetAuthorized is apiGet sending authorization as the Authorization
+// header, for APIs not taking bearer tokens
+func apiGetAuthorized(url string, authorization string) ([]byte, error) {
+	sum := sha256.Sum256([]byte(url))
+	cacheFile := filepath.Join(gitDir(), "git-cc", "cache", hex.EncodeToString(sum[:]))
+
+	cached, cacheErr := os.ReadFile(cacheFile)
+	if cacheErr == nil {
+		if info, err := os.Stat(cacheFile); err == nil && time.Since(info.ModTime()) < viper.GetDuration("api_cache_ttl") {
+			pterm.Debug.Println("Using cached response for", url)
+			return cached, nil
+		}
+	}
+
+	body, err := apiFetch(url, authorization)
+	if err != nil {
+		if cacheErr == nil {
+			pterm.Debug.Printfln("Using stale cached response for %s: %s", url, err)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	if readOnly() {
+		return body, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0o700); err == nil {
+		if err := os.WriteFile(cacheFile, body, 0o600); err != nil {
+			pterm.Debug.Println("Failed to cache response:", err)
+		}
+	}
+
+	return body, nil
+}
+
+func apiFetch(url string, authorization string) ([]byte, error) {
+	return apiRequest(http.MethodGet, url, authorization, nil)
+}
+
+// apiRequest sends a request with a JSON body, if any, to a hosted API and
+// returns the response body. Rate limits are waited for; failing servers
+// are retried for GET only, as another request might repeat a change.
+func apiRequest(method, url, authorization string, payload []byte) ([]byte, error) {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := apiClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		wait := backoff
+		switch {
+		case resp.StatusCode < 300:
+			return body, nil
+		case rateLimited(resp):
+			wait = rateLimitReset(resp)
+		case resp.StatusCode < 500 || method != http.MethodGet:
+			return nil, &apiError{method: method, url: url, status: resp.Status, code: resp.StatusCode}
+		}
+
+		if attempt+1 >= apiRetries || wait > apiMaxBackoff {
+			return nil, fmt.Errorf("%s %s: %s (giving up after %d attempts)", method, url, resp.Status, attempt+1)
+		}
+
+		pterm.Debug.Printfln("%s %s: %s, retrying in %s", method, url, resp.Status, wait)
+		time.Sleep(wait)
+		backoff *= 2
+	}
+}
+
+// rateLimited reports whether the response is a GitHub/GitLab rate limit
+// rejection rather than a permission error
+func rateLimited(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode == http.StatusForbidden &&
+		(resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("RateLimit-Remaining") == "0")
+}
+
+// rateLimitReset returns how long to wait before the quota is available
+// again, based on Retry-After or the GitHub/GitLab reset headers
+func rateLimitReset(resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	for _, header := range []string{"X-RateLimit-Reset", "RateLimit-Reset"} {
+		if reset, err := strconv.ParseInt(resp.Header.Get(header), 10, 64); err == nil {
+			return time.Until(time.Unix(reset, 0))
+		}
+	}
+
+	return apiMaxBackoff
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// promptBanner describes where and as whom the commit is going to be made,
+// e.g. "git-cc on main as Jane Doe <jane@example.com>, signed (ssh)"
+func promptBanner() string {
+	branch := "no branch"
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		branch = head.Name().Short()
+	} else if err == nil {
+		branch = "detached HEAD " + head.Hash().String()[:7]
+	} else if ref, err := repo.Storer.Reference("HEAD"); err == nil {
+		// unborn branch of a fresh repository
+		branch = ref.Target().Short()
+	}
+
+	author := pterm.Red("no identity configured")
+	if cfg, err := repo.ConfigScoped(config.SystemScope); err == nil {
+		if ident, err := commitSignature(cfg, "AUTHOR"); err == nil {
+			author = fmt.Sprintf("%s <%s>", ident.Name, ident.Email)
+		}
+	}
+
+	signing := pterm.Yellow("unsigned")
+	if signed := gitConfigBool("commit", "gpgsign") || gpgSign != "" || viper.GetBool("sign"); signed && !noGPGSign {
+		format := gitConfigOption("gpg", "format")
+		if format == "" {
+			format = "openpgp"
+		}
+		signing = pterm.Green("signed (" + format + ")")
+	}
+
+	return pterm.Bold.Sprint(filepath.Base(gitRoot)) + " on " + pterm.Cyan(branch) + " as " + author + ", " + signing
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var bisectCmd = &cobra.Command{
+	Use:   "bisect",
+	Short: "Skip the commits a bisect can leave out by their type and scope",
+	Long: `Mark the commits of a running git bisect which can't have caused the bug
+as skipped, by their conventional type and scope, so git bisect checks out
+fewer commits.
+
+Commits of the types of --skip-type, docs, style and ci unless
+bisect_skip_types says otherwise, are skipped. --type and --scope narrow
+the search down to the commits of these types and scopes, the others are
+skipped as well. Commits which aren't conventional are never skipped.
+
+Run it after git bisect start has marked a good and a bad commit, and again
+whenever the remaining range should be narrowed down further.`,
+	Example: `  git bisect start HEAD v1.2.0
+  git cc bisect
+  git cc bisect --type feat,fix --scope api`,
+	Args: cobra.NoArgs,
+	Run:  bisect,
+}
+
+func init() {
+	bisectCmd.Flags().StringSlice("type", nil, "Only bisect commits of these types")
+	bisectCmd.Flags().StringSlice("scope", nil, "Only bisect commits of these scopes")
+	bisectCmd.Flags().StringSlice("skip-type", nil, "Skip commits of these types (default bisect_skip_types)")
+	registerTypeCompletion(bisectCmd, "type", "scope")
+	bisectCmd.RegisterFlagCompletionFunc("skip-type", completeTypes)
+	rootCmd.AddCommand(bisectCmd)
+}
+
+func bisect(cmd *cobra.Command, args []string) {
+	types, _ := cmd.Flags().GetStringSlice("type")
+	scopeFilter, _ := cmd.Flags().GetStringSlice("scope")
+	skipTypes := viper.GetStringSlice("bisect_skip_types")
+	if cmd.Flags().Changed("skip-type") {
+		skipTypes, _ = cmd.Flags().GetStringSlice("skip-type")
+	}
+
+	if _, err := os.Stat(filepath.Join(gitDir(), "BISECT_START")); err != nil {
+		pterm.Error.Println("No bisect is running, start one with git bisect start")
+		exit(exitcode.Failure)
+	}
+	bad, err := gitQuiet("rev-parse", "--verify", "--quiet", "refs/bisect/bad")
+	if err != nil {
+		pterm.Error.Println("Mark a bad commit first, with git bisect bad")
+		exit(exitcode.Failure)
+	}
+	goods, _ := gitQuiet("for-each-ref", "--format=%(objectname)", "refs/bisect/good-*")
+	if goods == "" {
+		pterm.Error.Println("Mark a good commit first, with git bisect good")
+		exit(exitcode.Failure)
+	}
+	skipped, _ := gitQuiet("for-each-ref", "--format=%(objectname)", "refs/bisect/skip-*")
+
+	// the commits left to bisect, without the bad and skipped ones
+	out, err := gitOutput(append([]string{"rev-list", bad + "^@", "--not"}, strings.Fields(goods)...)...)
+	if err != nil {
+		pterm.Error.Println("Failed to list the bisected commits:", err)
+		exit(exitcode.Failure)
+	}
+	remaining := slices.DeleteFunc(strings.Fields(out), func(hash string) bool {
+		return strings.Contains(skipped, hash)
+	})
+
+	var skip []string
+	for _, hash := range remaining {
+		commit, err := repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			pterm.Error.Println("Failed to read commit:", err)
+			exit(exitcode.Failure)
+		}
+		c := parseHistoryCommit(commit)
+		if !c.Valid {
+			continue
+		}
+		if slices.Contains(skipTypes, c.Data.Type) ||
+			len(types) > 0 && !slices.Contains(types, c.Data.Type) ||
+			len(scopeFilter) > 0 && !slices.Contains(scopeFilter, c.Data.Scope) {
+			skip = append(skip, hash)
+		}
+	}
+	if len(skip) == 0 {
+		pterm.Info.Printfln("None of the %s remaining commits can be skipped", formatNumber(len(remaining)))
+		return
+	}
+
+	pterm.Info.Printfln("Skipping %s of %s remaining commits", formatNumber(len(skip)), formatNumber(len(remaining)))
+	// git bisect checks out the next commit to test and says which
+	git := exec.Command("git", append([]string{"bisect", "skip"}, skip...)...)
+	git.Dir = gitRoot
+	git.Stdout = os.Stdout
+	git.Stderr = os.Stderr
+	if err := git.Run(); err != nil {
+		pterm.Error.Println("git bisect skip failed:", err)
+		exit(exitcode.Failure)
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var blameTypeCmd = &cobra.Command{
+	Use:   "blame-type <path>...",
+	Short: "Show which kinds of changes touched a path",
+	Long: `Count the conventional commit types and scopes of the commits touching the
+given files or directories, to tell whether code is mostly fixed, extended
+or refactored. Renames of a single file are followed.`,
+	Example: `  git cc blame-type cmd/commit.go
+  git cc blame-type --since v1.0.0 pkg/`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.MinimumNArgs(1),
+	Run:         blameType,
+}
+
+func init() {
+	blameTypeCmd.Flags().String("since", "", "Count commits after this ref (default: whole history)")
+	blameTypeCmd.Flags().String("until", "HEAD", "Count commits up to this ref")
+	rootCmd.AddCommand(blameTypeCmd)
+}
+
+func blameType(cmd *cobra.Command, args []string) {
+	since, _ := cmd.Flags().GetString("since")
+	until, _ := cmd.Flags().GetString("until")
+
+	revisions := until
+	if since != "" {
+		revisions = since + ".." + until
+	}
+	logArgs := []string{"log", "--format=%H", "--no-merges", revisions}
+	// git can only follow the renames of a single file
+	if len(args) == 1 {
+		if info, err := os.Stat(args[0]); err == nil && !info.IsDir() {
+			logArgs = append(logArgs, "--follow")
+		}
+	}
+	// paths are given relative to the working directory, git runs in the root
+	logArgs = append(logArgs, "--")
+	for _, arg := range args {
+		if abs, err := filepath.Abs(arg); err == nil {
+			if rel, err := filepath.Rel(gitRoot, abs); err == nil {
+				arg = filepath.ToSlash(rel)
+			}
+		}
+		logArgs = append(logArgs, arg)
+	}
+
+	out, err := gitOutput(logArgs...)
+	if err != nil {
+		pterm.Error.Println("Failed to read history:", err)
+		exit(exitcode.Failure)
+	}
+	hashes := strings.Fields(out)
+	if len(hashes) == 0 {
+		pterm.Info.Printfln("No commits touch %s", strings.Join(args, ", "))
+		return
+	}
+
+	types := map[string]int{}
+	scopeCounts := map[string]int{}
+	breakingCount := 0
+	for _, hash := range hashes {
+		commit, err := repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			pterm.Error.Println("Failed to read commit:", err)
+			exit(exitcode.Failure)
+		}
+		c := parseHistoryCommit(commit)
+		if !c.Valid {
+			types["(not conventional)"]++
+			continue
+		}
+		types[c.Data.Type]++
+		if c.Data.Scope != "" {
+			scopeCounts[c.Data.Scope]++
+		}
+		if c.Data.BreakingChange {
+			breakingCount++
+		}
+	}
+
+	pterm.Info.Printfln("%d commits touch %s", len(hashes), strings.Join(args, ", "))
+	renderCounts("Type", types, len(hashes))
+	if len(scopeCounts) > 0 {
+		fmt.Println()
+		renderCounts("Scope", scopeCounts, len(hashes))
+	}
+	if breakingCount > 0 {
+		pterm.Warning.Printfln("%d of them are breaking changes", breakingCount)
+	}
+}
+
+// renderCounts prints a table of counts, the most frequent first, with
+// their share of total
+func renderCounts(label string, counts map[string]int, total int) {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+
+	table := pterm.TableData{{label, "Commits", "Share"}}
+	for _, name := range names {
+		table = append(table, []string{name, formatNumber(counts[name]), fmt.Sprintf("%d%%", counts[name]*100/total)})
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(table).Render()
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// githubWorkflow validates the commits of every pull request
+const githubWorkflow = `# installed by git-cc bootstrap
+name: Conventional Commits
+
+on:
+  pull_request:
+
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          fetch-depth: 0
+      - uses: actions/setup-go@v5
+        with:
+          go-version: stable
+      - run: go install github.com/45413/git-cc@latest
+      - run: git-cc lint --range ${{ github.event.pull_request.base.sha }}..${{ github.event.pull_request.head.sha }}
+`
+
+// gitlabJob validates the commits of every merge request
+const gitlabJob = `git-cc:
+  image: golang:latest
+  rules:
+    - if: $CI_PIPELINE_SOURCE == "merge_request_event"
+  variables:
+    GIT_DEPTH: 0
+  script:
+    - go install github.com/45413/git-cc@latest
+    - git-cc lint --range $CI_MERGE_REQUEST_DIFF_BASE_SHA..$CI_COMMIT_SHA
+`
+
+var bootstrapCmd = &cobra.Command{
+	Use:   "bootstrap",
+	Short: "Set up a repository for conventional commits",
+	Long: `Set up a repository for conventional commits in one go: write a
+.git-cc.yaml as git cc init does, install the commit-msg hook and add a CI
+job validating the commits of every pull request with git cc lint --range.
+
+Existing configs, hooks and workflows are kept. The CI system is detected
+from .gitlab-ci.yml and .github, --ci picks it explicitly. A GitHub Actions
+workflow is written to .github/workflows/git-cc.yml, the GitLab CI job is
+printed to be added to .gitlab-ci.yml.`,
+	Example: `  git cc bootstrap
+  git cc bootstrap --ci gitlab`,
+	Args: cobra.NoArgs,
+	Run:  bootstrap,
+}
+
+func init() {
+	bootstrapCmd.Flags().String("ci", "auto", "CI system to add the lint job for, github, gitlab, none or auto")
+	rootCmd.AddCommand(bootstrapCmd)
+}
+
+func bootstrap(cmd *cobra.Command, args []string) {
+	ci, _ := cmd.Flags().GetString("ci")
+	if ci == "auto" {
+		ci = detectCI()
+	}
+	if ci != "github" && ci != "gitlab" && ci != "none" {
+		pterm.Error.Printfln("unknown CI system %q, use github, gitlab, none or auto", ci)
+		exit(exitcode.Failure)
+	}
+
+	failed := false
+
+	if existing := findConfig(gitRoot, ".git-cc"); existing != "" {
+		pterm.Info.Printfln("Keeping the existing %s", existing)
+	} else {
+		path := filepath.Join(gitRoot, ".git-cc.yaml")
+		if err := os.WriteFile(path, []byte(askRepoConfig().yaml()), 0o644); err != nil {
+			pterm.Error.Println("Failed to write config:", err)
+			failed = true
+		} else {
+			pterm.Success.Println("Wrote", path)
+		}
+	}
+
+	if dir, err := hooksDir(); err != nil {
+		pterm.Error.Println("Failed to locate hooks directory:", err)
+		failed = true
+	} else if manager := hookManager(dir); manager != "" {
+		pterm.Warning.Printfln("hooks are managed by %s, add git cc lint \"$1\" as its commit-msg hook", manager)
+	} else if err := installHook(dir, "commit-msg", false); err != nil {
+		pterm.Warning.Println(err)
+	}
+
+	switch ci {
+	case "github":
+		if err := writeWorkflow(filepath.Join(gitRoot, ".github", "workflows", "git-cc.yml")); err != nil {
+			pterm.Error.Println("Failed to write workflow:", err)
+			failed = true
+		}
+	case "gitlab":
+		pterm.Info.Println("Add this job to .gitlab-ci.yml to validate the commits of merge requests:")
+		fmt.Println()
+		fmt.Print(gitlabJob)
+	}
+
+	if failed {
+		exit(exitcode.Failure)
+	}
+	pterm.Success.Println("Commit the changes to share the setup with everyone working on the repository")
+}
+
+// detectCI returns the CI system the repository uses or is hosted for
+func detectCI() string {
+	if _, err := os.Stat(filepath.Join(gitRoot, ".gitlab-ci.yml")); err == nil {
+		return "gitlab"
+	}
+	if _, err := os.Stat(filepath.Join(gitRoot, ".github")); err == nil {
+		return "github"
+	}
+
+	origin, _ := gitOutput("remote", "get-url", "origin")
+	switch {
+	case strings.Contains(origin, "github"):
+		return "github"
+	case strings.Contains(origin, "gitlab"):
+		return "gitlab"
+	}
+	return "none"
+}
+
+// writeWorkflow writes the GitHub Actions workflow to path unless it exists
+func writeWorkflow(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		pterm.Info.Printfln("Keeping the existing %s", path)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(githubWorkflow), 0o644); err != nil {
+		return err
+	}
+	pterm.Success.Println("Wrote", path)
+	return nil
+}
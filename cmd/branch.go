@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var branchCmd = &cobra.Command{
+	Use:   "branch [<description>...]",
+	Short: "Create and check out a branch named after a commit type and ticket",
+	Long: `Prompt for the commit type, the ticket and a short description of the
+work and create a branch named by branch_template, e.g.
+feat/PROJ-123-add-login, then check it out.
+
+The placeholders {type}, {ticket} and {slug} of the template are replaced,
+separators left over by an empty ticket are dropped. The ticket is checked
+against ticket_pattern, so the commits on the branch get it as footer.`,
+	Example: `  git cc branch
+  git cc branch --type fix --ticket PROJ-123 handle expired sessions
+  git cc branch --no-checkout --base origin/main add login`,
+	Args: cobra.ArbitraryArgs,
+	Run:  branch,
+}
+
+func init() {
+	branchCmd.Flags().String("type", "", "Commit type of the work on the branch")
+	branchCmd.Flags().String("ticket", "", "Ticket of the work, empty for none")
+	branchCmd.Flags().String("base", "", "Start the branch at this ref instead of HEAD")
+	branchCmd.Flags().Bool("no-checkout", false, "Create the branch without checking it out")
+	registerTypeCompletion(branchCmd, "type", "")
+	rootCmd.AddCommand(branchCmd)
+}
+
+func branch(cmd *cobra.Command, args []string) {
+	flags := cmd.Flags()
+
+	commitType, _ := flags.GetString("type")
+	if commitType == "" {
+		commitType, _ = ui.Select("Commit Type", commitTypes, "feat")
+	} else if !slices.Contains(commitTypes, commitType) {
+		pterm.Error.Printfln("type %q is not one of %s", commitType, strings.Join(commitTypes, ", "))
+		exit(exitcode.Failure)
+	}
+
+	ticket, _ := flags.GetString("ticket")
+	if !flags.Changed("ticket") {
+		for {
+			ticket, _ = ui.Input("Ticket (optional)", "")
+			ticket = strings.TrimSpace(ticket)
+			if ticket == "" || matchesTicketPattern(ticket) {
+				break
+			}
+			pterm.Warning.Printfln("%q doesn't match ticket_pattern %s", ticket, viper.GetString("ticket_pattern"))
+		}
+	} else if ticket != "" && !matchesTicketPattern(ticket) {
+		pterm.Error.Printfln("%q doesn't match ticket_pattern %s", ticket, viper.GetString("ticket_pattern"))
+		exit(exitcode.Failure)
+	}
+
+	slug := slugify(strings.Join(args, " "))
+	for slug == "" {
+		description, _ := ui.Input("Short description of the work", "")
+		slug = slugify(description)
+	}
+
+	name := branchName(viper.GetString("branch_template"), commitType, ticket, slug)
+	if err := exec.Command("git", "check-ref-format", "--branch", name).Run(); err != nil {
+		pterm.Error.Printfln("%q is not a valid branch name, check branch_template", name)
+		exit(exitcode.Failure)
+	}
+
+	gitArgs := []string{"checkout", "-b", name}
+	if noCheckout, _ := flags.GetBool("no-checkout"); noCheckout {
+		gitArgs = []string{"branch", name}
+	}
+	if base, _ := flags.GetString("base"); base != "" {
+		gitArgs = append(gitArgs, base)
+	}
+	git := exec.Command("git", gitArgs...)
+	git.Dir = gitRoot
+	git.Stdout = os.Stdout
+	git.Stderr = os.Stderr
+	if err := git.Run(); err != nil {
+		pterm.Error.Println("Failed to create the branch:", err)
+		exit(exitcode.Failure)
+	}
+	if gitArgs[0] == "branch" {
+		pterm.Success.Println("Created branch", name)
+	}
+}
+
+// matchesTicketPattern reports whether ticket would be found in a branch
+// name by ticket_pattern, any ticket matches without a pattern
+func matchesTicketPattern(ticket string) bool {
+	pattern := viper.GetString("ticket_pattern")
+	if pattern == "" {
+		return true
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		pterm.Warning.Printfln("ignoring invalid ticket_pattern %q: %s", pattern, err)
+		return true
+	}
+	return re.FindString(ticket) == ticket
+}
+
+var (
+	slugSeparators     = regexp.MustCompile(`[^a-z0-9]+`)
+	repeatedSeparators = regexp.MustCompile(`([-_.])[-_.]+`)
+)
+
+// slugify turns a description into lower case words joined by dashes, at
+// most 50 characters long
+func slugify(description string) string {
+	slug := strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(description), "-"), "-")
+	if len(slug) > 50 {
+		// cut at a word boundary
+		slug = slug[:50]
+		if i := strings.LastIndex(slug, "-"); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// branchName fills the placeholders of template, dropping the separators
+// around empty values
+func branchName(template, commitType, ticket, slug string) string {
+	name := strings.NewReplacer("{type}", commitType, "{ticket}", ticket, "{slug}", slug).Replace(template)
+	name = repeatedSeparators.ReplaceAllString(name, "$1")
+	var parts []string
+	for _, part := range strings.Split(name, "/") {
+		if part = strings.Trim(part, "-_."); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+const (
+	protectedCreate = "Create a feature branch"
+	protectedCommit = "Commit anyway"
+	protectedAbort  = "Abort"
+)
+
+// guardProtectedBranch warns about commits to a branch of
+// protected_branches or, with protected_branch_action block, refuses them.
+// The prompts offer to create a feature branch the commit goes to instead.
+func guardProtectedBranch() {
+	head, err := repo.Head()
+	if err != nil || !head.Name().IsBranch() || !matchBranch(head.Name().Short(), viper.GetStringSlice("protected_branches")) {
+		return
+	}
+	name := head.Name().Short()
+	block := viper.GetString("protected_branch_action") == "block"
+	if block {
+		pterm.Error.Printfln("%s is a protected branch, commit to a feature branch instead, e.g. one created with git cc branch", name)
+	} else {
+		pterm.Warning.Printfln("%s is a protected branch, consider committing to a feature branch, e.g. one created with git cc branch", name)
+	}
+	if nonInteractive || answersFile != "" {
+		if block {
+			exit(exitcode.Failure)
+		}
+		return
+	}
+
+	options := []string{protectedCreate, protectedCommit, protectedAbort}
+	if block {
+		options = []string{protectedCreate, protectedAbort}
+	}
+	choice, _ := ui.Select("Commit to "+name, options, protectedCreate)
+	switch choice {
+	case protectedCreate:
+		// the staged changes are taken to the new branch
+		branch(branchCmd, nil)
+	case protectedAbort:
+		pterm.Info.Println("Commit aborted")
+		exit(exitcode.Aborted)
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// trailers naming people rather than references to tickets or docs
+var peopleTrailers = []string{"signed-off-by", "co-authored-by", "reviewed-by", "acked-by", "tested-by", "reported-by", "helped-by"}
+
+var breakingCmd = &cobra.Command{
+	Use:   "breaking",
+	Short: "List the breaking changes in a range of commits",
+	Long: `List all breaking changes since a ref with their notes and the references
+(tickets, docs, ...) given in their footers, to help assembling upgrade guides.`,
+	Example: `  git cc breaking --since v2.0.0
+  git cc breaking --since v2.0.0 --format markdown > UPGRADING.md`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         breaking,
+}
+
+func init() {
+	breakingCmd.Flags().String("since", "", "List breaking changes after this ref (default: whole history)")
+	breakingCmd.Flags().String("until", "HEAD", "List breaking changes up to this ref")
+	breakingCmd.Flags().String("format", "terminal", "Output format, terminal or markdown")
+	rootCmd.AddCommand(breakingCmd)
+}
+
+func breaking(cmd *cobra.Command, args []string) {
+	since, _ := cmd.Flags().GetString("since")
+	until, _ := cmd.Flags().GetString("until")
+	format, _ := cmd.Flags().GetString("format")
+
+	if format != "terminal" && format != "markdown" {
+		pterm.Error.Printfln("unknown format %q, expected terminal or markdown", format)
+		exit(exitcode.Failure)
+	}
+
+	commits, err := commitRange(since, until)
+	if err != nil {
+		pterm.Error.Println("Failed to read history:", err)
+		exit(exitcode.Failure)
+	}
+
+	var changes []conventionalCommit
+	for _, c := range commits {
+		if c.Valid && c.Data.BreakingChange {
+			changes = append(changes, c)
+		}
+	}
+
+	title := "Breaking changes"
+	if since != "" {
+		title += " since " + since
+	}
+
+	if format == "markdown" {
+		fmt.Print(breakingMarkdown(title, changes))
+		return
+	}
+
+	pterm.DefaultSection.Println(title)
+	if len(changes) == 0 {
+		pterm.Info.Println("No breaking changes found")
+		return
+	}
+	for _, c := range changes {
+		header, _, _ := strings.Cut(c.Commit.Message, "\n")
+		pterm.Println(pterm.Yellow(c.Commit.Hash.String()[:7]) + " " + pterm.Bold.Sprint(header))
+		for _, note := range breakingNotes(c) {
+			pterm.Println("  " + strings.ReplaceAll(note, "\n", "\n  "))
+		}
+		for _, ref := range references(c) {
+			pterm.Println(pterm.Gray("  " + ref.String()))
+		}
+		pterm.Println()
+	}
+}
+
+func breakingMarkdown(title string, changes []conventionalCommit) string {
+	var md strings.Builder
+	fmt.Fprintf(&md, "# %s\n\n", title)
+	if len(changes) == 0 {
+		md.WriteString("No breaking changes.\n")
+		return md.String()
+	}
+
+	for _, c := range changes {
+		header, _, _ := strings.Cut(c.Commit.Message, "\n")
+		fmt.Fprintf(&md, "## %s (%s)\n\n", header, c.Commit.Hash.String()[:7])
+		for _, note := range breakingNotes(c) {
+			fmt.Fprintf(&md, "%s\n\n", note)
+		}
+		if refs := references(c); len(refs) > 0 {
+			md.WriteString("References:\n\n")
+			for _, ref := range refs {
+				fmt.Fprintf(&md, "- %s\n", ref.String())
+			}
+			md.WriteString("\n")
+		}
+	}
+	return md.String()
+}
+
+// breakingNotes returns the BREAKING CHANGE notes of c, falling back to the
+// description for changes only marked with "!"
+func breakingNotes(c conventionalCommit) []string {
+	if notes := c.BreakingNotes(); len(notes) > 0 {
+		return notes
+	}
+	if c.Body != "" {
+		return []string{c.Body}
+	}
+	return nil
+}
+
+// references returns the trailers of c pointing to tickets, docs or other
+// commits, e.g. Refs or Closes
+func references(c conventionalCommit) []trailer {
+	var refs []trailer
+	for _, t := range c.Trailers {
+		if t.Token == "BREAKING CHANGE" || t.Token == "BREAKING-CHANGE" || slices.Contains(peopleTrailers, strings.ToLower(t.Token)) {
+			continue
+		}
+		refs = append(refs, t)
+	}
+	return refs
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// exportedGoDecl matches top level declarations of exported Go identifiers,
+// methods with their receiver type
+var exportedGoDecl = regexp.MustCompile(`^(?:func\s+(?:\(\s*(?:\w+\s+)?\*?(\w+)(?:\[[^\]]*\])?\s*\)\s*)?|type\s+|var\s+|const\s+)([A-Z]\w*)`)
+
+// packageVersionPatterns parse the version of the package itself from a line
+// of the manifests by file name
+var packageVersionPatterns = map[string]*regexp.Regexp{
+	// major versions from v2 on are part of the module path
+	"go.mod":       regexp.MustCompile(`^\s*module\s+(\S+)`),
+	"package.json": regexp.MustCompile(`^\s*"version"\s*:\s*"v?(\d+)[^"]*"`),
+	"Cargo.toml":   regexp.MustCompile(`^\s*version\s*=\s*"v?(\d+)[^"]*"`),
+}
+
+var goMajorSuffix = regexp.MustCompile(`/v(\d+)$`)
+
+var (
+	stagedBreakingHints     []string
+	stagedBreakingHintsOnce sync.Once
+)
+
+// breakingHints returns the signs of a breaking change in the staged
+// changes: removed exported Go identifiers, deleted public files and major
+// version bumps of the package, read once per run
+func breakingHints() []string {
+	stagedBreakingHintsOnce.Do(func() {
+		if !viper.GetBool("breaking_hints") {
+			return
+		}
+		exclude := excludeGeneratedStaged()
+		diff, err := gitOutput(append([]string{"diff", "--cached", "--unified=0", "--no-color", "--no-ext-diff", "--no-renames"}, exclude...)...)
+		if err != nil {
+			pterm.Debug.Println("Failed to diff staged files:", err)
+			return
+		}
+		deleted, err := gitOutput(append([]string{"diff", "--cached", "--name-only", "--diff-filter=D", "--no-renames"}, exclude...)...)
+		if err != nil {
+			pterm.Debug.Println("Failed to list deleted files:", err)
+			return
+		}
+		stagedBreakingHints = append(removedGoIdentifiers(diff), deletedPublicFiles(strings.Fields(deleted))...)
+		stagedBreakingHints = append(stagedBreakingHints, majorVersionBumps(diff)...)
+	})
+	return stagedBreakingHints
+}
+
+// removedGoIdentifiers lists the exported identifiers whose declaration was
+// removed from a package and not added back in another file of it
+func removedGoIdentifiers(diff string) []string {
+	var removed []string
+	removedIn := map[string]bool{}
+	added := map[string]bool{}
+	file := ""
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			_, file, _ = strings.Cut(line, " b/")
+			continue
+		}
+		if !isPublicGoFile(file) || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++") {
+			continue
+		}
+		if !strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "+") {
+			continue
+		}
+		match := exportedGoDecl.FindStringSubmatch(line[1:])
+		// methods of unexported types aren't reachable by name
+		if match == nil || (match[1] != "" && !isExported(match[1])) {
+			continue
+		}
+		name := match[2]
+		if match[1] != "" {
+			name = match[1] + "." + name
+		}
+		key := path.Dir(file) + "\x00" + name
+		if line[0] == '+' {
+			added[key] = true
+		} else if !removedIn[key] {
+			removedIn[key] = true
+			removed = append(removed, key)
+		}
+	}
+
+	var hints []string
+	for _, key := range removed {
+		if added[key] {
+			continue
+		}
+		dir, name, _ := strings.Cut(key, "\x00")
+		hints = append(hints, fmt.Sprintf("removes %s from package %s", name, dir))
+	}
+	return hints
+}
+
+// deletedPublicFiles lists the deleted files of public Go packages and those
+// matching public_paths
+func deletedPublicFiles(files []string) []string {
+	var hints []string
+	for _, file := range files {
+		if isPublicGoFile(file) || matchesPublicPaths(file) {
+			hints = append(hints, "deletes "+file)
+		}
+	}
+	return hints
+}
+
+// majorVersionBumps lists the manifests raising the major version of the
+// package itself
+func majorVersionBumps(diff string) []string {
+	var hints []string
+	var pattern *regexp.Regexp
+	file := ""
+	from, to := -1, -1
+	flush := func() {
+		if from >= 0 && to > from {
+			hints = append(hints, fmt.Sprintf("bumps the major version in %s from %d to %d", file, from, to))
+		}
+		from, to = -1, -1
+	}
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			_, file, _ = strings.Cut(line, " b/")
+			pattern = packageVersionPatterns[path.Base(file)]
+			continue
+		}
+		if pattern == nil || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++") {
+			continue
+		}
+		if !strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "+") {
+			continue
+		}
+		match := pattern.FindStringSubmatch(line[1:])
+		if match == nil {
+			continue
+		}
+		major := majorVersion(path.Base(file), match[1])
+		if line[0] == '-' {
+			from = major
+		} else {
+			to = major
+		}
+	}
+	flush()
+	return hints
+}
+
+// majorVersion returns the major version of a module path or version number
+func majorVersion(manifest, version string) int {
+	if manifest == "go.mod" {
+		match := goMajorSuffix.FindStringSubmatch(version)
+		if match == nil {
+			return 1
+		}
+		version = match[1]
+	}
+	major, err := strconv.Atoi(version)
+	if err != nil {
+		return -1
+	}
+	return major
+}
+
+// isPublicGoFile reports whether file is a non-test Go file importable by
+// other modules, i.e. outside internal and testdata directories
+func isPublicGoFile(file string) bool {
+	if !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+		return false
+	}
+	for _, dir := range strings.Split(path.Dir(file), "/") {
+		if dir == "internal" || dir == "testdata" || dir == "vendor" {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesPublicPaths matches file against the public_paths patterns
+func matchesPublicPaths(file string) bool {
+	return matchesPathPatterns(file, viper.GetStringSlice("public_paths"))
+}
+
+func isExported(name string) bool {
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
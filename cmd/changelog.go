@@ -0,0 +1,416 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/pterm/pterm"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// changelogTypes maps commit types to the keep-a-changelog section they are
+// listed in, commits of other types are left out unless they are breaking
+var changelogTypes = map[string]string{
+	"feat":     "Added",
+	"perf":     "Changed",
+	"refactor": "Changed",
+	"revert":   "Removed",
+	"fix":      "Fixed",
+	"security": "Security",
+}
+
+// changelogSectionOrder is the order of the sections in a release
+var changelogSectionOrder = []string{"Added", "Changed", "Deprecated", "Removed", "Fixed", "Security", "Dependencies"}
+
+const changelogPreamble = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/).
+`
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Generate a changelog from the conventional commit history",
+	Long: `Generate a Markdown changelog in the Keep a Changelog format from the
+conventional commits between two refs, grouped by type and scope.
+
+By default the commits since the latest tag are listed as unreleased changes.
+With --output the release is added to the given changelog file: a section
+for the same release is replaced where it is, a new release goes above the
+others and replaces Unreleased. --contributors adds a
+section listing the authors and co-authors of the release.
+
+Dependency updates, the commits of renovate and dependabot and build or
+chore commits scoped deps, are listed together under Dependencies, grouped
+by type and scope. --collapse-dependencies only counts them, e.g. as
+27 dependency updates.
+
+--check regenerates the release the same way but only compares the result
+with the changelog file, CHANGELOG.md in the root of the repository unless
+--output names another: the differences are printed and git cc exits with 1
+when it is out of date, so CI keeps generated changelogs honest.`,
+	Example: `  git cc changelog
+  git cc changelog --from v1.1.0 --to v1.2.0
+  git cc changelog --release 1.3.0 --output CHANGELOG.md
+  git cc changelog --check`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         changelog,
+}
+
+func init() {
+	changelogCmd.Flags().String("from", "", "List commits after this ref (default: latest tag)")
+	changelogCmd.Flags().String("to", "HEAD", "List commits up to this ref")
+	changelogCmd.Flags().String("release", "", "Name of the release (default: the tag on --to, otherwise Unreleased)")
+	changelogCmd.Flags().StringP("output", "o", "", "Add the release to this changelog file instead of printing it")
+	changelogCmd.Flags().Bool("check", false, "Fail if the changelog file doesn't contain the release as generated")
+	changelogCmd.Flags().Bool("contributors", false, "List the authors and co-authors of the release (default changelog_contributors)")
+	changelogCmd.Flags().Bool("collapse-dependencies", false, "Count the dependency updates instead of listing each (default collapse_dependencies)")
+	rootCmd.AddCommand(changelogCmd)
+}
+
+func changelog(cmd *cobra.Command, args []string) {
+	viper.BindPFlag("changelog_contributors", cmd.Flags().Lookup("contributors"))
+	viper.BindPFlag("collapse_dependencies", cmd.Flags().Lookup("collapse-dependencies"))
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+	release, _ := cmd.Flags().GetString("release")
+	output, _ := cmd.Flags().GetString("output")
+	check, _ := cmd.Flags().GetBool("check")
+
+	if !cmd.Flags().Changed("from") {
+		from = previousTag(to)
+	}
+
+	if check && output == "" {
+		output = filepath.Join(gitRoot, "CHANGELOG.md")
+	} else if output != "" && !check {
+		refuseWrite("--output")
+	}
+
+	section, release, err := changelogSection(from, to, release)
+	if err != nil {
+		pterm.Error.Println("Failed to read history:", err)
+		exit(exitcode.Failure)
+	}
+
+	if output == "" {
+		fmt.Print(section)
+		return
+	}
+
+	existing, err := os.ReadFile(output)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		pterm.Error.Println("Failed to read changelog:", err)
+		exit(exitcode.Failure)
+	}
+	merged := mergeChangelog(string(existing), section)
+	if check {
+		if strings.TrimSpace(merged) == strings.TrimSpace(string(existing)) {
+			pterm.Success.Printfln("%s is up to date", output)
+			return
+		}
+		pterm.Error.Printfln("%s is out of date for %s, update it with git cc changelog --output", output, release)
+		printLineDiff(string(existing), merged)
+		exit(exitcode.Failure)
+	}
+	if err := os.WriteFile(output, []byte(merged), 0o644); err != nil {
+		pterm.Error.Println("Failed to write changelog:", err)
+		exit(exitcode.Failure)
+	}
+	pterm.Success.Printfln("Added %s to %s", release, output)
+}
+
+// changelogSection renders the release of the commits between from and to
+// and returns it with the release name, which defaults to the tag on to or
+// Unreleased
+func changelogSection(from, to, release string) (string, string, error) {
+	commits, err := commitRange(from, to)
+	if err != nil {
+		return "", release, err
+	}
+	release = releaseName(to, release)
+	return releaseChangelog(release, commits), release, nil
+}
+
+// releaseChangelog renders the release of commits, dated by the latest one
+func releaseChangelog(release string, commits []conventionalCommit) string {
+	if viper.GetBool("changelog_skip_reverted") {
+		commits = withoutRevertPairs(commits)
+	}
+
+	date := ""
+	if release != "Unreleased" && len(commits) > 0 {
+		// Keep a Changelog asks for ISO 8601 dates, whatever the locale
+		date = commits[0].Commit.Committer.When.Format(isoDate)
+		if viper.GetString("date_format") != "" {
+			date = formatDate(commits[0].Commit.Committer.When)
+		}
+	}
+
+	return renderChangelogRelease(release, date, commits)
+}
+
+// revertedPattern matches the line git revert writes into the body
+var revertedPattern = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-f]{7,40})`)
+
+// revertedCommit returns the hash of the commit c reverts, referenced by the
+// Refs footer of git cc revert or the body git revert writes, empty when c
+// isn't a revert
+func revertedCommit(c conventionalCommit) string {
+	if c.Valid && isRevert(c.Data.Type, c.Trailers) {
+		for _, t := range c.Trailers {
+			if strings.EqualFold(t.Token, "Refs") && revertRefPattern.MatchString(strings.TrimSpace(t.Value)) {
+				return strings.TrimSpace(t.Value)
+			}
+		}
+	}
+	if match := revertedPattern.FindStringSubmatch(c.Commit.Message); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// withoutRevertPairs leaves out the commits reverted in commits together
+// with their reverts, as neither changed anything in the release. The
+// history is newest first, so a reverted revert keeps the original commit.
+func withoutRevertPairs(commits []conventionalCommit) []conventionalCommit {
+	dropped := map[plumbing.Hash]bool{}
+	for _, c := range commits {
+		reverted := revertedCommit(c)
+		if reverted == "" || dropped[c.Commit.Hash] {
+			continue
+		}
+		i := slices.IndexFunc(commits, func(o conventionalCommit) bool {
+			return strings.HasPrefix(o.Commit.Hash.String(), reverted)
+		})
+		if i >= 0 && !dropped[commits[i].Commit.Hash] {
+			dropped[c.Commit.Hash] = true
+			dropped[commits[i].Commit.Hash] = true
+		}
+	}
+	return slices.DeleteFunc(slices.Clone(commits), func(c conventionalCommit) bool {
+		return dropped[c.Commit.Hash]
+	})
+}
+
+// renderChangelogRelease renders the section of one release, entries are
+// grouped into the keep-a-changelog sections and sorted by scope
+func renderChangelogRelease(release, date string, commits []conventionalCommit) string {
+	sections := map[string][]conventionalCommit{}
+	for _, c := range commits {
+		// dependency updates are listed together whatever type they were
+		// given
+		if c.DependencyUpdate() {
+			sections["Dependencies"] = append(sections["Dependencies"], c)
+			continue
+		}
+		if !c.Valid {
+			continue
+		}
+		section, ok := changelogTypes[c.Data.Type]
+		if !ok && c.Data.BreakingChange {
+			section, ok = "Changed", true
+		}
+		if ok {
+			sections[section] = append(sections[section], c)
+		}
+	}
+
+	prs := pullRequestsOf(commits)
+	repoURL := repositoryURL()
+
+	var md strings.Builder
+	if release == "Unreleased" {
+		md.WriteString("## [Unreleased]\n")
+	} else if date == "" {
+		fmt.Fprintf(&md, "## [%s]\n", release)
+	} else {
+		fmt.Fprintf(&md, "## [%s] - %s\n", release, date)
+	}
+
+	for _, name := range changelogSectionOrder {
+		entries := sections[name]
+		if len(entries) == 0 {
+			continue
+		}
+		// history is newest first, list unscoped entries first and group the
+		// rest by scope, dependency updates by type first
+		slices.Reverse(entries)
+		slices.SortStableFunc(entries, func(a, b conventionalCommit) int {
+			if name == "Dependencies" && a.Data.Type != b.Data.Type {
+				return strings.Compare(a.Data.Type, b.Data.Type)
+			}
+			return strings.Compare(a.Data.Scope, b.Data.Scope)
+		})
+
+		fmt.Fprintf(&md, "\n### %s\n\n", name)
+		collapsed := 0
+		for _, c := range entries {
+			// breaking updates stay listed when the others are only counted
+			if name == "Dependencies" && viper.GetBool("collapse_dependencies") && !c.Data.BreakingChange {
+				collapsed++
+				continue
+			}
+			md.WriteString("- " + changelogEntry(c, prs, repoURL) + "\n")
+		}
+		if collapsed > 0 {
+			md.WriteString("- " + dependencyUpdates(collapsed) + "\n")
+		}
+	}
+
+	if viper.GetBool("changelog_contributors") {
+		if contributors := releaseContributors(commits); len(contributors) > 0 {
+			md.WriteString("\n### Contributors\n\n")
+			for _, c := range contributors {
+				md.WriteString("- " + c.String() + "\n")
+			}
+		}
+	}
+	return md.String()
+}
+
+// changelogEntry renders c, linking the pull request it was merged with
+// instead of the commit when there is one
+func changelogEntry(c conventionalCommit, prs pullRequests, repoURL string) string {
+	entry, pr := prs.of(c)
+	if c.Data.Scope != "" {
+		entry = "**" + c.Data.Scope + ":** " + entry
+	}
+	switch url := pullRequestURL(repoURL, pr); {
+	case url != "":
+		entry += " ([#" + pr + "](" + url + "))"
+	case pr != "":
+		entry += " (#" + pr + ")"
+	default:
+		entry += " (" + c.Commit.Hash.String()[:7] + ")"
+	}
+	if c.Data.BreakingChange {
+		entry = "**BREAKING** " + entry
+		for _, note := range c.BreakingNotes() {
+			entry += "\n  " + strings.ReplaceAll(note, "\n", "\n  ")
+		}
+	}
+	return entry
+}
+
+// printLineDiff prints the lines changed from old to updated with up to
+// three lines of context
+func printLineDiff(old, updated string) {
+	const context = 3
+	diffs := diff.Do(old, updated)
+	for i, d := range diffs {
+		lines := strings.Split(strings.TrimSuffix(d.Text, "\n"), "\n")
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			for _, line := range lines {
+				fmt.Println(pterm.Red("-" + line))
+			}
+		case diffmatchpatch.DiffInsert:
+			for _, line := range lines {
+				fmt.Println(pterm.Green("+" + line))
+			}
+		default:
+			// context after the previous change and before the next one
+			head, tail := 0, 0
+			if i > 0 {
+				head = min(context, len(lines))
+			}
+			if i < len(diffs)-1 {
+				tail = min(context, len(lines)-head)
+			}
+			for _, line := range lines[:head] {
+				fmt.Println(" " + line)
+			}
+			if head+tail < len(lines) && head > 0 && tail > 0 {
+				fmt.Println(pterm.Cyan("@@"))
+			}
+			for _, line := range lines[len(lines)-tail:] {
+				fmt.Println(" " + line)
+			}
+		}
+	}
+}
+
+// linkDefinition matches the link reference definitions Keep a Changelog
+// files end with, such as [1.2.0]: https://...
+var linkDefinition = regexp.MustCompile(`^\[[^\]]+\]:\s*\S`)
+
+// mergeChangelog adds a release section to an existing changelog. A release
+// with the same name is replaced where it is. A new release goes above the
+// others, replacing Unreleased, whose changes it lists now.
+func mergeChangelog(existing, section string) string {
+	if strings.TrimSpace(existing) == "" {
+		return changelogPreamble + "\n" + section
+	}
+
+	heading, _, _ := strings.Cut(section, "\n")
+	name := changelogHeadingName(heading)
+
+	lines := strings.Split(strings.TrimRight(existing, "\n"), "\n")
+	// the link definitions at the end belong to no release
+	end := len(lines)
+	for end > 0 && (linkDefinition.MatchString(lines[end-1]) || strings.TrimSpace(lines[end-1]) == "") {
+		end--
+	}
+	links := strings.TrimSpace(strings.Join(lines[end:], "\n"))
+
+	// the text before the first release, then one block per release
+	blocks := [][]string{nil}
+	for _, line := range lines[:end] {
+		if strings.HasPrefix(line, "## ") {
+			blocks = append(blocks, nil)
+		}
+		blocks[len(blocks)-1] = append(blocks[len(blocks)-1], line)
+	}
+
+	release := strings.Split(strings.TrimRight(section, "\n"), "\n")
+	releaseNamed := func(name string) int {
+		return slices.IndexFunc(blocks[1:], func(block []string) bool {
+			return strings.EqualFold(changelogHeadingName(block[0]), name)
+		}) + 1
+	}
+	if i := releaseNamed(name); i > 0 {
+		blocks[i] = release
+	} else if i := releaseNamed("Unreleased"); i > 0 {
+		blocks[i] = release
+	} else {
+		blocks = slices.Insert(blocks, 1, release)
+	}
+
+	var parts []string
+	for _, block := range blocks {
+		if text := strings.TrimRight(strings.Join(block, "\n"), "\n"); strings.TrimSpace(text) != "" {
+			parts = append(parts, text)
+		}
+	}
+	if links != "" {
+		parts = append(parts, links)
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// changelogHeadingName returns the release of a ## heading without its date
+// and brackets, such as 1.2.0 for ## [1.2.0] - 2024-05-01
+func changelogHeadingName(heading string) string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(heading, "## "), " - ")
+	name = strings.TrimSpace(name)
+	if rest, ok := strings.CutPrefix(name, "["); ok {
+		if inner, _, found := strings.Cut(rest, "]"); found {
+			return inner
+		}
+	}
+	return name
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import "testing"
+
+func TestMergeChangelog(t *testing.T) {
+	const release = "## [1.2.0] - 2024-05-01\n\n### Added\n\n- search\n"
+	tests := []struct {
+		name     string
+		existing string
+		section  string
+		want     string
+	}{
+		{
+			name:    "fresh file",
+			section: release,
+			want:    changelogPreamble + "\n" + release,
+		},
+		{
+			name:     "release replaces Unreleased",
+			existing: "# Changelog\n\n## [Unreleased]\n\n### Added\n\n- search\n\n## [1.1.0] - 2024-04-01\n\n### Fixed\n\n- login\n",
+			section:  release,
+			want:     "# Changelog\n\n" + release + "\n## [1.1.0] - 2024-04-01\n\n### Fixed\n\n- login\n",
+		},
+		{
+			name:     "Unreleased is replaced",
+			existing: "# Changelog\n\n## [Unreleased]\n\n### Added\n\n- old\n\n## [1.1.0] - 2024-04-01\n\n### Fixed\n\n- login\n",
+			section:  "## [Unreleased]\n\n### Added\n\n- new\n",
+			want:     "# Changelog\n\n## [Unreleased]\n\n### Added\n\n- new\n\n## [1.1.0] - 2024-04-01\n\n### Fixed\n\n- login\n",
+		},
+		{
+			name:     "new release above the others",
+			existing: "# Changelog\n\n## [1.1.0] - 2024-04-01\n\n### Fixed\n\n- login\n",
+			section:  release,
+			want:     "# Changelog\n\n" + release + "\n## [1.1.0] - 2024-04-01\n\n### Fixed\n\n- login\n",
+		},
+		{
+			name:     "existing release below the top one is replaced in place",
+			existing: "# Changelog\n\n## [Unreleased]\n\n- next\n\n## [1.3.0] - 2024-06-01\n\n- three\n\n## [1.2.0] - 2024-05-01\n\n- old\n\n## [1.1.0] - 2024-04-01\n\n- one\n",
+			section:  release,
+			want:     "# Changelog\n\n## [Unreleased]\n\n- next\n\n## [1.3.0] - 2024-06-01\n\n- three\n\n" + release + "\n## [1.1.0] - 2024-04-01\n\n- one\n",
+		},
+		{
+			name:     "unchanged release",
+			existing: "# Changelog\n\n" + release + "\n## [1.1.0] - 2024-04-01\n\n- one\n",
+			section:  release,
+			want:     "# Changelog\n\n" + release + "\n## [1.1.0] - 2024-04-01\n\n- one\n",
+		},
+		{
+			name:     "link definitions stay at the end",
+			existing: "# Changelog\n\n## [1.2.0] - 2024-05-01\n\n- old\n\n[1.2.0]: https://example.com/compare/v1.1.0...v1.2.0\n",
+			section:  release,
+			want:     "# Changelog\n\n" + release + "\n[1.2.0]: https://example.com/compare/v1.1.0...v1.2.0\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeChangelog(tt.existing, tt.section); got != tt.want {
+				t.Errorf("mergeChangelog() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangelogHeadingName(t *testing.T) {
+	for heading, want := range map[string]string{
+		"## [Unreleased]":                 "Unreleased",
+		"## [1.2.0] - 2024-05-01":         "1.2.0",
+		"## 1.2.0":                        "1.2.0",
+		"## [1.2.0](https://example.com)": "1.2.0",
+	} {
+		if got := changelogHeadingName(heading); got != want {
+			t.Errorf("changelogHeadingName(%q) = %q, want %q", heading, got, want)
+		}
+	}
+}
+
+func TestDependencyUpdate(t *testing.T) {
+	commit := func(commitType, scope string) conventionalCommit {
+		return conventionalCommit{Valid: true, Data: CommitPromptData{Type: commitType, Scope: scope}}
+	}
+	tests := []struct {
+		name string
+		c    conventionalCommit
+		want bool
+	}{
+		{name: "build(deps)", c: commit("build", "deps"), want: true},
+		{name: "chore(deps)", c: commit("chore", "deps"), want: true},
+		{name: "chore(deps-dev)", c: commit("chore", "deps-dev"), want: true},
+		{name: "fix(deps)", c: commit("fix", "deps")},
+		{name: "chore(ci)", c: commit("chore", "ci")},
+		{name: "bot", c: conventionalCommit{DependencyBot: "renovate", Data: CommitPromptData{Type: "fix"}}, want: true},
+		{name: "not conventional", c: conventionalCommit{Data: CommitPromptData{Type: "chore", Scope: "deps"}}},
+	}
+	for _, tt := range tests {
+		if got := tt.c.DependencyUpdate(); got != tt.want {
+			t.Errorf("%s: DependencyUpdate() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
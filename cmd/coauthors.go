@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// coAuthorOptions returns the co-authors offered by the prompt as
+// "Name <email>": the configured co_authors followed by the authors of the
+// recent history, without the committing user
+func coAuthorOptions() []string {
+	self := gitConfigOption("user", "email")
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(self)): true}
+
+	var options []string
+	for _, author := range append(viper.GetStringSlice("co_authors"), recentAuthors()...) {
+		_, email, _ := strings.Cut(strings.TrimSuffix(author, ">"), "<")
+		if email = strings.ToLower(strings.TrimSpace(email)); email == "" || seen[email] {
+			continue
+		}
+		seen[email] = true
+		options = append(options, author)
+	}
+	return options
+}
+
+// recentAuthors returns the authors of the last co_author_history commits,
+// most recent first
+func recentAuthors() []string {
+	limit := viper.GetInt("co_author_history")
+	if limit <= 0 {
+		return nil
+	}
+	head, err := repo.Head()
+	if err != nil {
+		// no commits yet
+		return nil
+	}
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		pterm.Debug.Println("Failed to read history:", err)
+		return nil
+	}
+
+	var authors []string
+	scanned := 0
+	err = commits.ForEach(func(c *object.Commit) error {
+		if scanned++; scanned > limit {
+			return storer.ErrStop
+		}
+		authors = append(authors, c.Author.Name+" <"+c.Author.Email+">")
+		return nil
+	})
+	if err != nil {
+		pterm.Debug.Println("Failed to read history:", err)
+	}
+	return removeDuplicateStr(authors)
+}
+
+// askCoAuthors offers the known co-authors unless a mob session credits them
+// already, and returns a Co-authored-by trailer for each one picked
+func askCoAuthors() []trailer {
+	if session, _ := loadMobSession(); session != nil {
+		return nil
+	}
+	options := coAuthorOptions()
+	if len(options) == 0 {
+		return nil
+	}
+
+	picked, _ := ui.MultiSelect(tr("Co-authors"), options)
+	var trailers []trailer
+	for _, author := range picked {
+		trailers = append(trailers, trailer{Token: "Co-authored-by", Separator: ": ", Value: author})
+	}
+	return trailers
+}
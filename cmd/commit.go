@@ -0,0 +1,1075 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/45413/git-cc/pkg/conventional"
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	answersFile  string
+	writeMessage string
+	// print the message instead of committing, set by --dry-run or --print
+	dryRun bool
+	// text, or json to print the answers, message and commit for scripts
+	outputFormat string
+	printJSON    bool
+	// answers given by flags, set when any prompt flag is used
+	flagAnswers    CommitPromptData
+	flagFooters    []string
+	nonInteractive bool
+	stageAll       bool
+	amend          bool
+	// answers of the amended commit the prompts start with
+	promptDefaults CommitPromptData
+	// arguments given after -- for git commit
+	gitCommitArgs []string
+	// prompts skipped for this run by --no-scope, --no-body, ...
+	skippedPrompts = map[promptField]*bool{
+		scopeField:           new(bool),
+		longDescriptionField: new(bool),
+		breakingChangeField:  new(bool),
+		footersField:         new(bool),
+	}
+	// signing key given by --gpg-sign, defaultSigningKey without one
+	gpgSign   string
+	noGPGSign bool
+)
+
+// defaultSigningKey is the value of --gpg-sign without a key id, signing with
+// the key git is configured to use
+const defaultSigningKey = "default"
+
+var commitCmd = &cobra.Command{
+	Use:   "commit",
+	Short: "Prompt for a conventional commit message and commit the staged changes",
+	Long: `Prompt for a conventional commit message and commit the staged changes.
+
+Running git cc without a command is the same as git cc commit. Arguments
+after -- are passed on to git commit.`,
+	Example: `  git cc commit -- --no-verify --author="Jane Doe <jane@example.com>"`,
+	Args:    gitCommitArgsOnly,
+	PreRunE: commitFlagsPreRun,
+	Run:     commit,
+}
+
+func init() {
+	addCommitFlags(commitCmd)
+	addAmendFlag(commitCmd)
+	rootCmd.AddCommand(commitCmd)
+}
+
+// addCommitFlags defines the flags of the commit flow, shared by the root
+// command and the commit command
+func addCommitFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&answersFile, "answers", "", "Read prompt answers as JSON from `file` (- for stdin) instead of prompting")
+	cmd.Flags().BoolVar(&stdinAnswers, "stdin", false, "Pre-answer prompts with JSON answers read from stdin and ask the others")
+	cmd.Flags().BoolVarP(&stageAll, "all", "a", false, "Stage all changes to tracked files before committing")
+	cmd.Flags().StringVar(&writeMessage, "write-message", "", "Write the message to the start of `file` instead of committing, as used by the prepare-commit-msg hook")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the message to stdout instead of committing")
+	cmd.Flags().BoolVar(&dryRun, "print", false, "Same as --dry-run")
+	cmd.Flags().StringVar(&outputFormat, "output", "text", "Output `format`, json prints the answers, message and commit hash once committed")
+	cmd.Flags().BoolVar(&printJSON, "json", false, "Same as --output json")
+
+	// Define flags for the prompt fields, setting any of them skips the prompts
+	cmd.Flags().StringVar(&flagAnswers.Type, "type", "", "Commit type")
+	cmd.Flags().StringVar(&flagAnswers.Scope, "scope", "", "Commit scope")
+	cmd.Flags().StringVar(&flagAnswers.ShortDescription, "message", "", "Short description")
+	cmd.Flags().StringVar(&flagAnswers.LongDescription, "body", "", "Long description")
+	cmd.Flags().BoolVar(&flagAnswers.BreakingChange, "breaking", false, "Mark the commit as a breaking change")
+	cmd.Flags().StringArrayVar(&flagAnswers.BreakingChangeNotes, "breaking-note", nil, "Breaking change note, implies --breaking, may be repeated")
+	cmd.Flags().StringArrayVar(&flagFooters, "footer", nil, "Footer to add as \"Token: value\", may be repeated")
+	registerTypeCompletion(cmd, "type", "scope")
+	cmd.RegisterFlagCompletionFunc("footer", completeFooters)
+	cmd.Flags().BoolVarP(&quickCommit, "quick", "q", false, "Pick the type with a single key and ask the subject only, committing without the review")
+	cmd.Flags().BoolVar(&noDraft, "no-draft", false, "Neither resume nor save a draft of the answers")
+	cmd.Flags().BoolVar(&showDiff, "diff", false, "Show the staged diff before the prompts (default diff_preview or false)")
+	cmd.Flags().BoolVar(&suggest, "suggest", false, "Pre-fill the prompts with a message suggested for the staged diff by suggest_endpoint")
+
+	// skip single prompts for a quick commit
+	cmd.Flags().BoolVar(skippedPrompts[scopeField], "no-scope", false, "Skip the scope prompt")
+	cmd.Flags().BoolVar(skippedPrompts[longDescriptionField], "no-body", false, "Skip the long description prompt")
+	cmd.Flags().BoolVar(skippedPrompts[breakingChangeField], "no-breaking", false, "Skip the breaking change prompts")
+	cmd.Flags().BoolVar(skippedPrompts[footersField], "no-footers", false, "Skip the co-author and footer prompts")
+
+	// passed on to git commit
+	cmd.Flags().BoolP("signoff", "s", false, "Add a Signed-off-by trailer (default signoff or false)")
+	cmd.Flags().StringVarP(&gpgSign, "gpg-sign", "S", "", "Sign the commit, with `keyid` if given as -S=keyid (default sign or false)")
+	cmd.Flags().Lookup("gpg-sign").NoOptDefVal = defaultSigningKey
+	cmd.Flags().BoolVar(&noGPGSign, "no-gpg-sign", false, "Don't sign the commit, overriding sign and commit.gpgSign")
+
+	cmd.Flags().Bool("push", false, "Push the branch to its upstream after committing (default push or false)")
+	cmd.Flags().Bool("no-exec", false, "Commit through go-git instead of running git commit (default no_exec or false)")
+	cmd.Flags().BoolVar(&keepMessageFile, "keep-message-file", false, "Keep the temporary commit message file and print its path, for debugging")
+}
+
+// addAmendFlag defines --amend for the commands creating the commit directly
+func addAmendFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&amend, "amend", false, "Amend the last commit, prompting with its message pre-filled")
+}
+
+// gitCommitArgsOnly accepts arguments only after --, for git commit
+func gitCommitArgsOnly(cmd *cobra.Command, args []string) error {
+	// git running git-cc as its editor, core.editor or GIT_EDITOR
+	if cmd.ArgsLenAtDash() < 0 && len(args) == 1 && isGitEditorFile(args[0]) {
+		editorFile = args[0]
+		return nil
+	}
+	if dash := cmd.ArgsLenAtDash(); dash > 0 || (dash < 0 && len(args) > 0) {
+		if cmd.HasSubCommands() {
+			return fmt.Errorf("unknown command %q for %q", args[0], cmd.CommandPath())
+		}
+		return fmt.Errorf("unexpected argument %q, arguments for git commit go after --", args[0])
+	}
+	return nil
+}
+
+func commitFlagsPreRun(cmd *cobra.Command, args []string) error {
+	viper.BindPFlag("signoff", cmd.Flags().Lookup("signoff"))
+	viper.BindPFlag("push", cmd.Flags().Lookup("push"))
+	viper.BindPFlag("no_exec", cmd.Flags().Lookup("no-exec"))
+	if editorFile == "" {
+		gitCommitArgs = args
+	}
+	for _, name := range []string{"type", "scope", "message", "body", "breaking", "breaking-note", "footer"} {
+		if cmd.Flags().Changed(name) {
+			nonInteractive = true
+		}
+	}
+	for _, value := range flagFooters {
+		t, err := parseTrailerArg(value)
+		if err != nil {
+			return err
+		}
+		flagAnswers.Footers = append(flagAnswers.Footers, t)
+	}
+	if len(flagAnswers.BreakingChangeNotes) > 0 {
+		flagAnswers.BreakingChange = true
+	}
+	if nonInteractive && answersFile != "" {
+		return fmt.Errorf("--answers can't be combined with the prompt flags")
+	}
+	if suggest && (nonInteractive || answersFile != "") {
+		return fmt.Errorf("--suggest can't be combined with --answers or the prompt flags")
+	}
+	if stdinAnswers && (nonInteractive || answersFile != "") {
+		return fmt.Errorf("--stdin can't be combined with --answers or the prompt flags")
+	}
+	// answers of wrappers only replace prompts, flags and --answers replace
+	// all of them
+	if !nonInteractive && answersFile == "" {
+		if err := loadPreAnswers(); err != nil {
+			return err
+		}
+	}
+	if editorFile != "" && (writeMessage != "" || dryRun || amend || outputFormat == "json") {
+		return fmt.Errorf("--write-message, --dry-run, --amend and --output json can't be used when git runs git-cc as its editor")
+	}
+	if push, _ := cmd.Flags().GetBool("push"); push && (writeMessage != "" || dryRun || editorFile != "") {
+		return fmt.Errorf("--push can't be combined with --write-message or --dry-run, or used when git runs git-cc as its editor")
+	}
+	if amend && writeMessage != "" {
+		return fmt.Errorf("--amend can't be combined with --write-message")
+	}
+	if len(gitCommitArgs) > 0 && writeMessage != "" {
+		return fmt.Errorf("arguments for git commit can't be combined with --write-message")
+	}
+	if gpgSign != "" && noGPGSign {
+		return fmt.Errorf("--gpg-sign can't be combined with --no-gpg-sign")
+	}
+	if printJSON {
+		outputFormat = "json"
+	}
+	if outputFormat != "text" && outputFormat != "json" {
+		return fmt.Errorf("unknown output format %q, use text or json", outputFormat)
+	}
+	if dryRun && (writeMessage != "" || len(gitCommitArgs) > 0) {
+		return fmt.Errorf("--dry-run can't be combined with --write-message or arguments for git commit")
+	}
+	if outputFormat == "json" && writeMessage != "" {
+		return fmt.Errorf("--output json can't be combined with --write-message")
+	}
+	if dryRun || outputFormat == "json" {
+		// stdout is reserved for the message or JSON, so it can be piped
+		pterm.SetDefaultOutput(os.Stderr)
+		if plain, ok := ui.(*plainUI); ok {
+			plain.out = os.Stderr
+		}
+	}
+	return nil
+}
+
+func commit(cmd *cobra.Command, args []string) {
+	// git is already committing, only provide the message
+	if editorFile != "" {
+		runAsEditor(editorFile)
+		return
+	}
+	if writeMessage != "" {
+		if err := writeCommitMessage(writeMessage); err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Code(err))
+		}
+		return
+	}
+
+	// only the message is wanted, whatever is staged
+	if dryRun {
+		if amend {
+			if err := loadAmendDefaults(); err != nil {
+				pterm.Error.Println(err)
+				exit(exitcode.Failure)
+			}
+		}
+		if err := printCommitMessage(); err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Code(err))
+		}
+		return
+	}
+
+	// only nudge people who are about to answer the prompts
+	if !nonInteractive && answersFile == "" && !amend {
+		offerSetup()
+		if remindUncommittedWork() {
+			return
+		}
+	}
+
+	if amend {
+		if err := loadAmendDefaults(); err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Failure)
+		}
+	} else if !slices.Contains(gitCommitArgs, "--allow-empty") {
+		// Error out if nothing is staged
+		checkStagedChanges()
+		checkCommitSize()
+	}
+	guardProtectedBranch()
+
+	createCommit()
+}
+
+// createCommit prompts for the message and commits, exiting if that fails
+func createCommit() {
+	if err := runCommit(); errors.Is(err, errCommitAborted) {
+		pterm.Info.Println(tr("Commit aborted"))
+		exit(exitcode.Aborted)
+	} else if err != nil {
+		commitFailed(err)
+	}
+	if viper.GetBool("push") {
+		pushBranch()
+	}
+}
+
+// writeCommitMessage prompts for the commit message and writes it in front
+// of the existing content of file, keeping git's comments below it
+func writeCommitMessage(file string) error {
+	commitMsg, err := promptForCommit(commitTypes)
+	if err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.WriteFile(file, append([]byte(commitMsg+"\n"), existing...), 0o644); err != nil {
+		return err
+	}
+	removeDraft()
+	return nil
+}
+
+// printCommitMessage prompts for the commit message and prints it to
+// stdout, with --output json together with the answers it was built from
+func printCommitMessage() error {
+	commitMsg, err := promptForCommit(commitTypes)
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == "json" {
+		if err := printCommitJSON(commitMsg, ""); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println(commitMsg)
+	}
+	removeDraft()
+	return nil
+}
+
+// commitOutput is printed by --output json
+type commitOutput struct {
+	// Answers are left out for messages edited into another format
+	Answers *CommitPromptData `json:"answers,omitempty"`
+	Message string            `json:"message"`
+	// Commit is the hash of the created commit, empty for --dry-run
+	Commit string `json:"commit,omitempty"`
+}
+
+// printCommitJSON prints message, the answers parsed back from it and the
+// hash of its commit as JSON to stdout
+func printCommitJSON(message, hash string) error {
+	output := commitOutput{Message: message, Commit: hash}
+	if answers, err := parseCommitMessage(message); err == nil {
+		output.Answers = &answers
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// loadAmendDefaults parses the message of HEAD into the prompt defaults
+func loadAmendDefaults() error {
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("nothing to amend: %w", err)
+	}
+	last, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+
+	promptDefaults, err = parseCommitMessage(last.Message)
+	if err != nil {
+		// keep the message of a non conventional commit as description
+		pterm.Warning.Println(err)
+		header, rest, _ := strings.Cut(strings.TrimSpace(last.Message), "\n")
+		promptDefaults = CommitPromptData{ShortDescription: header, LongDescription: strings.TrimSpace(rest)}
+	}
+	return nil
+}
+
+func checkStagedChanges() {
+	if stageAll {
+		stage := func() error { return stageFiles("add", "--update") }
+		if commitWithoutGit() {
+			stage = stageTrackedChanges
+		}
+		if err := stage(); err != nil {
+			pterm.Error.Println(tr("Failed to stage changes:"), err)
+			exit(exitcode.Failure)
+		}
+	}
+
+	hasStagedChanges, hasUntracked, err := stagedChanges()
+	if err != nil {
+		pterm.Error.Println(tr("Failed to get status:"), err)
+		exit(exitcode.Failure)
+	}
+
+	// offer to pick the files instead of sending people back to git add
+	if !hasStagedChanges && !nonInteractive && answersFile == "" && promptForStaging() {
+		return
+	}
+
+	// Error out if nothing is staged
+	if !hasStagedChanges && gitExitCodes() {
+		nothingToCommit()
+	} else if !hasStagedChanges && hasUntracked {
+		pterm.Error.Println(tr("nothing added to commit but untracked files present (use \"git add\" to track)"))
+		exit(exitcode.NothingStaged)
+	} else if !hasStagedChanges {
+		pterm.Error.Println(tr("nothing added to commit"))
+		exit(exitcode.NothingStaged)
+	}
+}
+
+// CommitPromptData holds the answers given to the commit prompts
+type CommitPromptData struct {
+	Type               string `json:"type"`
+	Scope              string `json:"scope,omitempty"`
+	ShortDescription   string `json:"short_description"`
+	LongDescription    string `json:"long_description,omitempty"`
+	BreakingChange     bool   `json:"breaking_change,omitempty"`
+	BreakingChangeNote string `json:"breaking_change_note,omitempty"`
+	// BreakingChangeNotes are the notes of a commit breaking several
+	// things, BreakingChangeNote is added in front unless it is one of them
+	BreakingChangeNotes []string `json:"breaking_change_notes,omitempty"`
+	// Footers are trailers such as Refs or Reviewed-by, breaking changes
+	// have their own fields
+	Footers []trailer `json:"footers,omitempty"`
+}
+
+// BreakingNotes returns the notes of all BREAKING CHANGE footers
+func (d CommitPromptData) BreakingNotes() []string {
+	if d.BreakingChangeNote != "" && !slices.Contains(d.BreakingChangeNotes, d.BreakingChangeNote) {
+		return append([]string{d.BreakingChangeNote}, d.BreakingChangeNotes...)
+	}
+	return d.BreakingChangeNotes
+}
+
+func promptForCommit(commitTypes []string) (string, error) {
+	// answers given by flags replace the prompts
+	if nonInteractive {
+		data, err := withJiraTicket(withBranchTicket(flagAnswers), false)
+		if err != nil {
+			return "", err
+		}
+		if err := validateCommitData(data); err != nil {
+			return "", exitcode.New(exitcode.Invalid, err)
+		}
+		message, err := renderCommitMessage(data)
+		if err != nil {
+			return "", err
+		}
+		return addMobTrailers(message), nil
+	}
+
+	// answers passed in by an external frontend replace the prompts
+	if answersFile != "" {
+		data, err := loadAnswers(answersFile)
+		if err != nil {
+			return "", err
+		}
+		data, err = withJiraTicket(withBranchTicket(data), false)
+		if err != nil {
+			return "", err
+		}
+		message, err := renderCommitMessage(data)
+		if err != nil {
+			return "", err
+		}
+		return addMobTrailers(message), nil
+	}
+
+	// show where the commit goes before anything is typed, frontends
+	// redrawing the screen show it themselves
+	if _, redraws := ui.(*tuiUI); !redraws {
+		pterm.Println(promptBanner())
+	}
+
+	// answers of an aborted prompt or failed commit are offered again, an
+	// amended message or resumed named draft is started from instead
+	if !amend && namedDraft == "" {
+		if draft, ok := resumeDraft(); ok {
+			promptDefaults = draft
+		} else if suggest {
+			// only sent when asked for, the diff leaves the machine
+			if suggestion, err := suggestMessage(); err != nil {
+				pterm.Warning.Println(tr("No suggestion:"), err)
+			} else {
+				promptDefaults = suggestion
+			}
+		}
+		// the body of commit.template is where descriptions start
+		if promptDefaults.LongDescription == "" {
+			promptDefaults.LongDescription = commitTemplate()
+		}
+	}
+
+	// the ticket of the branch is pre-filled and can still be changed
+	promptDefaults = withBranchTicket(promptDefaults)
+	defaults, err := withJiraTicket(promptDefaults, true)
+	if err != nil {
+		return "", err
+	}
+	promptDefaults = defaults
+
+	// the diff is at hand while describing it
+	if diffPreviewEnabled() {
+		showStagedDiff()
+	}
+
+	// validate the answers with the same rules lint applies, so a message
+	// accepted here can't be rejected by a commit-msg hook or CI
+	data := askCommitPrompts(commitTypes)
+	for {
+		message := buildCommitMessage(data)
+
+		problems := validateCommitMessage(message)
+		if len(problems) == 0 {
+			// house styles are rendered from the validated answers
+			rendered, err := renderCommitMessage(data)
+			if err != nil {
+				return "", err
+			}
+			return addMobTrailers(rendered), nil
+		}
+		for _, problem := range problems {
+			pterm.Error.Println(strings.Join(append([]string{problemTitle(problem)}, problemHelp(problem)...), "\n"))
+		}
+
+		retry, err := ui.Confirm(tr("Edit the answers"), true)
+		if err != nil || !retry {
+			return "", exitcode.New(exitcode.Invalid, errors.New(tr("commit message does not follow the Conventional Commits spec")))
+		}
+
+		// only the prompts of the offending answers are asked again
+		promptDefaults = data
+		for _, field := range problemFields(message, problems) {
+			data = askField(field, data, commitTypes)
+		}
+	}
+}
+
+// promptField is one of the commit prompts
+type promptField int
+
+const (
+	typeField promptField = iota
+	scopeField
+	shortDescriptionField
+	longDescriptionField
+	breakingChangeField
+	footersField
+	// customField asks the custom_prompts of the repository
+	customField
+)
+
+// promptNames are the names of the prompts in the prompts config property
+var promptNames = map[string]promptField{
+	"type":     typeField,
+	"scope":    scopeField,
+	"subject":  shortDescriptionField,
+	"body":     longDescriptionField,
+	"breaking": breakingChangeField,
+	"footers":  footersField,
+	"custom":   customField,
+}
+
+// promptOrder returns the prompts to ask in the order of the prompts config
+// property. The type and subject can't be left out, missing ones are asked
+// first.
+func promptOrder() []promptField {
+	if quickCommit {
+		return []promptField{typeField, shortDescriptionField}
+	}
+	names := viper.GetStringSlice("prompts")
+	if len(names) == 0 {
+		return []promptField{typeField, scopeField, shortDescriptionField, longDescriptionField, breakingChangeField, footersField, customField}
+	}
+	var order []promptField
+	for _, name := range names {
+		field, ok := promptNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			pterm.Warning.Printfln("Ignoring the unknown prompt %q in prompts, use type, scope, subject, body, breaking, footers or custom", name)
+			continue
+		}
+		if !slices.Contains(order, field) {
+			order = append(order, field)
+		}
+	}
+	for _, field := range []promptField{shortDescriptionField, typeField} {
+		if !slices.Contains(order, field) {
+			order = append([]promptField{field}, order...)
+		}
+	}
+	return order
+}
+
+// askCommitPrompts asks for the commit message, starting with promptDefaults.
+// Prompts left out of the prompts config property keep their default.
+func askCommitPrompts(commitTypes []string) CommitPromptData {
+	var data CommitPromptData
+	order := promptOrder()
+	fields := slices.Clone(order)
+	for field := typeField; field <= customField; field++ {
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	defer func() { pendingFields = nil }()
+	for i, field := range fields {
+		pendingFields = slices.DeleteFunc(slices.Clone(fields[i+1:]), func(f promptField) bool { return preAnswered[f] })
+		if preAnswered[field] {
+			data = preAnswer(field, data)
+			continue
+		}
+		if skip := skippedPrompts[field]; skip != nil && *skip || !slices.Contains(order, field) {
+			data = keepDefault(field, data)
+			continue
+		}
+		data = askField(field, data, commitTypes)
+	}
+	return data
+}
+
+// pendingFields are the fields askCommitPrompts hasn't reached yet
+var pendingFields []promptField
+
+// withPendingDefaults fills the fields not reached yet with promptDefaults,
+// so a draft saved halfway keeps e.g. the resumed body
+func withPendingDefaults(data CommitPromptData) CommitPromptData {
+	for _, field := range pendingFields {
+		switch field {
+		case typeField:
+			data.Type = promptDefaults.Type
+		case shortDescriptionField:
+			data.ShortDescription = promptDefaults.ShortDescription
+		default:
+			data = keepDefault(field, data)
+		}
+	}
+	return data
+}
+
+// keepDefault answers the prompts of a skipped field with promptDefaults,
+// so an amended commit keeps its answers
+func keepDefault(field promptField, data CommitPromptData) CommitPromptData {
+	switch field {
+	case scopeField:
+		data.Scope = promptDefaults.Scope
+	case longDescriptionField:
+		data.LongDescription = promptDefaults.LongDescription
+	case breakingChangeField:
+		data.BreakingChange = promptDefaults.BreakingChange
+		data.BreakingChangeNote = promptDefaults.BreakingChangeNote
+		data.BreakingChangeNotes = promptDefaults.BreakingChangeNotes
+	case footersField:
+		data.Footers = keepFooters(promptDefaults.Footers, data.Footers)
+	}
+	return data
+}
+
+// keepFooters returns footers with the answered ones, such as those of the
+// custom prompts when they are asked first, replacing theirs
+func keepFooters(footers, answered []trailer) []trailer {
+	kept := slices.DeleteFunc(slices.Clone(footers), func(f trailer) bool {
+		return slices.ContainsFunc(answered, func(t trailer) bool { return strings.EqualFold(t.Token, f.Token) })
+	})
+	return append(kept, answered...)
+}
+
+// askField asks the prompts of field, starting with promptDefaults, and
+// returns data with the answers
+func askField(field promptField, data CommitPromptData, commitTypes []string) CommitPromptData {
+	guideField(field, commitTypes)
+	switch field {
+	case typeField:
+		if quickCommit {
+			data.Type = askQuickType(commitTypes)
+			break
+		}
+		// Use PTerm's interactive select feature to present the options to the user and capture their selection
+		options, optionTypes := typeOptions(commitTypes)
+		defaultType := promptDefaults.Type
+		if defaultType == "" {
+			defaultType = suggestedType(commitTypes)
+		}
+		if i := slices.Index(commitTypes, defaultType); i >= 0 {
+			defaultType = options[i]
+		} else {
+			defaultType = ""
+		}
+		selected, _ := ui.Select(promptLabel("Commit Type"), options, defaultType)
+		data.Type = optionTypes[selected]
+	case scopeField:
+		data.Scope = askScope()
+		if owners := scopeOwners(data.Scope); len(owners) > 0 {
+			pterm.Info.Println(tr("%s is owned by %s", data.Scope, strings.Join(owners, ", ")))
+		}
+	case shortDescriptionField:
+		// Prompt for single line short description
+		data.ShortDescription = askShortDescription(data)
+	case longDescriptionField:
+		// staged dependency bumps can be listed instead of typed
+		body := promptDefaults.LongDescription
+		if changes := dependencyChanges(); body == "" && len(changes) > 0 {
+			if list, _ := ui.Confirm(tr("List the %d dependency changes in the body", len(changes)), true); list {
+				body = dependencyBody(changes)
+			}
+		}
+		// Pompt for optional multiline long description, wrapped to the line
+		// limit, compact mode asks a single line unless one is kept
+		label := "Long Description (optional)"
+		if requiresBody(data) {
+			label = "Long Description"
+		}
+		if viper.GetBool("body_editor") {
+			data.LongDescription = editBody(data, body)
+		} else if isUIMode(compactMode) && !strings.Contains(body, "\n") {
+			data.LongDescription, _ = ui.Input(promptLabel(label), body)
+		} else {
+			data.LongDescription, _ = ui.MultilineInput(promptLabel(label), body)
+		}
+		data.LongDescription = checkSpelling("long description", data.LongDescription)
+		data.LongDescription = wrapText(data.LongDescription, viper.GetInt("max_body_line_length"))
+	case breakingChangeField:
+		// confirm is this commit includes a breaking change, staged signs of
+		// one make it the default
+		breaking := promptDefaults.BreakingChange
+		if hints := breakingHints(); !breaking && len(hints) > 0 {
+			pterm.Warning.Println(tr("The staged changes look like a breaking change:") + "\n  " + strings.Join(hints, "\n  "))
+			breaking = true
+		}
+		data.BreakingChange, _ = ui.Confirm(promptLabel("Breaking Change"), breaking)
+
+		data.BreakingChangeNote, data.BreakingChangeNotes = "", nil
+		if data.BreakingChange {
+			// Prompt for breaking change notes, one per thing that breaks
+			previous := promptDefaults.BreakingNotes()
+			label := "Breaking Change Note"
+			for i := 0; ; i++ {
+				note := ""
+				if i < len(previous) {
+					note = previous[i]
+				}
+				note, _ = ui.Input(promptLabel(label), note)
+				if note = strings.TrimSpace(note); note == "" {
+					break
+				}
+				data.BreakingChangeNotes = append(data.BreakingChangeNotes, note)
+				label = "Another Breaking Change Note (optional)"
+			}
+			if len(data.BreakingChangeNotes) > 0 {
+				data.BreakingChangeNote = data.BreakingChangeNotes[0]
+			}
+		}
+	case footersField:
+		// footers of an amended commit are kept, more can be added
+		data.Footers = keepFooters(promptDefaults.Footers, data.Footers)
+		for _, t := range askCoAuthors() {
+			data.Footers = setTrailer(data.Footers, t, false)
+		}
+		if t, ok := askIssue(); ok {
+			data.Footers = setTrailer(data.Footers, t, false)
+		}
+		if addFooters, _ := ui.Confirm(promptLabel("Add Footers (Refs, Reviewed-by, ...)"), false); addFooters {
+			for _, t := range promptForTrailers() {
+				data.Footers = setTrailer(data.Footers, t, false)
+			}
+		}
+	case customField:
+		data = askCustomPrompts(data)
+	}
+	answersChanged(data)
+	return data
+}
+
+// askScope prompts for the scope, offering the configured scopes or those
+// suggested by the staged files and used in the history
+func askScope() string {
+	suggested := suggestedScopes()
+	if len(scopes) > 0 {
+		options, suggestion := orderScopes(groupScopes(scopes), suggested)
+		defaultScope := "none"
+		if slices.Contains(scopes, promptDefaults.Scope) {
+			defaultScope = promptDefaults.Scope
+		} else if suggestion != "" {
+			defaultScope = suggestion
+		}
+		return selectScope(options, defaultScope)
+	}
+
+	known := removeDuplicateStr(slices.Concat(suggested, historyScopes()))
+	if len(known) == 0 {
+		scope, _ := ui.Input(promptLabel("Scope (optional)"), promptDefaults.Scope)
+		return scope
+	}
+
+	defaultScope := "none"
+	switch {
+	case promptDefaults.Scope != "":
+		defaultScope = promptDefaults.Scope
+		if !slices.Contains(known, defaultScope) {
+			known = append([]string{defaultScope}, known...)
+		}
+	case len(suggested) > 0:
+		defaultScope = suggested[0]
+	}
+	scope := selectScope(append(known, "none", otherScope), defaultScope)
+	if scope == otherScope {
+		scope, _ = ui.Input(promptLabel("Scope (optional)"), "")
+	}
+	return scope
+}
+
+// problemFields returns the prompts whose answers caused the problems found
+// in message, or all of them when a problem can't be attributed
+func problemFields(message string, problems []conventional.Problem) []promptField {
+	lines := strings.Split(message, "\n")
+	match := headerPattern.FindStringSubmatchIndex(lines[0])
+	bodyEnd := len(lines)
+	if _, footers := splitTrailers(strings.Join(lines[1:], "\n")); len(footers) > 0 {
+		bodyEnd -= strings.Count(joinTrailers("", footers), "\n") + 1
+	}
+
+	found := map[promptField]bool{}
+	for _, problem := range problems {
+		switch {
+		case problem.Rule == "body-empty":
+			found[longDescriptionField] = true
+		case problem.Rule == "trailer-exists":
+			found[footersField] = true
+		case match == nil || problem.Line == 1:
+			return []promptField{typeField, scopeField, shortDescriptionField, longDescriptionField, breakingChangeField, footersField, customField}
+		case problem.Line >= bodyEnd:
+			found[footersField] = true
+		case problem.Line > 1:
+			found[longDescriptionField] = true
+		case problem.Column < match[3]:
+			found[typeField] = true
+		case match[4] >= 0 && problem.Column < match[5], problem.Message == "scope is required":
+			found[scopeField] = true
+		default:
+			// the description is what gets shortened to fit the header
+			found[shortDescriptionField] = true
+		}
+	}
+
+	var fields []promptField
+	for field := typeField; field <= customField; field++ {
+		if found[field] {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// askShortDescription prompts for the short description until the header
+// fits into max_subject_length
+func askShortDescription(data CommitPromptData) string {
+	limit := viper.GetInt("max_subject_length")
+	description := promptDefaults.ShortDescription
+	for {
+		label := tr("Short Description")
+		data.ShortDescription = ""
+		// only the header counts, a body may be answered already
+		header, _, _ := strings.Cut(buildCommitMessage(data), "\n")
+		available := limit - utf8.RuneCountInString(header)
+		if limit > 0 {
+			label = tr("Short Description (max %d characters)", available)
+		}
+		if diffPreviewEnabled() {
+			label += " " + tr("(? shows the diff)")
+		}
+		if isUIMode(compactMode) {
+			label = tr("description")
+			if limit > 0 {
+				label = tr("description (%d)", available)
+			}
+		}
+
+		previous := description
+		var err error
+		description, err = ui.Input(label, description)
+		if err != nil {
+			return description
+		}
+		// the diff can be looked at again while describing it
+		if description == "?" {
+			showStagedDiff()
+			description = previous
+			continue
+		}
+		// fixed typos may not fit anymore
+		description = checkSpelling("short description", description)
+		if limit > 0 && utf8.RuneCountInString(description) > available {
+			pterm.Warning.Println(tr("The description is %d characters too long, the header may have at most %d", utf8.RuneCountInString(description)-available, limit))
+			continue
+		}
+
+		// offer to fix the case, a trailing period and the mood
+		if fixed := conventional.FixDescription(description, commitRules()); fixed != description {
+			pterm.Warning.Println(tr("The description doesn't follow the subject style of this repository"))
+			if useFixed, _ := ui.Confirm(tr("Use %q instead", fixed), true); useFixed {
+				description = fixed
+			}
+		}
+		return description
+	}
+}
+
+func buildCommitMessage(data CommitPromptData) string {
+	c := conventional.Commit{
+		Type:        data.Type,
+		Scope:       data.Scope,
+		Breaking:    data.BreakingChange,
+		Description: data.ShortDescription,
+		Body:        data.LongDescription,
+		Footers:     data.Footers,
+	}
+	if c.Scope == "none" {
+		c.Scope = ""
+	}
+	if data.BreakingChange {
+		c.BreakingNotes = data.BreakingNotes()
+	}
+
+	message := c.String()
+	header, _, _ := strings.Cut(message, "\n")
+	return withEmoji(data.Type, header) + message[len(header):]
+}
+
+// signingArgs returns the git commit flags for the sign-off and signing
+// requested by flags or the config
+func signingArgs() []string {
+	var args []string
+	if viper.GetBool("signoff") {
+		args = append(args, "--signoff")
+	}
+	switch {
+	case noGPGSign:
+		args = append(args, "--no-gpg-sign")
+	case gpgSign != "" && gpgSign != defaultSigningKey:
+		args = append(args, "--gpg-sign="+gpgSign)
+	case gpgSign != "" || viper.GetBool("sign"):
+		args = append(args, "--gpg-sign")
+	}
+	return args
+}
+
+// errCommitAborted is returned when the commit is aborted in the preview
+var errCommitAborted = exitcode.New(exitcode.Aborted, errors.New("commit aborted"))
+
+// reviewCommitMessage previews the message in file and lets the user
+// confirm it, edit it in their editor or abort the commit
+func reviewCommitMessage(file string) error {
+	// the answers were validated before the message_template rendered them
+	edited := false
+	for {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		message := strings.TrimSpace(string(content))
+
+		pterm.DefaultBox.WithTitle(tr("Commit Message")).WithLeftPadding(1).WithRightPadding(1).Println(message)
+		if edited {
+			for _, problem := range validateCommitMessage(stripComments(message)) {
+				pterm.Warning.Println(strings.Join(append([]string{problemTitle(problem)}, problemHelp(problem)...), "\n"))
+			}
+		}
+
+		choice, _ := ui.Select(tr("Commit"), []string{tr("Confirm"), tr("Edit"), tr("Abort")}, "")
+		switch choice {
+		case tr("Confirm"):
+			return nil
+		case tr("Abort"):
+			return errCommitAborted
+		}
+
+		if err := editFile(file); err != nil {
+			return err
+		}
+		edited = true
+	}
+}
+
+// editFile opens file in the editor git is configured to use, honoring
+// GIT_EDITOR, core.editor, VISUAL and EDITOR, skipping git-cc itself
+func editFile(file string) error {
+	if err := runEditor(messageEditor(), file); err != nil {
+		return err
+	}
+	// editors on Windows may save the file with CRLF line endings
+	return normalizeLineEndings(file)
+}
+
+func runCommit() error {
+	started := time.Now()
+	// Prompt and build commit message
+	commitMsg, err := promptForCommit(commitTypes)
+	if err != nil {
+		return err
+	}
+
+	// Create a temporary file
+	file, err := writeMessageFile(commitMsg)
+	if err != nil {
+		return err
+	}
+	defer atExit(func() { removeMessageFile(file) })() // clean up
+	pterm.Debug.Println(commitMsg)
+	pterm.Debug.Println("temp file: " + file)
+
+	// answers given up front have been reviewed already, quick commits
+	// skip the review
+	if !nonInteractive && answersFile == "" && !quickCommit {
+		if err := reviewCommitMessage(file); err != nil {
+			return err
+		}
+	}
+
+	// the time spent on hooks doesn't count for the usage stats
+	took := time.Since(started)
+
+	// run git commit passing commit message, this ensures pre-commit hooks are run
+	args := append([]string{"commit", "-F", file}, signingArgs()...)
+	if amend {
+		args = append(args, "--amend")
+	}
+	args = append(args, gitCommitArgs...)
+
+	// Run the command, the draft is kept for another try if it fails
+	commit := runGitCommit
+	if commitWithoutGit() {
+		commit = nativeCommit
+	}
+	for {
+		var stdout io.Writer = os.Stdout
+		if outputFormat == "json" {
+			// git's summary would break the JSON
+			stdout = os.Stderr
+		}
+		hooksStarted := time.Now()
+		err := commit(args, stdout)
+		notifyCommitDone(err, time.Since(hooksStarted))
+		if err == nil {
+			break
+		}
+
+		// a failing hook is fixed and retried without answering again
+		if nonInteractive || answersFile != "" {
+			return err
+		}
+		pterm.Error.Println(tr("git commit failed:"), err)
+		choice, promptErr := ui.Select(tr("Commit failed"), []string{tr("Retry"), tr("Edit message"), tr("Retry with --no-verify"), tr("Abort")}, tr("Retry"))
+		if promptErr != nil {
+			return err
+		}
+		switch choice {
+		case tr("Edit message"):
+			if err := editFile(file); err != nil {
+				return err
+			}
+		case tr("Retry with --no-verify"):
+			if !slices.Contains(args, "--no-verify") {
+				args = append(args, "--no-verify")
+			}
+		case tr("Abort"):
+			return err
+		}
+	}
+	removeDraft()
+	if !nonInteractive && answersFile == "" {
+		if message, err := os.ReadFile(file); err == nil {
+			recordUsage(string(message), took)
+		}
+	}
+
+	if outputFormat == "json" {
+		// the message may have been edited in review or after a failure
+		out, err := gitOutput("log", "-1", "--format=%H%n%B")
+		if commitWithoutGit() {
+			out, err = headCommit()
+		}
+		if err == nil {
+			hash, message, _ := strings.Cut(out, "\n")
+			err = printCommitJSON(strings.TrimSpace(message), hash)
+		}
+		if err != nil {
+			// committed anyway, so not a failed commit
+			pterm.Error.Println(tr("Failed to print the commit:"), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// commitizen config files in the order commitizen looks them up, the TOML
+// files are only used if they have a [tool.commitizen] section
+var commitizenFiles = []string{
+	"pyproject.toml",
+	".cz.toml",
+	".cz.json",
+	"cz.json",
+	".cz.yaml",
+	"cz.yaml",
+	"cz.toml",
+}
+
+// types of commitizen's cz_conventional_commits rules
+var commitizenTypes = []string{"fix", "feat", "docs", "style", "refactor", "perf", "test", "build", "ci"}
+
+// templateField matches the answers inserted by a message_template, the
+// scope in parentheses
+var templateField = regexp.MustCompile(`(\()?\{\{\s*(\w+)\s*\}\}`)
+
+// loadCommitizen reads the commitizen config of the repository, it returns
+// nil if there is none or it uses rules git-cc doesn't understand
+func loadCommitizen() *sharedRules {
+	for _, name := range commitizenFiles {
+		path := filepath.Join(gitRoot, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		config, err := readCommitizenFile(path)
+		if err != nil {
+			pterm.Warning.Printfln("Ignoring %s: %s", name, err)
+			return nil
+		}
+		if config == nil {
+			continue
+		}
+		rules := parseCommitizenRules(config)
+		if rules == nil {
+			pterm.Debug.Printfln("Ignoring %s: git-cc doesn't understand the commitizen rules %s", name, config["name"])
+			return nil
+		}
+		rules.File = name
+		return rules
+	}
+	return nil
+}
+
+// readCommitizenFile returns the commitizen section of the config in path
+// or nil if it has none
+func readCommitizenFile(path string) (map[string]any, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	key := "commitizen"
+	if filepath.Ext(path) == ".toml" {
+		key = "tool.commitizen"
+	}
+	if !v.IsSet(key) {
+		return nil, nil
+	}
+	return v.GetStringMap(key), nil
+}
+
+// parseCommitizenRules maps the commitizen config to git-cc settings: the
+// types of cz_conventional_commits or the type and scope questions of
+// cz_customize and the message_length_limit. Other rules return nil.
+func parseCommitizenRules(config map[string]any) *sharedRules {
+	result := &sharedRules{Settings: map[string]any{}}
+	if limit := cast.ToInt(config["message_length_limit"]); limit > 0 {
+		result.Settings["max_subject_length"] = limit
+	}
+
+	switch name := cast.ToString(config["name"]); name {
+	case "", "cz_conventional_commits":
+		result.Types = commitizenTypes
+	case "cz_customize":
+		customize := cast.ToStringMap(config["customize"])
+		typeName, scopeName := "change_type", "scope"
+		for i, match := range templateField.FindAllStringSubmatch(cast.ToString(customize["message_template"]), -1) {
+			switch {
+			case i == 0:
+				typeName = match[2]
+			case match[1] != "":
+				scopeName = match[2]
+			}
+		}
+		for _, q := range cast.ToSlice(customize["questions"]) {
+			question := cast.ToStringMap(q)
+			if cast.ToString(question["type"]) != "list" {
+				continue
+			}
+			switch cast.ToString(question["name"]) {
+			case typeName:
+				result.Types, result.Explanations = questionChoices(question)
+				// "bug fix" can't be parsed from a header
+				result.Types = slices.DeleteFunc(result.Types, func(t string) bool {
+					if !validName.MatchString(t) {
+						pterm.Warning.Printfln("Ignoring the commitizen type %q, use letters, digits, _ and -", t)
+						return true
+					}
+					return false
+				})
+			case scopeName:
+				result.Scopes, _ = questionChoices(question)
+				// an empty choice makes the scope optional
+				result.ScopeRequired = !slices.Contains(result.Scopes, "")
+				result.Scopes = slices.DeleteFunc(result.Scopes, func(s string) bool { return s == "" })
+			}
+		}
+	default:
+		return nil
+	}
+	return result
+}
+
+// questionChoices returns the values of the choices of a commitizen list
+// question and their names as explanations, without the repeated value
+func questionChoices(question map[string]any) ([]string, map[string]string) {
+	var values []string
+	explanations := map[string]string{}
+	for _, c := range cast.ToSlice(question["choices"]) {
+		choice, ok := c.(map[string]any)
+		if !ok {
+			values = append(values, cast.ToString(c))
+			continue
+		}
+		value := cast.ToString(choice["value"])
+		values = append(values, value)
+		// "feature: A new feature."
+		explanation := strings.TrimSpace(strings.TrimPrefix(cast.ToString(choice["name"]), value+":"))
+		if explanation != "" && explanation != value {
+			explanations[value] = strings.TrimSuffix(explanation, ".")
+		}
+	}
+	return values, explanations
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// commitlint config files in the order commitlint looks them up, package.json
+// is only used if it has a commitlint key
+var commitlintFiles = []string{
+	".commitlintrc",
+	".commitlintrc.json",
+	".commitlintrc.yaml",
+	".commitlintrc.yml",
+	".commitlintrc.js",
+	".commitlintrc.cjs",
+	"commitlint.config.js",
+	"commitlint.config.cjs",
+	"package.json",
+}
+
+// rules of @commitlint/config-conventional, applied when a config extends it
+var configConventional = map[string]any{
+	"type-enum":            []any{2, "always", []any{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}},
+	"header-max-length":    []any{2, "always", 100},
+	"body-max-line-length": []any{2, "always", 100},
+	"subject-case":         []any{2, "never", []any{"sentence-case", "start-case", "pascal-case", "upper-case"}},
+	"subject-full-stop":    []any{2, "never", "."},
+}
+
+// sharedRules are the rules of a commitlint or commitizen config git-cc
+// understands
+type sharedRules struct {
+	File          string
+	Types         []string
+	Scopes        []string
+	ScopeRequired bool
+	// descriptions of the types
+	Explanations map[string]string
+	// settings mapped to git-cc config keys
+	Settings map[string]any
+}
+
+// loadCommitlint reads the commitlint config of the repository, it returns
+// nil if there is none
+func loadCommitlint() *sharedRules {
+	for _, name := range commitlintFiles {
+		path := filepath.Join(gitRoot, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		config, err := readCommitlintFile(path)
+		if err != nil {
+			pterm.Warning.Printfln("Ignoring %s: %s", name, err)
+			return nil
+		}
+		if config == nil {
+			continue
+		}
+		rules := parseCommitlintRules(config)
+		rules.File = name
+		return rules
+	}
+	return nil
+}
+
+// readCommitlintFile returns the commitlint config in path, JavaScript
+// configs are evaluated with node unless in read-only mode
+func readCommitlintFile(path string) (map[string]any, error) {
+	var content []byte
+	var err error
+	switch filepath.Ext(path) {
+	case ".js", ".cjs":
+		// evaluating runs code from the repository
+		if readOnly() {
+			return nil, fmt.Errorf("JavaScript configs aren't evaluated in read-only mode")
+		}
+		node := exec.Command("node", "-e", "console.log(JSON.stringify(require(process.argv[1])))", path)
+		node.Dir = gitRoot
+		if content, err = node.Output(); err != nil {
+			return nil, fmt.Errorf("failed to evaluate it with node: %w", err)
+		}
+	default:
+		if content, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if filepath.Ext(path) != "" && filepath.Ext(path) != ".yaml" && filepath.Ext(path) != ".yml" {
+		v.SetConfigType("json")
+	}
+	if err := v.ReadConfig(bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+	if filepath.Base(path) == "package.json" {
+		if !v.IsSet("commitlint") {
+			return nil, nil
+		}
+		return v.GetStringMap("commitlint"), nil
+	}
+	return v.AllSettings(), nil
+}
+
+// parseCommitlintRules maps the rules of config to git-cc settings, rules
+// which are disabled or not understood are skipped
+func parseCommitlintRules(config map[string]any) *sharedRules {
+	rules := map[string]any{}
+	if slices.ContainsFunc(cast.ToStringSlice(config["extends"]), func(e string) bool {
+		return e == "@commitlint/config-conventional" || e == "config-conventional"
+	}) {
+		for name, rule := range configConventional {
+			rules[name] = rule
+		}
+	}
+	for name, rule := range cast.ToStringMap(config["rules"]) {
+		rules[name] = rule
+	}
+
+	result := &sharedRules{Settings: map[string]any{}}
+	for name, rule := range rules {
+		args := cast.ToSlice(rule)
+		if len(args) < 2 || cast.ToInt(args[0]) == 0 {
+			continue
+		}
+		always := cast.ToString(args[1]) == "always"
+		var value any
+		if len(args) > 2 {
+			value = args[2]
+		}
+
+		switch name {
+		case "type-enum":
+			if always {
+				result.Types = cast.ToStringSlice(value)
+			}
+		case "scope-enum":
+			if always {
+				result.Scopes = cast.ToStringSlice(value)
+			}
+		case "scope-empty":
+			result.ScopeRequired = !always
+		case "header-max-length":
+			result.Settings["max_subject_length"] = cast.ToInt(value)
+		case "body-max-line-length":
+			result.Settings["max_body_line_length"] = cast.ToInt(value)
+		case "subject-full-stop":
+			result.Settings["forbid_trailing_period"] = !always
+		case "subject-case":
+			cases := cast.ToStringSlice(value)
+			if len(cases) == 0 {
+				cases = []string{cast.ToString(value)}
+			}
+			switch {
+			case always && slices.Contains(cases, "lower-case"):
+				result.Settings["subject_case"] = "lower"
+			case always && slices.Contains(cases, "sentence-case"),
+				!always && slices.Contains(cases, "lower-case"):
+				result.Settings["subject_case"] = "sentence"
+			case !always && slices.Contains(cases, "sentence-case"):
+				result.Settings["subject_case"] = "lower"
+			}
+		}
+	}
+	return result
+}
+
+// applySharedRules merges the commitlint or commitizen rules into the
+// configuration. Unless preferRules, as commitlint: commitlint asks for,
+// the settings of .git-cc.yaml win.
+func applySharedRules(rules *sharedRules, preferRules bool) {
+	pterm.Debug.Printfln("Using the rules of %s", rules.File)
+
+	for key, value := range rules.Settings {
+		if preferRules {
+			viper.Set(key, value)
+		} else {
+			viper.SetDefault(key, value)
+		}
+	}
+
+	if len(rules.Types) > 0 && (preferRules || (!viper.InConfig("custom_commit_types") && !viper.InConfig("use_defaults"))) {
+		commitTypes = rules.Types
+		for t, explanation := range rules.Explanations {
+			typeExplanations[t] = explanation
+		}
+	}
+	if len(rules.Scopes) > 0 && (preferRules || !viper.InConfig("scopes")) {
+		scopes = rules.Scopes
+		if !rules.ScopeRequired {
+			scopes = append([]string{"none"}, scopes...)
+		}
+	}
+}
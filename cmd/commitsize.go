@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// stagedSize returns the number of staged files and the lines they add and
+// delete, generated files left out
+func stagedSize() (files, lines int, err error) {
+	out, err := gitOutput(append([]string{"diff", "--cached", "--numstat"}, excludeGeneratedStaged()...)...)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		files++
+		// binary files have - instead of line counts
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		lines += added + deleted
+	}
+	return files, lines, nil
+}
+
+// checkCommitSize warns when the staged changes exceed max_commit_files or
+// max_commit_lines and asks whether to commit them anyway
+func checkCommitSize() {
+	maxFiles, maxLines := viper.GetInt("max_commit_files"), viper.GetInt("max_commit_lines")
+	if maxFiles <= 0 && maxLines <= 0 {
+		return
+	}
+	files, lines, err := stagedSize()
+	if err != nil {
+		pterm.Debug.Println("Failed to measure the staged changes:", err)
+		return
+	}
+
+	var exceeded []string
+	if maxFiles > 0 && files > maxFiles {
+		exceeded = append(exceeded, fmt.Sprintf("%d files (limit %d)", files, maxFiles))
+	}
+	if maxLines > 0 && lines > maxLines {
+		exceeded = append(exceeded, fmt.Sprintf("%d changed lines (limit %d)", lines, maxLines))
+	}
+	if len(exceeded) == 0 {
+		return
+	}
+	pterm.Warning.Printfln("This commit is large, %s. Smaller commits are easier to review and describe, stage part of the changes with git add -p.", strings.Join(exceeded, " and "))
+
+	// answers given up front can't be asked for a confirmation
+	if nonInteractive || answersFile != "" {
+		return
+	}
+	if commitAnyway, _ := ui.Confirm("Commit all staged changes anyway", false); !commitAnyway {
+		pterm.Info.Println("Commit aborted")
+		exit(exitcode.Aborted)
+	}
+}
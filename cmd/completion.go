@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion bash|zsh|fish|powershell",
+	Short: "Generate a shell completion script",
+	Long: `Generate the completion script of git-cc for a shell. Besides commands and
+flags, it completes the commit types and scopes configured in the repository
+of the working directory for --type and --scope, and the footer keys for
+--footer, so the flags of the non-interactive mode don't need to be looked up.`,
+	Example: `  # bash, in ~/.bashrc
+  source <(git-cc completion bash)
+
+  # zsh, in a directory of $fpath
+  git-cc completion zsh > "${fpath[1]}/_git-cc"
+
+  # fish
+  git-cc completion fish > ~/.config/fish/completions/git-cc.fish
+
+  # PowerShell, in $PROFILE
+  git-cc completion powershell | Out-String | Invoke-Expression`,
+	Annotations: readOnlyCommand,
+	ValidArgs:   []string{"bash", "zsh", "fish", "powershell"},
+	Args:        cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE:        completion,
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+func completion(cmd *cobra.Command, args []string) error {
+	switch args[0] {
+	case "bash":
+		return cmd.Root().GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		return cmd.Root().GenZshCompletion(os.Stdout)
+	case "fish":
+		return cmd.Root().GenFishCompletion(os.Stdout, true)
+	default:
+		return cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
+	}
+}
+
+// registerTypeCompletion completes the flags given by name with the commit
+// types and scopes
+func registerTypeCompletion(cmd *cobra.Command, typeFlag, scopeFlag string) {
+	if typeFlag != "" {
+		cmd.RegisterFlagCompletionFunc(typeFlag, completeTypes)
+	}
+	if scopeFlag != "" {
+		cmd.RegisterFlagCompletionFunc(scopeFlag, completeScopes)
+	}
+}
+
+// loadCompletionConfig loads the config of the repository in the working
+// directory, completions run without startup. Nothing may be printed as
+// the shell reads the completions from stdout.
+func loadCompletionConfig() bool {
+	pterm.DisableOutput()
+	if repo == nil {
+		if err := openRepository(); err != nil {
+			return false
+		}
+		loadConfig()
+	}
+	return true
+}
+
+func completeTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if !loadCompletionConfig() {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []string
+	for _, t := range commitTypes {
+		if explanation, ok := typeExplanations[t]; ok {
+			t += "\t" + explanation
+		}
+		completions = append(completions, t)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeScopes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if !loadCompletionConfig() {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	descriptions := viper.GetStringMapString("scope_descriptions")
+	var completions []string
+	for _, scope := range scopes {
+		if scope == "none" {
+			continue
+		}
+		if description := descriptions[scope]; description != "" {
+			scope += "\t" + description
+		}
+		completions = append(completions, scope)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFooters completes the tokens of footer_keys and the custom
+// prompts, the value is typed after them
+func completeFooters(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if !loadCompletionConfig() {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	keys := viper.GetStringSlice("footer_keys")
+	for _, p := range customPrompts() {
+		keys = append(keys, p.Footer)
+	}
+	var completions []string
+	for _, key := range slices.Compact(keys) {
+		if strings.HasPrefix(strings.ToLower(key), strings.ToLower(toComplete)) {
+			completions = append(completions, key+": ")
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"os"
+	"path"
+	"sync"
+
+	"github.com/45413/git-cc/pkg/conventional"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// conditionalRule is a rule of conditional_rules, applying to the commits
+// of some types or scopes on some branches only
+type conditionalRule struct {
+	Types          []string `mapstructure:"types"`
+	Scopes         []string `mapstructure:"scopes"`
+	Branches       []string `mapstructure:"branches"`
+	Forbid         bool     `mapstructure:"forbid"`
+	RequireBody    bool     `mapstructure:"require_body"`
+	RequireFooters []string `mapstructure:"require_footers"`
+}
+
+var (
+	conditionsOnce   sync.Once
+	conditionsLoaded []conventional.Condition
+)
+
+// commitConditions returns the conditional_rules which apply on the current
+// branch, warning about invalid ones once
+func commitConditions() []conventional.Condition {
+	conditionsOnce.Do(func() {
+		var rules []conditionalRule
+		if err := viper.UnmarshalKey("conditional_rules", &rules); err != nil {
+			pterm.Warning.Println("Ignoring invalid conditional_rules:", err)
+			return
+		}
+		branch := currentBranch()
+		for _, r := range rules {
+			if !r.Forbid && !r.RequireBody && len(r.RequireFooters) == 0 {
+				pterm.Warning.Println("Ignoring a conditional rule which neither forbids nor requires anything")
+				continue
+			}
+			condition := conventional.Condition{
+				Types:          r.Types,
+				Scopes:         r.Scopes,
+				Forbid:         r.Forbid,
+				RequireBody:    r.RequireBody,
+				RequireFooters: r.RequireFooters,
+			}
+			if len(r.Branches) > 0 {
+				if !matchBranch(branch, r.Branches) {
+					continue
+				}
+				condition.Reason = "on branch " + branch
+			}
+			conditionsLoaded = append(conditionsLoaded, condition)
+		}
+	})
+	return conditionsLoaded
+}
+
+// currentBranch returns the branch checked out or, on the detached HEAD of
+// CI, the branch CI builds or a pull request targets
+func currentBranch() string {
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		return head.Name().Short()
+	}
+	for _, name := range []string{"GITHUB_BASE_REF", "CI_MERGE_REQUEST_TARGET_BRANCH_NAME", "GITHUB_REF_NAME", "CI_COMMIT_BRANCH"} {
+		if branch := os.Getenv(name); branch != "" {
+			return branch
+		}
+	}
+	return ""
+}
+
+// matchBranch reports whether branch matches one of the patterns, such as
+// main or release/*
+func matchBranch(branch string, patterns []string) bool {
+	if branch == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, branch); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// requiresBody reports whether a conditional rule requires a long
+// description for the type and scope answered
+func requiresBody(data CommitPromptData) bool {
+	scope := data.Scope
+	if scope == "none" {
+		scope = ""
+	}
+	for _, c := range commitConditions() {
+		if c.RequireBody && c.Applies(data.Type, scope) {
+			return true
+		}
+	}
+	return false
+}
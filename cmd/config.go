@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+func loadConfig() {
+	// the config format is taken from the file extension
+	// Optional. If you want to support environment variables, use this
+	viper.AutomaticEnv()
+
+	// Set Default Config Values
+	viper.SetDefault("use_defaults", true)
+	viper.SetDefault("setup_wizard", true)
+	viper.SetDefault("custom_commit_types", []string{})
+	viper.SetDefault("scopes", []string{})
+	viper.SetDefault("suggest_scope", true)
+	viper.SetDefault("type_suggestions", defaultTypeSuggestions)
+	viper.SetDefault("rename_similarity", 100)
+	viper.SetDefault("dependency_body", true)
+	viper.SetDefault("generated_paths", []string{})
+	viper.SetDefault("breaking_hints", true)
+	viper.SetDefault("public_paths", []string{})
+	viper.SetDefault("diff_preview", false)
+	viper.SetDefault("suggest_endpoint", "")
+	viper.SetDefault("suggest_model", "")
+	viper.SetDefault("suggest_max_diff", 20000)
+	viper.SetDefault("max_subject_length", 72)
+	viper.SetDefault("max_body_line_length", 100)
+	viper.SetDefault("subject_case", "")
+	viper.SetDefault("forbid_trailing_period", false)
+	viper.SetDefault("imperative_mood", false)
+	viper.SetDefault("rules_url", "")
+	viper.SetDefault("scope_history", 200)
+	viper.SetDefault("scope_discovery", "off")
+	viper.SetDefault("scope_discovery_glob", []string{})
+	viper.SetDefault("co_authors", []string{})
+	viper.SetDefault("co_author_history", 200)
+	viper.SetDefault("ui", "pterm")
+	viper.SetDefault("ui_mode", "standard")
+	viper.SetDefault("prompts", []string{})
+	viper.SetDefault("quick_keys", defaultQuickKeys)
+	viper.SetDefault("theme.preset", "default")
+	viper.SetDefault("ticket_pattern", "")
+	viper.SetDefault("ticket_footer", "Refs")
+	viper.SetDefault("ticket_as_scope", false)
+	viper.SetDefault("issue_prompt", false)
+	viper.SetDefault("issue_footer", "Refs")
+	viper.SetDefault("issue_tracker", "auto")
+	viper.SetDefault("issue_token", "")
+	viper.SetDefault("jira_url", "")
+	viper.SetDefault("jira_email", "")
+	viper.SetDefault("jira_token", "")
+	viper.SetDefault("jira_required", false)
+	viper.SetDefault("jira_footer", "Refs: {key}")
+	viper.SetDefault("branch_template", "{type}/{ticket}-{slug}")
+	viper.SetDefault("protected_branches", []string{})
+	viper.SetDefault("protected_branch_action", "warn")
+	viper.SetDefault("footer_keys", []string{"Refs", "Closes", "Co-authored-by", "Reviewed-by", "Acked-by", "Tested-by"})
+	viper.SetDefault("scope_owners", map[string][]string{})
+	viper.SetDefault("scope_descriptions", map[string]string{})
+	viper.SetDefault("scope_groups", map[string][]string{})
+	viper.SetDefault("watch_debounce", "3s")
+	viper.SetDefault("api_cache_ttl", "5m")
+	viper.SetDefault("uncommitted_reminder", "0s")
+	viper.SetDefault("drafts", "enabled")
+	viper.SetDefault("usage_stats", false)
+	viper.SetDefault("encrypt_drafts", false)
+	viper.SetDefault("commitlint", "git-cc")
+	viper.SetDefault("commitizen", "git-cc")
+	viper.SetDefault("git_exit_codes", false)
+	viper.SetDefault("signoff", false)
+	viper.SetDefault("sign", false)
+	viper.SetDefault("push", false)
+	viper.SetDefault("max_commit_files", 0)
+	viper.SetDefault("max_commit_lines", 0)
+	viper.SetDefault("dependency_bots", true)
+	viper.SetDefault("notify", "off")
+	viper.SetDefault("notify_after", "30s")
+	viper.SetDefault("commit_timeout", "0s")
+	viper.SetDefault("prompt_timeout", "0s")
+	viper.SetDefault("pull_request_style", "auto")
+	viper.SetDefault("changelog_skip_reverted", true)
+	viper.SetDefault("changelog_contributors", false)
+	viper.SetDefault("collapse_dependencies", false)
+	viper.SetDefault("contributor_format", "{name}")
+	viper.SetDefault("release_files", []string{})
+	viper.SetDefault("release_changelog", "CHANGELOG.md")
+	viper.SetDefault("homebrew_formula", "")
+	viper.SetDefault("scoop_manifest", "")
+	viper.SetDefault("release_artifacts", "dist/*")
+	viper.SetDefault("release_packages", []string{})
+	viper.SetDefault("contributor_exclude", []string{})
+	viper.SetDefault("no_exec", false)
+	viper.SetDefault("temp_file_dir", "")
+	viper.SetDefault("body_editor", false)
+	viper.SetDefault("locale", "")
+	viper.SetDefault("language", "")
+	viper.SetDefault("date_format", "")
+	viper.SetDefault("spell_check", true)
+	viper.SetDefault("spell_dictionary", "")
+	viper.SetDefault("spell_words", []string{})
+	viper.SetDefault("bisect_skip_types", []string{"docs", "style", "ci"})
+
+	// Read the user's global configuration file, then merge the one of the
+	// repository over it so repository values win
+	if dir := globalConfigDir(); dir != "" {
+		if path := findConfig(dir, "config"); path != "" {
+			viper.SetConfigFile(path)
+			if err := viper.ReadInConfig(); err != nil {
+				pterm.Warning.Printfln("Error reading config file %s: %s", path, err)
+			}
+		}
+	}
+	// where the staged diff is sent is up to the user, repositories can't
+	// redirect it
+	suggestEndpoint = viper.GetString("suggest_endpoint")
+	// as is recording personal usage stats
+	usageStats = viper.GetBool("usage_stats")
+	// and the token sent to the issue tracker
+	issueToken = viper.GetString("issue_token")
+	// and the Jira token, for the Jira it is meant for
+	jiraToken, jiraTokenURL = viper.GetString("jira_token"), viper.GetString("jira_url")
+	if path := findConfig(gitRoot, ".git-cc"); path != "" {
+		// a shared team config referenced by extends goes in between
+		if extends := configExtends(path); extends != "" {
+			if err := mergeSharedConfig(extends); err != nil {
+				pterm.Warning.Printfln("Failed to load the shared config %s: %s", extends, err)
+			}
+		}
+		viper.SetConfigFile(path)
+		if err := viper.MergeInConfig(); err != nil {
+			pterm.Warning.Printfln("Error reading config file %s: %s", path, err)
+		}
+	} else {
+		pterm.Debug.Println("No .git-cc config file in", gitRoot)
+	}
+
+	resolveTypesAndScopes()
+}
+
+// resolveTypesAndScopes sets the commit types and scopes offered from the
+// config, or the commitlint rules shared with it
+func resolveTypesAndScopes() {
+	scopes = nil
+	use_defaults := viper.GetBool("use_defaults")
+	if use_defaults {
+		commitTypes = append(defaultCommitTypes, viper.GetStringSlice("custom_commit_types")...)
+		if len(viper.GetStringSlice("scopes")) > 0 {
+			scopes = append([]string{"none"}, viper.GetStringSlice("scopes")...)
+		}
+	} else {
+		commitTypes = viper.GetStringSlice("custom_commit_types")
+		scopes = viper.GetStringSlice("scopes")
+	}
+	// repositories migrating from commitizen or standardized on commitlint
+	// share their rules, commitlint's win over commitizen's
+	if viper.GetString("commitizen") != "off" {
+		if rules := loadCommitizen(); rules != nil {
+			applySharedRules(rules, viper.GetString("commitizen") == "commitizen")
+		}
+	}
+	if viper.GetString("commitlint") != "off" {
+		if rules := loadCommitlint(); rules != nil {
+			applySharedRules(rules, viper.GetString("commitlint") == "commitlint")
+		}
+	}
+
+	// packages of a monorepo come and go, their scopes are found on every run
+	if discovered := discoveredScopes(); len(discovered) > 0 {
+		if len(scopes) == 0 && use_defaults {
+			scopes = []string{"none"}
+		}
+		scopes = append(scopes, discovered...)
+	}
+
+	// dedup slices just in case
+	commitTypes = removeDuplicateStr(commitTypes)
+	scopes = removeDuplicateStr(scopes)
+}
+
+func removeDuplicateStr(strSlice []string) []string {
+	allKeys := make(map[string]bool)
+	list := []string{}
+	for _, item := range strSlice {
+		if _, value := allKeys[item]; !value {
+			allKeys[item] = true
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// configExtends returns the extends property of the config file at path
+func configExtends(path string) string {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return ""
+	}
+	return v.GetString("extends")
+}
+
+// configExtensions are the config formats, in order of precedence when a
+// directory holds more than one config file
+var configExtensions = []string{".yaml", ".yml", ".json", ".toml"}
+
+// findConfig returns the config file name in dir, in the first format of
+// configExtensions found, or an empty string when there is none
+func findConfig(dir, name string) string {
+	var found []string
+	for _, ext := range configExtensions {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		}
+	}
+	if len(found) == 0 {
+		return ""
+	}
+	if len(found) > 1 {
+		pterm.Warning.Printfln("Using %s, ignoring %s", found[0], strings.Join(found[1:], ", "))
+	}
+	return found[0]
+}
+
+// globalConfigDir returns the directory of the user's config file shared by
+// all repositories: $XDG_CONFIG_HOME/git-cc, defaulting to ~/.config, or
+// %APPDATA%\git-cc on Windows
+func globalConfigDir() string {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("APPDATA"); dir != "" {
+			return filepath.Join(dir, "git-cc")
+		}
+		return ""
+	}
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "git-cc")
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Maintain the configuration of the repository",
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the .git-cc config of the repository in guided prompts",
+	Long: `Edit the commit types, scopes, validation rules and features of the
+repository in guided prompts, without writing YAML. The prompts resulting
+from the changes can be previewed and tried before saving.
+
+Only the properties changed are written to the .git-cc config file of the
+repository, or a new .git-cc.yaml; its other properties are kept, its
+comments are not.`,
+	Args: cobra.NoArgs,
+	Run:  configEdit,
+}
+
+func init() {
+	configCmd.AddCommand(configEditCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// configFeature is an optional behaviour turned on or off in the config
+type configFeature struct {
+	Key   string
+	Label string
+}
+
+// configFeatures are the features offered by config edit
+var configFeatures = []configFeature{
+	{"suggest_scope", "Suggest a scope from the staged files"},
+	{"dependency_body", "Offer to list dependency changes in the long description"},
+	{"breaking_hints", "Default to a breaking change when the staged changes look like one"},
+	{"diff_preview", "Show the staged diff before the prompts"},
+	{"spell_check", "Offer fixes for likely typos in the descriptions"},
+	{"ticket_as_scope", "Use the ticket of the branch name as scope"},
+	{"signoff", "Add a Signed-off-by trailer"},
+}
+
+const (
+	editTypes    = "Commit types"
+	editScopes   = "Scopes"
+	editRules    = "Validation rules"
+	editFeatures = "Features"
+	editPreview  = "Preview the prompts"
+	editSave     = "Save and quit"
+	editQuit     = "Quit"
+)
+
+func configEdit(cmd *cobra.Command, args []string) {
+	path := findConfig(gitRoot, ".git-cc")
+	if path == "" {
+		path = filepath.Join(gitRoot, ".git-cc.yaml")
+	}
+	// a preview must not leave a draft behind
+	noDraft = true
+
+	config := currentRepoConfig()
+	features := map[string]bool{}
+	for _, f := range configFeatures {
+		features[f.Key] = viper.GetBool(f.Key)
+	}
+	original := editedSettings(config, features)
+
+	for {
+		changed := changedSettings(original, editedSettings(config, features))
+		label := "Edit " + filepath.Base(path)
+		if len(changed) > 0 {
+			label += fmt.Sprintf(" (%d unsaved changes)", len(changed))
+		}
+		choice, _ := ui.Select(label, []string{editTypes, editScopes, editRules, editFeatures, editPreview, editSave, editQuit}, editTypes)
+
+		switch choice {
+		case editTypes:
+			config.askTypes()
+		case editScopes:
+			config.askScopes()
+		case editRules:
+			config.askRules()
+		case editFeatures:
+			config.askEmoji()
+			for _, f := range configFeatures {
+				features[f.Key], _ = ui.Confirm(f.Label, features[f.Key])
+			}
+		case editPreview:
+			previewPrompts(editedSettings(config, features))
+		case editSave:
+			if len(changed) == 0 {
+				pterm.Info.Println("Nothing changed")
+				return
+			}
+			if err := saveSettings(path, changed); err != nil {
+				pterm.Error.Println("Failed to write config:", err)
+				exit(exitcode.Failure)
+			}
+			pterm.Success.Printfln("Updated %s in %s, commit it to share the settings", strings.Join(sortedKeys(changed), ", "), path)
+			return
+		default:
+			if len(changed) == 0 {
+				return
+			}
+			if discard, _ := ui.Confirm(fmt.Sprintf("Discard %d unsaved changes", len(changed)), false); discard {
+				return
+			}
+		}
+	}
+}
+
+// editedSettings returns the config properties edited by config edit
+func editedSettings(config repoConfig, features map[string]bool) map[string]any {
+	settings := config.settings()
+	for key, on := range features {
+		settings[key] = on
+	}
+	return settings
+}
+
+// changedSettings returns the properties of edited differing from original
+func changedSettings(original, edited map[string]any) map[string]any {
+	changed := map[string]any{}
+	for key, value := range edited {
+		if !reflect.DeepEqual(original[key], value) {
+			changed[key] = value
+		}
+	}
+	return changed
+}
+
+func sortedKeys(settings map[string]any) []string {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// saveSettings writes changed to the config file at path, keeping its other
+// properties
+func saveSettings(path string, changed map[string]any) error {
+	file := viper.New()
+	file.SetConfigFile(path)
+	if _, err := os.Stat(path); err == nil {
+		if err := file.ReadInConfig(); err != nil {
+			return err
+		}
+	}
+	for key, value := range changed {
+		file.Set(key, value)
+	}
+	return file.WriteConfigAs(path)
+}
+
+// previewPrompts shows the prompts as they are with settings applied and
+// offers to try them
+func previewPrompts(settings map[string]any) {
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+	resolveTypesAndScopes()
+
+	pterm.DefaultSection.Println("Prompts")
+	pterm.Println(pterm.Bold.Sprint("Commit Type: ") + strings.Join(commitTypes, ", "))
+	if len(scopes) > 0 {
+		options, _ := scopeOptions(groupScopes(scopes))
+		pterm.Println(pterm.Bold.Sprint("Scope: ") + strings.Join(options, ", "))
+	} else {
+		pterm.Println(pterm.Bold.Sprint("Scope: ") + "any, entered freely")
+	}
+	var rules []string
+	if limit := viper.GetInt("max_subject_length"); limit > 0 {
+		rules = append(rules, fmt.Sprintf("header of at most %d characters", limit))
+	}
+	if c := viper.GetString("subject_case"); c != "" {
+		rules = append(rules, c+" case")
+	}
+	if viper.GetBool("forbid_trailing_period") {
+		rules = append(rules, "no trailing period")
+	}
+	if viper.GetBool("imperative_mood") {
+		rules = append(rules, "imperative mood")
+	}
+	if len(rules) == 0 {
+		rules = append(rules, "any")
+	}
+	pterm.Println(pterm.Bold.Sprint("Short Description: ") + strings.Join(rules, ", "))
+
+	if try, _ := ui.Confirm("Try the prompts", false); !try {
+		return
+	}
+	message := buildCommitMessage(askCommitPrompts(commitTypes))
+	pterm.DefaultBox.WithTitle("Commit Message").Println(message)
+	if problems := validateCommitMessage(message); len(problems) > 0 {
+		pterm.Warning.Println("The message breaks the rules")
+		printProblems(message, problems)
+	} else {
+		pterm.Success.Println("The message follows the rules")
+	}
+}
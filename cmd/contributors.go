@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// contributor is an author or co-author of the commits of a release
+type contributor struct {
+	Name  string
+	Email string
+	// Commits counts the commits they authored or co-authored
+	Commits int
+}
+
+// String renders the contributor as contributor_format, whose {name},
+// {email} and {commits} placeholders are replaced
+func (c contributor) String() string {
+	return strings.NewReplacer("{name}", c.Name, "{email}", c.Email, "{commits}", strconv.Itoa(c.Commits)).Replace(viper.GetString("contributor_format"))
+}
+
+// releaseContributors returns the authors and co-authors of commits sorted
+// by name, as .mailmap maps them. Dependency bots, accounts whose name ends
+// in [bot] and those matching contributor_exclude are left out.
+func releaseContributors(commits []conventionalCommit) []contributor {
+	var identities []string
+	for _, c := range commits {
+		if c.DependencyBot != "" {
+			continue
+		}
+		identities = append(identities, c.Commit.Author.Name+" <"+c.Commit.Author.Email+">")
+		for _, t := range c.Trailers {
+			if strings.EqualFold(t.Token, "Co-authored-by") {
+				identities = append(identities, strings.TrimSpace(t.Value))
+			}
+		}
+	}
+
+	var contributors []contributor
+	for _, identity := range mailmapIdentities(identities) {
+		name, email := splitIdentity(identity)
+		if name == "" || excludedContributor(name, email) {
+			continue
+		}
+		// the same person may commit under several emails .mailmap doesn't
+		// know about, the name tells them apart
+		i := slices.IndexFunc(contributors, func(c contributor) bool {
+			return strings.EqualFold(c.Name, name) || email != "" && strings.EqualFold(c.Email, email)
+		})
+		if i < 0 {
+			contributors = append(contributors, contributor{Name: name, Email: email})
+			i = len(contributors) - 1
+		}
+		contributors[i].Commits++
+	}
+	slices.SortFunc(contributors, func(a, b contributor) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return contributors
+}
+
+// mailmapIdentities maps "Name <email>" identities through the .mailmap of
+// the repository and mailmap.file, as git log --use-mailmap does. They are
+// returned as they are when git can't map them.
+func mailmapIdentities(identities []string) []string {
+	if len(identities) == 0 {
+		return nil
+	}
+	var valid []string
+	for _, identity := range identities {
+		// check-mailmap fails on anything without an email
+		if _, email := splitIdentity(identity); email != "" {
+			valid = append(valid, identity)
+		}
+	}
+	valid = removeDuplicateStr(valid)
+	if len(valid) == 0 {
+		return identities
+	}
+	out, err := gitQuiet(append([]string{"check-mailmap"}, valid...)...)
+	if err != nil {
+		pterm.Debug.Println("Failed to map the contributors with .mailmap:", err)
+		return identities
+	}
+	mapped := map[string]string{}
+	for i, line := range strings.Split(out, "\n") {
+		if i < len(valid) {
+			mapped[valid[i]] = line
+		}
+	}
+	result := make([]string, len(identities))
+	for i, identity := range identities {
+		result[i] = identity
+		if m, ok := mapped[identity]; ok {
+			result[i] = m
+		}
+	}
+	return result
+}
+
+// splitIdentity splits "Name <email>" into the name and email
+func splitIdentity(identity string) (string, string) {
+	name, email, found := strings.Cut(identity, "<")
+	if !found {
+		return strings.TrimSpace(identity), ""
+	}
+	return strings.TrimSpace(name), strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(email), ">"))
+}
+
+// excludedContributor reports whether name or email belong to a bot: they
+// end in [bot], as GitHub Apps do, or match a contributor_exclude pattern
+func excludedContributor(name, email string) bool {
+	local, _, _ := strings.Cut(email, "@")
+	if strings.HasSuffix(strings.ToLower(name), "[bot]") || strings.HasSuffix(strings.ToLower(local), "[bot]") {
+		return true
+	}
+	for _, pattern := range viper.GetStringSlice("contributor_exclude") {
+		pattern = strings.ToLower(pattern)
+		for _, s := range []string{name, email} {
+			if matched, err := path.Match(pattern, strings.ToLower(s)); err != nil {
+				pterm.Warning.Printfln("Invalid contributor_exclude pattern %q: %s", pattern, err)
+			} else if matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// previousAuthors returns the lower case names and emails of the authors of
+// the history of from, as .mailmap maps them, none without from
+func previousAuthors(from string) (map[string]bool, error) {
+	known := map[string]bool{}
+	if from == "" {
+		return known, nil
+	}
+	out, err := gitQuiet("log", "--use-mailmap", "--format=%aN%n%aE", from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the authors of %s: %w", from, err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			known[strings.ToLower(line)] = true
+		}
+	}
+	return known, nil
+}
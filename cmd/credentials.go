@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// hostToken returns an API token for a GitHub or GitLab host, reusing the
+// credentials git already knows about before falling back to the gh and
+// glab CLI configuration, so hosted-API features don't need a token of their own.
+func hostToken(host string) (string, error) {
+	if token := gitCredential(host); token != "" {
+		pterm.Debug.Println("Using git credential helper token for", host)
+		return token, nil
+	}
+
+	if token := cliToken(host); token != "" {
+		return token, nil
+	}
+
+	return "", fmt.Errorf("no credentials found for %s (configure a git credential helper or log in with gh/glab)", host)
+}
+
+// gitCredential asks the configured git credential helpers for the password
+// stored for host, without ever prompting the user.
+func gitCredential(host string) string {
+	cmd := exec.Command("git", "credential", "fill")
+	cmd.Dir = gitRoot
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+
+	out, err := cmd.Output()
+	if err != nil {
+		pterm.Debug.Println("git credential fill failed:", err)
+		return ""
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		if password, ok := strings.CutPrefix(scanner.Text(), "password="); ok {
+			return password
+		}
+	}
+	return ""
+}
+
+// cliToken reads the token of a logged in gh or glab CLI for host
+func cliToken(host string) string {
+	for _, cli := range [][]string{
+		{"gh", "auth", "token", "--hostname", host},
+		{"glab", "config", "get", "token", "--host", host},
+	} {
+		if _, err := exec.LookPath(cli[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(cli[0], cli[1:]...).Output()
+		if token := strings.TrimSpace(string(out)); err == nil && token != "" {
+			pterm.Debug.Printfln("Using %s token for %s", cli[0], host)
+			return token
+		}
+	}
+
+	// read the config files directly when the CLIs aren't installed
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	ghConfig := filepath.Join(configDir, "gh", "hosts.yml")
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		ghConfig = filepath.Join(dir, "hosts.yml")
+	}
+	for file, key := range map[string]string{
+		ghConfig: host + "::oauth_token",
+		filepath.Join(configDir, "glab-cli", "config.yml"): "hosts::" + host + "::token",
+	} {
+		// host names contain dots, which is viper's default key delimiter
+		v := viper.NewWithOptions(viper.KeyDelimiter("::"))
+		v.SetConfigFile(file)
+		v.SetConfigType("yaml")
+		if err := v.ReadInConfig(); err != nil {
+			continue
+		}
+		if token := v.GetString(key); token != "" {
+			pterm.Debug.Printfln("Using token from %s for %s", file, host)
+			return token
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// kinds of custom prompts
+const (
+	selectPrompt  = "select"
+	textPrompt    = "text"
+	confirmPrompt = "confirm"
+)
+
+// customPrompt is a prompt a repository adds with custom_prompts, its
+// answer is added to the message as footer
+type customPrompt struct {
+	Name     string   `mapstructure:"name"`
+	Label    string   `mapstructure:"label"`
+	Kind     string   `mapstructure:"kind"`
+	Options  []string `mapstructure:"options"`
+	Required bool     `mapstructure:"required"`
+	// Footer is the token of the footer, the label with dashes for spaces
+	// by default
+	Footer string `mapstructure:"footer"`
+}
+
+var (
+	customPromptsOnce   sync.Once
+	customPromptsLoaded []customPrompt
+)
+
+// customPrompts returns the valid prompts of custom_prompts, warning about
+// the others once
+func customPrompts() []customPrompt {
+	customPromptsOnce.Do(func() {
+		var prompts []customPrompt
+		if err := viper.UnmarshalKey("custom_prompts", &prompts); err != nil {
+			pterm.Warning.Println("Ignoring invalid custom_prompts:", err)
+			return
+		}
+		for _, p := range prompts {
+			if p.Label == "" {
+				p.Label = p.Name
+			}
+			if p.Kind == "" {
+				p.Kind = textPrompt
+			}
+			if p.Footer == "" {
+				p.Footer = strings.Join(strings.Fields(p.Label), "-")
+			}
+			switch {
+			case p.Name == "":
+				pterm.Warning.Printfln("Ignoring custom prompt %q without a name", p.Label)
+			case !slices.Contains([]string{selectPrompt, textPrompt, confirmPrompt}, p.Kind):
+				pterm.Warning.Printfln("Ignoring custom prompt %s of unknown kind %q, expected select, text or confirm", p.Name, p.Kind)
+			case p.Kind == selectPrompt && len(p.Options) == 0:
+				pterm.Warning.Printfln("Ignoring custom prompt %s, a select needs options", p.Name)
+			case !footerTokenPattern.MatchString(p.Footer):
+				pterm.Warning.Printfln("Ignoring custom prompt %s, footer %q must be a single word", p.Name, p.Footer)
+			default:
+				customPromptsLoaded = append(customPromptsLoaded, p)
+			}
+		}
+	})
+	return customPromptsLoaded
+}
+
+// footerValue returns the value of the first of footers with token
+func footerValue(footers []trailer, token string) string {
+	for _, t := range footers {
+		if strings.EqualFold(t.Token, token) {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+// askCustomPrompts asks the custom prompts, starting with the answers in
+// the footers of promptDefaults, and sets their footers in data
+func askCustomPrompts(data CommitPromptData) CommitPromptData {
+	for _, p := range customPrompts() {
+		// answered by GIT_CC_FOOTERS or --stdin
+		if preAnswered[footersField] && footerValue(preAnswers.Footers, p.Footer) != "" {
+			continue
+		}
+		previous := footerValue(promptDefaults.Footers, p.Footer)
+		var answer string
+		switch p.Kind {
+		case selectPrompt:
+			options := p.Options
+			if !p.Required {
+				options = append(slices.Clip(options), "none")
+			}
+			defaultOption := previous
+			if !slices.Contains(options, defaultOption) {
+				defaultOption = ""
+			}
+			answer, _ = ui.Select(p.Label, options, defaultOption)
+			if answer == "none" && !p.Required {
+				answer = ""
+			}
+		case confirmPrompt:
+			yes, _ := ui.Confirm(p.Label, previous == "yes")
+			answer = "no"
+			if yes {
+				answer = "yes"
+			}
+		default:
+			label := p.Label
+			if !p.Required {
+				label += " (optional)"
+			}
+			for {
+				answer, _ = ui.Input(label, previous)
+				answer = strings.TrimSpace(answer)
+				if answer != "" || !p.Required {
+					break
+				}
+				pterm.Warning.Printfln("%s is required", p.Label)
+			}
+		}
+
+		data.Footers = slices.DeleteFunc(slices.Clone(data.Footers), func(t trailer) bool {
+			return strings.EqualFold(t.Token, p.Footer)
+		})
+		if answer != "" {
+			data.Footers = append(data.Footers, trailer{Token: p.Footer, Separator: ": ", Value: answer})
+		}
+	}
+	return data
+}
+
+// checkCustomFooters returns an error unless the footers answer the
+// required custom prompts with one of their options
+func checkCustomFooters(footers []trailer) error {
+	for _, p := range customPrompts() {
+		value := footerValue(footers, p.Footer)
+		switch {
+		case value == "" && p.Required:
+			return fmt.Errorf("footer %s (%s) is required", p.Footer, p.Label)
+		case value == "":
+		case p.Kind == selectPrompt && !slices.Contains(p.Options, value):
+			return fmt.Errorf("footer %s must be one of %s", p.Footer, strings.Join(p.Options, ", "))
+		case p.Kind == confirmPrompt && value != "yes" && value != "no":
+			return fmt.Errorf("footer %s must be yes or no", p.Footer)
+		}
+	}
+	return nil
+}
+
+// customAnswers returns the answers of the custom prompts in footers by
+// the names of the prompts
+func customAnswers(footers []trailer) map[string]string {
+	answers := map[string]string{}
+	for _, p := range customPrompts() {
+		answers[p.Name] = footerValue(footers, p.Footer)
+	}
+	return answers
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// renovateFiles are the config files renovate looks up, besides the
+// renovate key of package.json
+var renovateFiles = []string{
+	"renovate.json",
+	".github/renovate.json",
+	".gitlab/renovate.json",
+	".renovaterc",
+	".renovaterc.json",
+}
+
+var dependabotFiles = []string{".github/dependabot.yml", ".github/dependabot.yaml"}
+
+// dependencyBot is a dependency update bot configured in the repository
+// and how its commits are classified
+type dependencyBot struct {
+	Name string
+	// Authors are the names and emails the bot commits as
+	Authors []string
+	// BranchPrefix starts the branches of its pull requests, which merges
+	// of them name
+	BranchPrefix string
+	// Type and Scope are given to its commits which aren't conventional,
+	// such as dependabot's "Bump x from 1.0 to 1.1"
+	Type, Scope string
+}
+
+var (
+	dependencyBotsOnce   sync.Once
+	dependencyBotsLoaded []dependencyBot
+)
+
+// dependencyBots returns the bots configured by renovate and dependabot
+// config files, read once per run
+func dependencyBots() []dependencyBot {
+	dependencyBotsOnce.Do(func() {
+		if !viper.GetBool("dependency_bots") {
+			return
+		}
+		if bot, ok := loadRenovate(); ok {
+			dependencyBotsLoaded = append(dependencyBotsLoaded, bot)
+		}
+		if bot, ok := loadDependabot(); ok {
+			dependencyBotsLoaded = append(dependencyBotsLoaded, bot)
+		}
+	})
+	return dependencyBotsLoaded
+}
+
+// readBotConfig reads the first of files which exists, nil if there is none
+func readBotConfig(files []string, key string) *viper.Viper {
+	for _, name := range files {
+		content, err := os.ReadFile(filepath.Join(gitRoot, name))
+		if err != nil {
+			continue
+		}
+		v := viper.New()
+		v.SetConfigType("json")
+		if ext := filepath.Ext(name); ext == ".yml" || ext == ".yaml" {
+			v.SetConfigType("yaml")
+		}
+		if err := v.ReadConfig(bytes.NewReader(content)); err != nil {
+			pterm.Warning.Printfln("Ignoring %s: %s", name, err)
+			return nil
+		}
+		if key != "" {
+			if !v.IsSet(key) {
+				continue
+			}
+			v = v.Sub(key)
+		}
+		return v
+	}
+	return nil
+}
+
+func loadRenovate() (dependencyBot, bool) {
+	v := readBotConfig(renovateFiles, "")
+	if v == nil {
+		if v = readBotConfig([]string{"package.json"}, "renovate"); v == nil {
+			return dependencyBot{}, false
+		}
+	}
+	// renovate's defaults
+	bot := dependencyBot{
+		Name:         "renovate",
+		Authors:      []string{"renovate[bot]", "renovate-bot", "bot@renovateapp.com"},
+		BranchPrefix: "renovate/",
+		Type:         "chore",
+		Scope:        "deps",
+	}
+	if prefix := v.GetString("branchprefix"); prefix != "" {
+		bot.BranchPrefix = prefix
+	}
+	if t := v.GetString("semanticcommittype"); t != "" {
+		bot.Type = t
+	}
+	if v.IsSet("semanticcommitscope") {
+		bot.Scope = v.GetString("semanticcommitscope")
+	}
+	// Name <email> of a self-hosted bot
+	if author := v.GetString("gitauthor"); author != "" {
+		name, email, _ := strings.Cut(author, "<")
+		bot.Authors = append(bot.Authors, strings.TrimSpace(name), strings.TrimSuffix(strings.TrimSpace(email), ">"))
+	}
+	return bot, true
+}
+
+func loadDependabot() (dependencyBot, bool) {
+	v := readBotConfig(dependabotFiles, "")
+	if v == nil {
+		return dependencyBot{}, false
+	}
+	bot := dependencyBot{
+		Name:         "dependabot",
+		Authors:      []string{"dependabot[bot]", "dependabot-preview[bot]"},
+		BranchPrefix: "dependabot/",
+		Type:         "chore",
+		Scope:        "deps",
+	}
+	// the prefix of the first update naming a commit type, e.g. build
+	for _, update := range cast.ToSlice(v.Get("updates")) {
+		message := cast.ToStringMap(cast.ToStringMap(update)["commit-message"])
+		prefix := strings.TrimSuffix(cast.ToString(message["prefix"]), ":")
+		if slices.Contains(commitTypes, prefix) {
+			bot.Type = prefix
+			break
+		}
+	}
+	return bot, true
+}
+
+// dependencyBotOf returns the bot which made c, nil for other commits
+func dependencyBotOf(c *object.Commit) *dependencyBot {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	for i, bot := range dependencyBots() {
+		if slices.ContainsFunc(bot.Authors, func(author string) bool {
+			return strings.EqualFold(author, c.Author.Name) || strings.EqualFold(author, c.Author.Email)
+		}) {
+			return &dependencyBots()[i]
+		}
+		// Merge pull request #1 from org/dependabot/... and Merge branch 'renovate/...'
+		if strings.HasPrefix(subject, "Merge ") && (strings.Contains(subject, "/"+bot.BranchPrefix) || strings.Contains(subject, "'"+bot.BranchPrefix)) {
+			return &dependencyBots()[i]
+		}
+	}
+	return nil
+}
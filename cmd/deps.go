@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// dependencyPatterns parse a dependency and its version from a line of the
+// manifests by file name, the lock files are left out as they repeat the
+// manifests with all transitive dependencies
+var dependencyPatterns = map[string]*regexp.Regexp{
+	// require blocks and single line requires, versions start with v
+	"go.mod": regexp.MustCompile(`^\s*(?:require\s+)?([^\s()]+)\s+(v[^\s/]+)`),
+	// "name": "^1.2.3", values not looking like a version are skipped
+	"package.json": regexp.MustCompile(`^\s*"([^"]+)"\s*:\s*"([~^<>=]*\d[^"]*)"`),
+	// name = "1.2" or name = { version = "1.2", ... }
+	"Cargo.toml": regexp.MustCompile(`^\s*([\w-]+)\s*=\s*(?:\{.*\bversion\s*=\s*)?"([^"]+)"`),
+	// name==1.2, name>=1.2
+	"requirements.txt": regexp.MustCompile(`^\s*([\w.\[\]-]+)\s*([=<>~!]=.+?)\s*(?:#.*)?$`),
+}
+
+// dependencyChange is a dependency added, removed or changed to another version
+type dependencyChange struct {
+	Name     string
+	From, To string
+}
+
+func (c dependencyChange) String() string {
+	switch {
+	case c.From == "":
+		return fmt.Sprintf("add %s %s", c.Name, c.To)
+	case c.To == "":
+		return fmt.Sprintf("remove %s %s", c.Name, c.From)
+	}
+	return fmt.Sprintf("bump %s from %s to %s", c.Name, c.From, c.To)
+}
+
+var (
+	stagedDependencies     []dependencyChange
+	stagedDependenciesOnce sync.Once
+)
+
+// dependencyChanges returns the dependency changes of the staged manifests,
+// read once per run
+func dependencyChanges() []dependencyChange {
+	stagedDependenciesOnce.Do(func() {
+		if !viper.GetBool("dependency_body") {
+			return
+		}
+		out, err := gitOutput("diff", "--cached", "--unified=0", "--no-color", "--no-ext-diff")
+		if err != nil {
+			pterm.Debug.Println("Failed to diff staged files:", err)
+			return
+		}
+		stagedDependencies = parseDependencyChanges(out)
+	})
+	return stagedDependencies
+}
+
+// parseDependencyChanges collects the versions removed and added in the
+// manifests of diff and pairs them up by dependency
+func parseDependencyChanges(diff string) []dependencyChange {
+	var changes []dependencyChange
+	index := map[string]int{}
+	var pattern *regexp.Regexp
+	var file string
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			_, name, _ := strings.Cut(line, " b/")
+			file = name
+			pattern = dependencyPatterns[path.Base(name)]
+			continue
+		}
+		if pattern == nil || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++") {
+			continue
+		}
+		if !strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "+") {
+			continue
+		}
+		match := pattern.FindStringSubmatch(line[1:])
+		// the version of the package itself isn't a dependency
+		if match == nil || match[1] == "version" || match[1] == "module" || match[1] == "go" {
+			continue
+		}
+
+		key := file + "\x00" + match[1]
+		i, ok := index[key]
+		if !ok {
+			i = len(changes)
+			index[key] = i
+			changes = append(changes, dependencyChange{Name: match[1]})
+		}
+		if line[0] == '-' {
+			changes[i].From = match[2]
+		} else {
+			changes[i].To = match[2]
+		}
+	}
+
+	// lines moved without changing the version
+	kept := changes[:0]
+	for _, c := range changes {
+		if c.From != c.To {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// dependencyBody lists the dependency changes for the long description
+func dependencyBody(changes []dependencyChange) string {
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = "- " + c.String()
+	}
+	return strings.Join(lines, "\n")
+}
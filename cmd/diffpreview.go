@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// showDiff shows the staged diff before the prompts, set by --diff
+var showDiff bool
+
+// continueOption ends the diff preview
+const continueOption = "Continue"
+
+// diffPreviewEnabled reports whether the staged diff is shown before the
+// prompts, by --diff or diff_preview
+func diffPreviewEnabled() bool {
+	return showDiff || viper.GetBool("diff_preview")
+}
+
+// showStagedDiff lists the staged files with their inserted and deleted
+// lines and shows the patch of each file picked, until the user continues
+func showStagedDiff() {
+	stat, err := stagedDiff("--stat")
+	if err != nil {
+		pterm.Warning.Println("Failed to diff staged files:", err)
+		return
+	}
+	if strings.TrimSpace(stat) == "" {
+		return
+	}
+	pterm.Print(stat)
+
+	out, err := gitOutput("diff", "--cached", "--name-only", "-z")
+	if err != nil {
+		return
+	}
+	files := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for {
+		file, err := ui.Select("Show the patch of", append([]string{continueOption}, files...), continueOption)
+		if err != nil || file == continueOption {
+			return
+		}
+		patch, err := stagedDiff("--", file)
+		if err != nil {
+			pterm.Warning.Println("Failed to diff", file+":", err)
+			continue
+		}
+		pterm.Print(patch)
+	}
+}
+
+// stagedDiff runs git diff --cached with args, colored unless colors are
+// disabled
+func stagedDiff(args ...string) (string, error) {
+	color := "--color=always"
+	if pterm.RawOutput {
+		color = "--no-color"
+	}
+	cmd := exec.Command("git", append([]string{"diff", "--cached", color}, args...)...)
+	cmd.Dir = gitRoot
+	out, err := cmd.Output()
+	return string(out), err
+}
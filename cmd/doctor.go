@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that the configuration matches how the repository is used",
+	Long: `Check that the configuration matches how the repository is used. All
+checks run unless some are selected by their flags.
+
+--conventions compares the types and scopes of the recent history with the
+configured ones: types and scopes used at least --min-uses times but not
+configured should be added, configured custom types and scopes no recent
+commit used might be obsolete. The suggested custom_commit_types and scopes
+are printed for .git-cc.yaml.`,
+	Example: `  git cc doctor
+  git cc doctor --conventions --commits 1000 --min-uses 5`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         doctor,
+}
+
+func init() {
+	doctorCmd.Flags().Bool("conventions", false, "Compare the types and scopes of the history with the config")
+	doctorCmd.Flags().Int("commits", 500, "Number of recent commits to analyze")
+	doctorCmd.Flags().Int("min-uses", 3, "Number of uses from which a type or scope missing in the config is reported")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func doctor(cmd *cobra.Command, args []string) {
+	conventions, _ := cmd.Flags().GetBool("conventions")
+	all := !conventions
+
+	if all || conventions {
+		commits, _ := cmd.Flags().GetInt("commits")
+		minUses, _ := cmd.Flags().GetInt("min-uses")
+		checkConventions(max(commits, 1), max(minUses, 1))
+	}
+}
+
+// conventionUsage counts how often each type and scope is used
+type conventionUsage struct {
+	Commits int
+	Types   map[string]int
+	Scopes  map[string]int
+}
+
+// readConventionUsage counts the types and scopes of the last n conventional
+// commits, merges and other commits are skipped
+func readConventionUsage(n int) (conventionUsage, error) {
+	usage := conventionUsage{Types: map[string]int{}, Scopes: map[string]int{}}
+	out, err := gitOutput("log", "--no-merges", "--format=%s", "--max-count="+strconv.Itoa(n))
+	if err != nil {
+		return usage, err
+	}
+	for _, subject := range strings.Split(out, "\n") {
+		match := headerPattern.FindStringSubmatch(subject)
+		if match == nil {
+			continue
+		}
+		usage.Commits++
+		usage.Types[match[1]]++
+		if match[2] != "" {
+			usage.Scopes[match[2]]++
+		}
+	}
+	return usage, nil
+}
+
+// conventionDrift holds the types and scopes to add to or remove from the
+// config
+type conventionDrift struct {
+	MissingTypes, UnusedTypes   []string
+	MissingScopes, UnusedScopes []string
+}
+
+func (d conventionDrift) empty() bool {
+	return len(d.MissingTypes)+len(d.UnusedTypes)+len(d.MissingScopes)+len(d.UnusedScopes) == 0
+}
+
+// findConventionDrift compares usage with the configured types and scopes.
+// The default types aren't reported as unused, they can only be turned off
+// all together.
+func findConventionDrift(usage conventionUsage, minUses int) conventionDrift {
+	var drift conventionDrift
+	for _, t := range byUses(usage.Types) {
+		if usage.Types[t] >= minUses && !slices.Contains(commitTypes, t) {
+			drift.MissingTypes = append(drift.MissingTypes, t)
+		}
+	}
+	for _, t := range customTypes() {
+		if usage.Types[t] == 0 {
+			drift.UnusedTypes = append(drift.UnusedTypes, t)
+		}
+	}
+	for _, s := range byUses(usage.Scopes) {
+		if usage.Scopes[s] >= minUses && !slices.Contains(scopes, s) {
+			drift.MissingScopes = append(drift.MissingScopes, s)
+		}
+	}
+	for _, s := range configuredScopes() {
+		if usage.Scopes[s] == 0 {
+			drift.UnusedScopes = append(drift.UnusedScopes, s)
+		}
+	}
+	return drift
+}
+
+// customTypes returns the types offered besides the default ones, which
+// includes those of commitlint
+func customTypes() []string {
+	if !viper.GetBool("use_defaults") {
+		return commitTypes
+	}
+	return without(commitTypes, defaultCommitTypes)
+}
+
+// configuredScopes returns the scopes offered, without the implicit none
+func configuredScopes() []string {
+	return without(scopes, []string{"none"})
+}
+
+// byUses returns the keys of counts, most used first
+func byUses(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func checkConventions(n, minUses int) {
+	pterm.DefaultSection.Println("Conventions")
+	usage, err := readConventionUsage(n)
+	if err != nil {
+		pterm.Error.Println("Failed to read history:", err)
+		exit(exitcode.Failure)
+	}
+	if usage.Commits == 0 {
+		pterm.Info.Println("No conventional commits to compare the config with")
+		return
+	}
+
+	drift := findConventionDrift(usage, minUses)
+	if drift.empty() {
+		pterm.Success.Printfln("The types and scopes of the last %d conventional commits match the config", usage.Commits)
+		return
+	}
+
+	table := pterm.TableData{{"", "Used", "Config"}}
+	for _, t := range drift.MissingTypes {
+		table = append(table, []string{"type " + t, strconv.Itoa(usage.Types[t]), pterm.Yellow("missing")})
+	}
+	for _, t := range drift.UnusedTypes {
+		table = append(table, []string{"type " + t, "0", pterm.Gray("unused")})
+	}
+	for _, s := range drift.MissingScopes {
+		table = append(table, []string{"scope " + s, strconv.Itoa(usage.Scopes[s]), pterm.Yellow("missing")})
+	}
+	for _, s := range drift.UnusedScopes {
+		table = append(table, []string{"scope " + s, "0", pterm.Gray("unused")})
+	}
+	pterm.Warning.Printfln("The config doesn't match the last %d conventional commits", usage.Commits)
+	pterm.DefaultTable.WithHasHeader().WithData(table).Render()
+	pterm.Info.Println("Suggested for .git-cc.yaml:")
+	fmt.Print(suggestedConventions(drift))
+}
+
+// suggestedConventions renders the config lists with the drift applied,
+// only those which change
+func suggestedConventions(drift conventionDrift) string {
+	var b strings.Builder
+	if len(drift.MissingTypes)+len(drift.UnusedTypes) > 0 {
+		types := without(customTypes(), drift.UnusedTypes)
+		b.WriteString("custom_commit_types:" + yamlList(append(types, drift.MissingTypes...)) + "\n")
+	}
+	if len(drift.MissingScopes)+len(drift.UnusedScopes) > 0 {
+		configured := without(configuredScopes(), drift.UnusedScopes)
+		b.WriteString("scopes:" + yamlList(append(configured, drift.MissingScopes...)) + "\n")
+	}
+	return b.String()
+}
+
+// without returns items except those in removed
+func without(items, removed []string) []string {
+	var kept []string
+	for _, item := range items {
+		if !slices.Contains(removed, item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
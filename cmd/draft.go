@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// noDraft skips the draft store for one run, set by --no-draft
+var noDraft bool
+
+// commitDraft holds the answers of an unfinished commit message, saved after
+// every prompt so they survive an aborted prompt or a failed commit
+type commitDraft struct {
+	Saved   time.Time         `json:"saved"`
+	Answers *CommitPromptData `json:"answers,omitempty"`
+	// Sealed holds the encrypted answers instead when encrypt_drafts is set
+	Sealed *sealedDraft `json:"sealed,omitempty"`
+}
+
+// warns once per run that drafts can't be encrypted
+var sealWarning sync.Once
+
+// draftsDir holds one draft per branch
+func draftsDir() string {
+	return filepath.Join(gitDir(), "git-cc", "drafts")
+}
+
+// draftPath returns the draft file of the current branch, so an unfinished
+// commit on one branch isn't offered on another. The name is escaped as
+// branch names may contain slashes.
+func draftPath() string {
+	branch := "HEAD"
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		branch = head.Name().Short()
+	} else if ref, err := repo.Storer.Reference(plumbing.HEAD); err == nil && ref.Type() == plumbing.SymbolicReference {
+		// unborn branch of a fresh repository
+		branch = ref.Target().Short()
+	}
+	return filepath.Join(draftsDir(), url.PathEscape(branch)+".json")
+}
+
+// draftsEnabled reports whether answers may be persisted, drafts: disabled
+// turns the store off entirely
+func draftsEnabled() bool {
+	return !noDraft && !readOnly() && viper.GetString("drafts") != "disabled"
+}
+
+// saveDraft persists the answers given so far, amended messages are kept
+// in their commit anyway
+func saveDraft(data CommitPromptData) {
+	if !draftsEnabled() || amend {
+		return
+	}
+	if err := writeDraft(draftPath(), data); errors.Is(err, errUnsealed) {
+		sealWarning.Do(func() { pterm.Warning.Println("Not saving a draft,", err) })
+	} else if err != nil {
+		pterm.Debug.Println("Failed to save draft:", err)
+	}
+}
+
+// errUnsealed is returned for drafts that can't be encrypted
+var errUnsealed = errors.New("it can't be encrypted")
+
+// writeDraft saves answers to path, encrypted when encrypt_drafts is set
+func writeDraft(path string, data CommitPromptData) error {
+	draft := commitDraft{Saved: time.Now(), Answers: &data}
+	if viper.GetBool("encrypt_drafts") {
+		// never fall back to plain text
+		plain, err := json.Marshal(data)
+		if err == nil {
+			draft.Answers = nil
+			draft.Sealed, err = sealDraft(plain)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", errUnsealed, err)
+		}
+	}
+
+	content, err := json.MarshalIndent(draft, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, content, 0o600)
+}
+
+// readDraft loads the draft saved at path, decrypting its answers
+func readDraft(path string) (commitDraft, error) {
+	var draft commitDraft
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return draft, err
+	}
+	if err := json.Unmarshal(content, &draft); err != nil {
+		return draft, fmt.Errorf("unreadable draft: %w", err)
+	}
+	if draft.Sealed != nil {
+		plain, err := draft.Sealed.open()
+		if err == nil {
+			err = json.Unmarshal(plain, &draft.Answers)
+		}
+		if err != nil {
+			return draft, fmt.Errorf("failed to decrypt the draft: %w", err)
+		}
+	}
+	if draft.Answers == nil {
+		return draft, fmt.Errorf("draft without answers")
+	}
+	return draft, nil
+}
+
+// resumeDraft offers to continue with the answers of an unfinished commit
+// message. Declined drafts are removed, as are all drafts once the store
+// is disabled.
+func resumeDraft() (CommitPromptData, bool) {
+	if viper.GetString("drafts") == "disabled" {
+		if err := shredDrafts(); err != nil {
+			pterm.Warning.Println("Failed to delete drafts:", err)
+		}
+		return CommitPromptData{}, false
+	}
+	if !draftsEnabled() {
+		return CommitPromptData{}, false
+	}
+
+	draft, err := readDraft(draftPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return CommitPromptData{}, false
+	} else if err != nil {
+		pterm.Warning.Println(tr("Ignoring the unfinished commit message:"), err)
+		return CommitPromptData{}, false
+	}
+
+	resume, _ := ui.Confirm(tr("Resume the unfinished commit message from %s", draft.Saved.Format("Jan 2 15:04")), true)
+	if !resume {
+		removeDraft()
+		return CommitPromptData{}, false
+	}
+	return *draft.Answers, true
+}
+
+// removeDraft deletes the draft once its commit has been created, together
+// with the named draft it was resumed from
+func removeDraft() {
+	if err := os.Remove(draftPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		pterm.Debug.Println("Failed to remove draft:", err)
+	}
+	if namedDraft != "" {
+		if err := shredFile(namedDraftPath(namedDraft)); err != nil {
+			pterm.Debug.Println("Failed to remove draft:", err)
+		}
+	}
+}
+
+// shredDrafts securely deletes the drafts of all branches, the named drafts
+// and the single draft of earlier versions
+func shredDrafts() error {
+	paths, err := filepath.Glob(filepath.Join(draftsDir(), "*.json"))
+	if err != nil {
+		return err
+	}
+	named, err := filepath.Glob(filepath.Join(namedDraftsDir(), "*.json"))
+	if err != nil {
+		return err
+	}
+	paths = append(paths, named...)
+	for _, path := range append(paths, filepath.Join(gitDir(), "git-cc", "draft.json")) {
+		if err := shredFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shredFile overwrites a file with zeros before deleting it, so the answers
+// don't linger in free disk blocks
+func shredFile(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(make([]byte, info.Size()))
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
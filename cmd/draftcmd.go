@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// namedDraft is the named draft resumed by draft resume, removed once its
+// commit has been created
+var namedDraft string
+
+var draftCmd = &cobra.Command{
+	Use:   "draft",
+	Short: "Save commit messages as named drafts and resume them later",
+	Long: `Save a partially composed commit message as a named draft, e.g. when a
+review forces you to switch tasks, and resume it later. Unlike the draft
+saved automatically while prompting, named drafts aren't tied to a branch
+and are kept until they are resumed or deleted.
+
+Named drafts are stored in .git/git-cc/drafts/named/ and encrypted like
+the automatic ones when encrypt_drafts is set.`,
+	Example: `  git cc draft save login-form
+  git cc draft list
+  git cc draft resume login-form`,
+}
+
+var draftSaveCmd = &cobra.Command{
+	Use:   "save [<name>]",
+	Short: "Prompt for a commit message and save it as a named draft",
+	Long: `Prompt for a commit message and save it as a named draft instead of
+committing. An unfinished commit message of the current branch is offered
+to start from. The answers don't need to be complete yet, they are
+validated once the draft is resumed. The name defaults to the current
+branch.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  draftSave,
+}
+
+var draftListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "List the named drafts",
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         draftList,
+}
+
+var draftResumeCmd = &cobra.Command{
+	Use:   "resume <name>",
+	Short: "Commit the staged changes, prompting with a named draft pre-filled",
+	Args:  cobra.ExactArgs(1),
+	Run:   draftResume,
+}
+
+var draftDeleteCmd = &cobra.Command{
+	Use:   "delete <name>...",
+	Short: "Securely delete named drafts",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   draftDelete,
+}
+
+func init() {
+	draftSaveCmd.Flags().BoolP("force", "f", false, "Replace an existing draft of the same name")
+	draftResumeCmd.Flags().BoolVarP(&stageAll, "all", "a", false, "Stage all changes to tracked files before committing")
+	draftCmd.AddCommand(draftSaveCmd, draftListCmd, draftResumeCmd, draftDeleteCmd)
+	rootCmd.AddCommand(draftCmd)
+}
+
+// namedDraftsDir holds the drafts saved by draft save
+func namedDraftsDir() string {
+	return filepath.Join(draftsDir(), "named")
+}
+
+// namedDraftPath returns the file of a named draft, escaped like branch drafts
+func namedDraftPath(name string) string {
+	return filepath.Join(namedDraftsDir(), url.PathEscape(name)+".json")
+}
+
+// readNamedDraft loads a named draft, erroring with a hint if it doesn't exist
+func readNamedDraft(name string) (commitDraft, error) {
+	draft, err := readDraft(namedDraftPath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return draft, fmt.Errorf("no draft named %q, see git cc draft list", name)
+	}
+	return draft, err
+}
+
+func draftSave(cmd *cobra.Command, args []string) {
+	if !draftsEnabled() {
+		pterm.Error.Println("drafts are disabled")
+		exit(exitcode.Failure)
+	}
+
+	name := "HEAD"
+	if len(args) == 1 {
+		name = args[0]
+	} else if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		name = head.Name().Short()
+	}
+	if strings.TrimSpace(name) == "" {
+		pterm.Error.Println("the draft name must not be empty")
+		exit(exitcode.Failure)
+	}
+	path := namedDraftPath(name)
+	if force, _ := cmd.Flags().GetBool("force"); !force {
+		if _, err := os.Stat(path); err == nil {
+			pterm.Error.Printfln("a draft named %q exists already, use --force to replace it", name)
+			exit(exitcode.Failure)
+		}
+	}
+
+	if data, ok := resumeDraft(); ok {
+		promptDefaults = data
+	}
+	data := askCommitPrompts(commitTypes)
+
+	if err := writeDraft(path, data); err != nil {
+		pterm.Error.Println("Failed to save draft:", err)
+		exit(exitcode.Failure)
+	}
+	// the answers live on in the named draft
+	removeDraft()
+	pterm.Success.Printfln("Saved draft %q, continue with git cc draft resume %s", name, shellQuote(name))
+}
+
+func draftList(cmd *cobra.Command, args []string) {
+	paths, err := filepath.Glob(filepath.Join(namedDraftsDir(), "*.json"))
+	if err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+	if len(paths) == 0 {
+		pterm.Info.Println("No named drafts")
+		return
+	}
+	sort.Strings(paths)
+
+	table := pterm.TableData{{"Name", "Saved", "Message"}}
+	for _, path := range paths {
+		name, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil {
+			continue
+		}
+		draft, err := readDraft(path)
+		if err != nil {
+			table = append(table, []string{name, "", err.Error()})
+			continue
+		}
+		header, _, _ := strings.Cut(buildCommitMessage(*draft.Answers), "\n")
+		table = append(table, []string{name, draft.Saved.Format("Jan 2 15:04"), header})
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(table).Render()
+}
+
+func draftResume(cmd *cobra.Command, args []string) {
+	draft, err := readNamedDraft(args[0])
+	if err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+	namedDraft = args[0]
+	promptDefaults = *draft.Answers
+
+	checkStagedChanges()
+	createCommit()
+}
+
+func draftDelete(cmd *cobra.Command, args []string) {
+	failed := false
+	for _, name := range args {
+		path := namedDraftPath(name)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			pterm.Error.Printfln("no draft named %q", name)
+			failed = true
+			continue
+		}
+		if err := shredFile(path); err != nil {
+			pterm.Error.Printfln("Failed to delete draft %q: %v", name, err)
+			failed = true
+			continue
+		}
+		pterm.Success.Printfln("Deleted draft %q", name)
+	}
+	if failed {
+		exit(exitcode.Failure)
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// draftChallenge is signed with a key of the ssh-agent to derive the draft
+// key. Ed25519 and RSA signatures are deterministic, so signing it again
+// yields the same key.
+const draftChallenge = "git-cc draft encryption v1"
+
+// sealedDraft holds the answers of a draft encrypted with AES-GCM
+type sealedDraft struct {
+	// Key is the fingerprint of the agent key the draft key is derived from
+	Key   string `json:"key"`
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// agentSigners returns the keys of the running ssh-agent which sign
+// deterministically, and a function closing the connection to the agent
+func agentSigners() ([]ssh.Signer, func() error, error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, nil, errors.New("no ssh-agent running (SSH_AUTH_SOCK is not set)")
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to ssh-agent: %w", err)
+	}
+
+	signers, err := agent.NewClient(conn).Signers()
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	var usable []ssh.Signer
+	for _, signer := range signers {
+		switch signer.PublicKey().Type() {
+		case ssh.KeyAlgoED25519, ssh.KeyAlgoRSA:
+			usable = append(usable, signer)
+		}
+	}
+	if len(usable) == 0 {
+		conn.Close()
+		return nil, nil, errors.New("ssh-agent holds no ed25519 or RSA key")
+	}
+	return usable, conn.Close, nil
+}
+
+// draftCipher derives the AES key for salt from the signature of signer
+func draftCipher(signer ssh.Signer, salt []byte) (cipher.AEAD, error) {
+	signature, err := signer.Sign(rand.Reader, []byte(draftChallenge))
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, signature.Blob, salt, []byte("git-cc draft")), key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealDraft encrypts plain with the first usable key of the ssh-agent
+func sealDraft(plain []byte) (*sealedDraft, error) {
+	signers, closeAgent, err := agentSigners()
+	if err != nil {
+		return nil, err
+	}
+	defer closeAgent()
+
+	sealed := &sealedDraft{Key: ssh.FingerprintSHA256(signers[0].PublicKey()), Salt: make([]byte, 16)}
+	if _, err := rand.Read(sealed.Salt); err != nil {
+		return nil, err
+	}
+	aead, err := draftCipher(signers[0], sealed.Salt)
+	if err != nil {
+		return nil, err
+	}
+	sealed.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(sealed.Nonce); err != nil {
+		return nil, err
+	}
+	sealed.Data = aead.Seal(nil, sealed.Nonce, plain, []byte(sealed.Key))
+	return sealed, nil
+}
+
+// open decrypts the draft with its key from the ssh-agent
+func (s *sealedDraft) open() ([]byte, error) {
+	signers, closeAgent, err := agentSigners()
+	if err != nil {
+		return nil, err
+	}
+	defer closeAgent()
+
+	for _, signer := range signers {
+		if ssh.FingerprintSHA256(signer.PublicKey()) != s.Key {
+			continue
+		}
+		aead, err := draftCipher(signer, s.Salt)
+		if err != nil {
+			return nil, err
+		}
+		return aead.Open(nil, s.Nonce, s.Data, []byte(s.Key))
+	}
+	return nil, fmt.Errorf("key %s is not in the ssh-agent", s.Key)
+}
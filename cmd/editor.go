@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+)
+
+// editorFile is the message file git passed to git-cc as its editor
+var editorFile string
+
+// commitMessageFiles are the files git has its editor write commit messages
+// in: commits and rebase --continue, merges and squash merges
+var commitMessageFiles = []string{"COMMIT_EDITMSG", "MERGE_MSG", "SQUASH_MSG"}
+
+// isGitEditorFile reports whether path is a file git passes to its editor,
+// i.e. an existing file in a git directory
+func isGitEditorFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(filepath.ToSlash(abs), "/.git/")
+}
+
+// runAsEditor writes the commit message to file when git-cc is git's editor.
+// Files other than commit messages, such as the rebase todo list, are
+// opened in the editor git-cc stands in for.
+func runAsEditor(file string) {
+	if !slices.Contains(commitMessageFiles, filepath.Base(file)) {
+		if err := editFile(file); err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Failure)
+		}
+		return
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+	existing := stripComments(string(content))
+	// the others of the messages squashed together are kept in the body
+	var squashed []string
+	step, rebasing := currentRebaseStep()
+	if rebasing {
+		pterm.Info.Println("Interactive rebase:", step)
+		if messages := squashedMessages(string(content)); messages != nil {
+			existing, squashed = messages[0], messages[1:]
+		}
+	}
+	header, body, _ := strings.Cut(existing, "\n")
+
+	switch {
+	case existing == "":
+	case ignoredMessage(existing):
+		// merges, reverts and fixups are exempt from the conventions
+		if keep, _ := ui.Confirm(fmt.Sprintf("Keep the message prepared by git (%s)", header), true); keep {
+			return
+		}
+	default:
+		// e.g. rebase --continue, commit -c or a reword
+		if data, err := parseCommitMessage(existing); err == nil {
+			promptDefaults = data
+		} else {
+			promptDefaults.ShortDescription = header
+			promptDefaults.LongDescription = strings.TrimSpace(body)
+		}
+		for _, message := range squashed {
+			header, body, _ := strings.Cut(message, "\n")
+			body, trailers := splitTrailers(body)
+			promptDefaults.LongDescription = strings.TrimSpace(promptDefaults.LongDescription + "\n\n" + strings.TrimSpace(header+"\n\n"+body))
+			for _, t := range trailers {
+				promptDefaults.Footers = setTrailer(promptDefaults.Footers, t, false)
+			}
+		}
+	}
+
+	message, err := promptForCommit(commitTypes)
+	if err != nil {
+		// git aborts the commit when its editor fails
+		pterm.Error.Println(err)
+		if rebasing {
+			pterm.Info.Println("The rebase stopped, run git rebase --continue to write the message again or git rebase --abort")
+		}
+		exit(exitcode.Code(err))
+	}
+	if comments := gitComments(string(content)); comments != "" {
+		message += "\n\n" + comments
+	}
+	if err := os.WriteFile(file, []byte(message+"\n"), 0o644); err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+	removeDraft()
+}
+
+// gitComments returns git's comment lines of a message file together with
+// the scissors line and everything below it
+func gitComments(content string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	comment := commentChar(lines)
+	var comments []string
+	for i, line := range lines {
+		if line == comment+scissors {
+			return strings.Join(append(comments, lines[i:]...), "\n")
+		}
+		if strings.HasPrefix(line, comment) {
+			comments = append(comments, line)
+		}
+	}
+	return strings.Join(comments, "\n")
+}
+
+// editBody composes the long description in the editor, starting from body
+// with the header rendered so far below it as a comment. The multi-line
+// prompt is asked instead when the editor fails.
+func editBody(data CommitPromptData, body string) string {
+	header, _ := renderCommitMessage(data)
+	header, _, _ = strings.Cut(header, "\n")
+	comment := commentChar(nil)
+	content := fmt.Sprintf("%s\n\n%s Long description of the commit, paragraphs and lists are kept.\n%s Lines starting with %s are ignored, an empty description leaves it out.\n%s\n%s %s\n",
+		body, comment, comment, comment, comment, comment, header)
+
+	file, err := writeTempFile("COMMIT_BODY", content)
+	if err == nil {
+		defer removeMessageFile(file)
+		err = editFile(file)
+	}
+	if err == nil {
+		var edited []byte
+		if edited, err = os.ReadFile(file); err == nil {
+			return stripComments(string(edited))
+		}
+	}
+	pterm.Warning.Println("Failed to edit the long description:", err)
+	answer, _ := ui.MultilineInput(promptLabel("Long Description (optional)"), body)
+	return answer
+}
+
+// messageEditor returns the editor to open message files in, picked like
+// git does from GIT_EDITOR, core.editor, VISUAL, EDITOR and vi, skipping
+// git-cc itself
+func messageEditor() string {
+	candidates := []string{os.Getenv("GIT_EDITOR"), gitConfigOption("core", "editor"), os.Getenv("VISUAL"), os.Getenv("EDITOR")}
+	for _, candidate := range candidates {
+		if candidate != "" && !invokesGitCC(candidate) {
+			return candidate
+		}
+	}
+	return "vi"
+}
+
+// invokesGitCC reports whether an editor command runs git-cc, by name or
+// because it is the running executable
+func invokesGitCC(editor string) bool {
+	if strings.Contains(editor, "git-cc") || strings.Contains(editor, "git cc") {
+		return true
+	}
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		return false
+	}
+	path, err := exec.LookPath(fields[0])
+	if err != nil {
+		return false
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	path, _ = filepath.EvalSymlinks(path)
+	self, _ = filepath.EvalSymlinks(self)
+	return path == self
+}
+
+// runEditor opens file in editor, which may come with arguments
+func runEditor(editor, file string) error {
+	// let the shell split the arguments as git does
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, "editor", file)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+	return nil
+}
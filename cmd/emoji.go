@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// defaultEmojis maps the common types to their gitmoji, emojis configures more
+var defaultEmojis = map[string]string{
+	"build":    "📦",
+	"chore":    "🔧",
+	"ci":       "👷",
+	"docs":     "📝",
+	"feat":     "✨",
+	"fix":      "🐛",
+	"perf":     "⚡",
+	"refactor": "♻️",
+	"revert":   "⏪",
+	"style":    "🎨",
+	"test":     "✅",
+}
+
+// typeEmoji returns the emoji of commitType if emoji are enabled
+func typeEmoji(commitType string) string {
+	if !viper.GetBool("emoji") {
+		return ""
+	}
+	if emoji, ok := viper.GetStringMapString("emojis")[strings.ToLower(commitType)]; ok {
+		return emoji
+	}
+	return defaultEmojis[commitType]
+}
+
+// withEmoji renders header, built from commitType, with the emoji of the type
+// in front of the description or, with emoji_position type, of the type
+func withEmoji(commitType, header string) string {
+	emoji := typeEmoji(commitType)
+	if emoji == "" {
+		return header
+	}
+
+	if viper.GetString("emoji_position") == "type" {
+		if strings.HasPrefix(header, emoji+" ") {
+			return header
+		}
+		return emoji + " " + header
+	}
+
+	prefix, description, _ := strings.Cut(header, ": ")
+	if strings.TrimSpace(description) == "" || strings.HasPrefix(description, emoji+" ") {
+		return header
+	}
+	return prefix + ": " + emoji + " " + description
+}
+
+// typeOptions returns the types as shown in the type select, with their emoji
+// when enabled, and a lookup from option back to type
+func typeOptions(types []string) ([]string, map[string]string) {
+	options := make([]string, len(types))
+	lookup := map[string]string{}
+	for i, t := range types {
+		options[i] = t
+		if emoji := typeEmoji(t); emoji != "" {
+			options[i] = emoji + " " + t
+		}
+		lookup[options[i]] = t
+	}
+	return options, lookup
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/conventional"
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var examplesCmd = &cobra.Command{
+	Use:   "examples",
+	Short: "Print example commit messages which pass this repository's rules",
+	Long: `Print example commit messages built from the configured types, scopes,
+footers and rules of this repository: a header for every type and complete
+messages with a body, a breaking change and footers, each validated as git cc
+lint would. Messages breaking the rules follow, with the problems lint
+reports for them.
+
+Handy for onboarding and to point to from a failing commit-msg hook or CI
+check.`,
+	Example: `  git cc examples
+  git cc examples --invalid=false`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         examples,
+}
+
+func init() {
+	examplesCmd.Flags().Bool("invalid", true, "Print messages breaking the rules as well")
+	rootCmd.AddCommand(examplesCmd)
+}
+
+// exampleDescriptions are the descriptions of the example messages by type,
+// other types get a generic one
+var exampleDescriptions = map[string]string{
+	"feat":     "add export of reports as CSV",
+	"fix":      "handle empty responses of the API",
+	"docs":     "explain the release process",
+	"style":    "format the imports",
+	"refactor": "extract the retry logic",
+	"perf":     "cache the parsed config",
+	"test":     "cover the error paths of the parser",
+	"build":    "update the Go toolchain",
+	"ci":       "run the tests on Windows too",
+	"chore":    "update the dependencies",
+	"security": "escape user input in the search",
+}
+
+func examples(cmd *cobra.Command, args []string) {
+	types := slices.DeleteFunc(slices.Clone(commitTypes), func(t string) bool { return t == "revert" })
+	if len(types) == 0 {
+		pterm.Error.Println("No commit types are configured")
+		exit(exitcode.Failure)
+	}
+
+	pterm.Println(pterm.Bold.Sprint("Headers"))
+	for i, t := range types {
+		data := exampleData(t, exampleScope(i))
+		if message, ok := validExample(data); ok {
+			header, _, _ := strings.Cut(message, "\n")
+			pterm.Println("    " + header)
+		}
+	}
+
+	main := types[0]
+	if slices.Contains(types, "feat") {
+		main = "feat"
+	}
+	fix := main
+	if slices.Contains(types, "fix") {
+		fix = "fix"
+	}
+	footer := "Refs"
+	if keys := viper.GetStringSlice("footer_keys"); len(keys) > 0 {
+		footer = keys[0]
+	}
+
+	withBody := exampleData(fix, exampleScope(0))
+	withBody.LongDescription = "Some endpoints answer 204 without a body, which failed to parse\nas JSON and was reported as an outage."
+	withBody.Footers = append(withBody.Footers, trailer{Token: footer, Separator: ": ", Value: "PROJ-123"})
+	breaking := exampleData(main, exampleScope(1))
+	breaking.ShortDescription = conventional.FixDescription("drop the v1 endpoints", commitRules())
+	breaking.BreakingChange = true
+	breaking.BreakingChangeNote = "the v1 endpoints are gone, use the v2 ones instead"
+
+	for _, data := range []CommitPromptData{withBody, breaking} {
+		if message, ok := validExample(data); ok {
+			pterm.Println("\n" + pterm.Bold.Sprint("Message"))
+			pterm.Println(indentText(message, "    "))
+		}
+	}
+
+	if invalid, _ := cmd.Flags().GetBool("invalid"); invalid {
+		printInvalidExamples(main)
+	}
+}
+
+// exampleScope returns the nth scope to use in examples, one of the
+// configured scopes or else of the history. Without scopes there is none,
+// unless scopes are required.
+func exampleScope(n int) string {
+	options := slices.DeleteFunc(slices.Clone(scopes), func(s string) bool { return s == "none" })
+	if len(options) == 0 {
+		options = historyScopes()
+	}
+	if len(options) == 0 {
+		return ""
+	}
+	// optional scopes are left out of every other example
+	if slices.Contains(scopes, "none") && n%2 == 1 {
+		return ""
+	}
+	return options[n%len(options)]
+}
+
+// exampleData returns the answers of an example of commitType, with the
+// footers and body the conditional rules require for it
+func exampleData(commitType, scope string) CommitPromptData {
+	description, ok := exampleDescriptions[commitType]
+	if !ok {
+		description = "update the project setup"
+	}
+	data := CommitPromptData{
+		Type:             commitType,
+		Scope:            scope,
+		ShortDescription: conventional.FixDescription(description, commitRules()),
+	}
+	for _, c := range commitConditions() {
+		if !c.Applies(commitType, scope) {
+			continue
+		}
+		if c.RequireBody {
+			data.LongDescription = "Explain what changed and why."
+		}
+		for _, token := range c.RequireFooters {
+			data.Footers = append(data.Footers, trailer{Token: token, Separator: ": ", Value: "PROJ-123"})
+		}
+	}
+	return data
+}
+
+// validExample renders data as the prompts would and reports whether the
+// message passes the rules, examples which can't are left out
+func validExample(data CommitPromptData) (string, bool) {
+	message, err := renderCommitMessage(data)
+	if err != nil {
+		pterm.Debug.Println("Skipping example:", err)
+		return "", false
+	}
+	if problems := validateCommitMessage(message); len(problems) > 0 {
+		pterm.Debug.Printfln("Skipping example %q: %s", message, problems[0].Message)
+		return "", false
+	}
+	return message, true
+}
+
+// printInvalidExamples prints variants of a valid message of commitType
+// breaking one rule each, with the problems lint explains them with
+func printInvalidExamples(commitType string) {
+	base := exampleData(commitType, exampleScope(0))
+	rules := commitRules()
+	var candidates []string
+	variant := func(change func(data *CommitPromptData)) {
+		data := base
+		change(&data)
+		candidates = append(candidates, buildCommitMessage(data))
+	}
+
+	_, rest, _ := strings.Cut(buildCommitMessage(base), "\n")
+	candidates = append(candidates, strings.TrimRight("Added export of reports as CSV\n"+rest, "\n"))
+	variant(func(data *CommitPromptData) { data.Type = "feature" })
+	if len(rules.Scopes) > 0 {
+		variant(func(data *CommitPromptData) { data.Scope = "misc" })
+		variant(func(data *CommitPromptData) { data.Scope = "" })
+	}
+	description := base.ShortDescription
+	first, tail := description[:1], description[1:]
+	if rules.SubjectCase == conventional.SentenceCase {
+		variant(func(data *CommitPromptData) { data.ShortDescription = strings.ToLower(first) + tail })
+	} else if rules.SubjectCase == conventional.LowerCase {
+		variant(func(data *CommitPromptData) { data.ShortDescription = strings.ToUpper(first) + tail })
+	}
+	variant(func(data *CommitPromptData) { data.ShortDescription = description + "." })
+	variant(func(data *CommitPromptData) {
+		data.ShortDescription = conventional.FixDescription("added export of reports as CSV", conventional.Rules{SubjectCase: rules.SubjectCase})
+	})
+	if limit := rules.MaxHeaderLength; limit > 0 {
+		variant(func(data *CommitPromptData) {
+			data.ShortDescription = description + strings.Repeat(" and more", limit/9+1)
+		})
+	}
+	for _, c := range rules.Conditions {
+		if c.RequireBody && len(c.Types) > 0 {
+			variant(func(data *CommitPromptData) {
+				data.Type, data.LongDescription = c.Types[0], ""
+			})
+			break
+		}
+	}
+
+	pterm.Println("\n" + pterm.Bold.Sprint("Invalid"))
+	shown := map[string]bool{}
+	for _, message := range candidates {
+		problems := validateCommitMessage(message)
+		// one example per rule broken
+		if len(problems) == 0 || shown[problems[0].Rule] {
+			continue
+		}
+		if len(shown) > 0 {
+			pterm.Println()
+		}
+		shown[problems[0].Rule] = true
+		writeProblems(os.Stdout, message, problems)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+)
+
+var (
+	cleanupMu sync.Mutex
+	cleanups  []*func()
+	// exitSignal is the signal git-cc was stopped with, passed on to the
+	// running git commit
+	exitSignal = syscall.SIGTERM
+)
+
+// atExit registers fn to run when the process exits through exit, a signal
+// or a panic. The returned function runs fn right away and unregisters it,
+// for the usual defer.
+func atExit(fn func()) func() {
+	cleanupMu.Lock()
+	defer cleanupMu.Unlock()
+	registered := &fn
+	cleanups = append(cleanups, registered)
+
+	return func() {
+		cleanupMu.Lock()
+		for i, c := range cleanups {
+			if c == registered {
+				cleanups = append(cleanups[:i], cleanups[i+1:]...)
+				break
+			}
+		}
+		cleanupMu.Unlock()
+		fn()
+	}
+}
+
+// runCleanups runs the registered cleanups, latest first
+func runCleanups() {
+	cleanupMu.Lock()
+	pending := cleanups
+	cleanups = nil
+	cleanupMu.Unlock()
+
+	for i := len(pending) - 1; i >= 0; i-- {
+		(*pending[i])()
+	}
+	// interactive prompts hide the cursor while they run
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Print("\033[?25h")
+	}
+}
+
+// exit ends the process with code after cleaning up, use it instead of
+// os.Exit which skips deferred cleanups
+func exit(code int) {
+	runCleanups()
+	if quiet && code != exitcode.OK {
+		printQuietError(code)
+	}
+	os.Exit(gitExitCode(code))
+}
+
+// exitOnSignal cleans up and exits with the shell's 128+n convention when
+// the process is interrupted or terminated
+func exitOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		s := <-signals
+		code := exitcode.Interrupted
+		if n, ok := s.(syscall.Signal); ok {
+			code = 128 + int(n)
+			exitSignal = n
+		}
+		exit(code)
+	}()
+}
+
+// exitOnPanic cleans up before a panic ends the process, deferred in Execute
+func exitOnPanic() {
+	if r := recover(); r != nil {
+		runCleanups()
+		pterm.Error.Println("internal error:", r)
+		panic(r)
+	}
+}
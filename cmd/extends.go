@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// githubRepoPattern matches the org/repo shorthand of a shared config
+var githubRepoPattern = regexp.MustCompile(`^[\w.-]+/[\w.-]+$`)
+
+// extendsURL returns the URL of the shared config referenced by extends,
+// either an https URL or org/repo for the .git-cc.yaml of a GitHub repository
+func extendsURL(extends string) (string, error) {
+	if githubRepoPattern.MatchString(extends) {
+		return "https://raw.githubusercontent.com/" + extends + "/HEAD/.git-cc.yaml", nil
+	}
+
+	u, err := url.Parse(extends)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("extends must be an https URL or org/repo, not %q", extends)
+	}
+	return extends, nil
+}
+
+// mergeSharedConfig merges the shared config referenced by extends into the
+// config. It is fetched like hosted API responses, so it is cached for
+// api_cache_ttl and a stale copy is used while the host is unreachable.
+func mergeSharedConfig(extends string) error {
+	source, err := extendsURL(extends)
+	if err != nil {
+		return err
+	}
+
+	// public configs are fetched without credentials, private ones are
+	// retried with the token of the host
+	body, err := apiGet(source, "")
+	if err != nil {
+		u, _ := url.Parse(source)
+		host := u.Host
+		if host == "raw.githubusercontent.com" {
+			host = "github.com"
+		}
+		token, tokenErr := hostToken(host)
+		if tokenErr != nil {
+			return err
+		}
+		if body, err = apiGet(source, token); err != nil {
+			return err
+		}
+	}
+
+	format := strings.TrimPrefix(path.Ext(strings.SplitN(source, "?", 2)[0]), ".")
+	if format == "" {
+		format = "yaml"
+	}
+	viper.SetConfigType(format)
+	// files merged later take their format from their extension again
+	defer viper.SetConfigType("")
+
+	if err := viper.MergeConfig(bytes.NewReader(body)); err != nil {
+		return fmt.Errorf("reading shared config %s: %w", source, err)
+	}
+	pterm.Debug.Println("Merged shared config", source)
+	return nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// keep the temporary commit message file for debugging, set by
+// --keep-message-file
+var keepMessageFile bool
+
+// privateDir returns git-cc's directory inside the git dir, which holds
+// drafts and temporary commit messages out of reach of other users
+func privateDir() (string, error) {
+	dir := filepath.Join(gitDir(), "git-cc")
+	return dir, os.MkdirAll(dir, 0o700)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so readers never see a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	// clean up unless renamed
+	defer atExit(func() { os.Remove(f.Name()) })()
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// tempFileDir returns the directory of temporary files: the private dir in
+// the git dir by default, the system's temp directory for temp_file_dir
+// system or else temp_file_dir itself, relative to the repository root
+func tempFileDir() (string, error) {
+	switch dir := viper.GetString("temp_file_dir"); dir {
+	case "", "git":
+		return privateDir()
+	case "system":
+		return os.TempDir(), nil
+	default:
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(gitRoot, dir)
+		}
+		return dir, os.MkdirAll(dir, 0o700)
+	}
+}
+
+// writeMessageFile writes a commit message into a temporary file for git
+// commit -F, with LF line endings as git expects them on every platform
+func writeMessageFile(message string) (string, error) {
+	file, err := writeTempFile("commitMessage", strings.ReplaceAll(message, "\r\n", "\n"))
+	if err != nil {
+		return "", err
+	}
+	// git runs without a shell, a path with spaces stays one argument
+	return filepath.Abs(file)
+}
+
+// normalizeLineEndings rewrites file with LF line endings if it has CRLF ones
+func normalizeLineEndings(file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil || !strings.Contains(string(content), "\r\n") {
+		return err
+	}
+	return os.WriteFile(file, []byte(strings.ReplaceAll(string(content), "\r\n", "\n")), 0o600)
+}
+
+// removeMessageFile removes a temporary message file unless
+// --keep-message-file is given. Windows refuses to remove a file another
+// process such as git or the editor still holds open, which is retried.
+func removeMessageFile(file string) {
+	if keepMessageFile {
+		pterm.Info.Println("Kept the commit message file", file)
+		return
+	}
+	var err error
+	for attempt := 0; attempt < 10; attempt++ {
+		err = os.Remove(file)
+		if err == nil || errors.Is(err, fs.ErrNotExist) || runtime.GOOS != "windows" {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		pterm.Debug.Println("Failed to remove the temporary file:", err)
+	}
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var fixupCmd = &cobra.Command{
+	Use:   "fixup [<commit>]",
+	Short: "Commit the staged changes as a fixup of a recent commit",
+	Long: `Commit the staged changes with git commit --fixup, picking the commit they
+fix from the recent history grouped by type and scope. With --rebase the
+fixup is squashed into it right away by an autosquash rebase.`,
+	Example: `  git cc fixup
+  git cc fixup --rebase
+  git cc fixup HEAD~2`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  fixup,
+}
+
+func init() {
+	fixupCmd.Flags().Bool("rebase", false, "Squash the fixup into its commit with git rebase --autosquash")
+	fixupCmd.Flags().Int("count", 20, "Number of recent commits to pick from")
+	fixupCmd.Flags().BoolVarP(&stageAll, "all", "a", false, "Stage all changes to tracked files before committing")
+	rootCmd.AddCommand(fixupCmd)
+}
+
+// fixupTarget is a commit offered to be fixed up
+type fixupTarget struct {
+	Hash    string
+	Subject string
+	// Group is type(scope) of the commit, or other
+	Group       string
+	Description string
+}
+
+func fixup(cmd *cobra.Command, args []string) {
+	var hash string
+	if len(args) == 1 {
+		out, err := gitOutput("rev-parse", "--verify", "--quiet", args[0]+"^{commit}")
+		if err != nil {
+			pterm.Error.Printfln("%s is not a commit", args[0])
+			exit(exitcode.Failure)
+		}
+		hash = strings.TrimSpace(out)
+	}
+
+	checkStagedChanges()
+
+	if hash == "" {
+		count, _ := cmd.Flags().GetInt("count")
+		targets, err := recentFixupTargets(max(count, 1))
+		if err != nil {
+			pterm.Error.Println("Failed to read history:", err)
+			exit(exitcode.Failure)
+		}
+		if len(targets) == 0 {
+			pterm.Error.Println("No commits to fix up")
+			exit(exitcode.Failure)
+		}
+		hash = pickFixupTarget(targets)
+	}
+
+	commit := exec.Command("git", append([]string{"commit", "--fixup=" + hash}, signingArgs()...)...)
+	commit.Dir = gitRoot
+	commit.Stdout = os.Stdout
+	commit.Stderr = os.Stderr
+	passTerminal(commit)
+	if err := commit.Run(); err != nil {
+		commitFailed(err)
+	}
+
+	if rebase, _ := cmd.Flags().GetBool("rebase"); rebase {
+		autosquash(hash)
+	}
+}
+
+// recentFixupTargets returns the last count commits which aren't merges or
+// fixups themselves
+func recentFixupTargets(count int) ([]fixupTarget, error) {
+	out, err := gitOutput("log", "--no-merges", "--format=%H%x00%s", "--max-count="+strconv.Itoa(count))
+	if err != nil {
+		return nil, err
+	}
+	var targets []fixupTarget
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		hash, subject, ok := strings.Cut(line, "\x00")
+		if !ok || strings.HasPrefix(subject, "fixup! ") || strings.HasPrefix(subject, "squash! ") || strings.HasPrefix(subject, "amend! ") {
+			continue
+		}
+		target := fixupTarget{Hash: hash, Subject: subject, Group: "other", Description: subject}
+		if match := headerPattern.FindStringSubmatch(subject); match != nil {
+			target.Group = match[1]
+			if match[2] != "" {
+				target.Group += "(" + match[2] + ")"
+			}
+			target.Description = match[4]
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// pickFixupTarget lets the user select one of targets, grouped by type and
+// scope in the order the groups were last committed to
+func pickFixupTarget(targets []fixupTarget) string {
+	var groups []string
+	byGroup := map[string][]fixupTarget{}
+	for _, t := range targets {
+		if _, ok := byGroup[t.Group]; !ok {
+			groups = append(groups, t.Group)
+		}
+		byGroup[t.Group] = append(byGroup[t.Group], t)
+	}
+
+	var options []string
+	hashes := map[string]string{}
+	for _, group := range groups {
+		for _, t := range byGroup[group] {
+			option := fmt.Sprintf("[%s] %s (%s)", group, t.Description, t.Hash[:7])
+			options = append(options, option)
+			hashes[option] = t.Hash
+		}
+	}
+	selected, err := ui.Select("Fix up", options, options[0])
+	if err != nil || hashes[selected] == "" {
+		pterm.Info.Println("Commit aborted")
+		exit(exitcode.Aborted)
+	}
+	return hashes[selected]
+}
+
+// autosquash squashes the fixups of the history since hash into their
+// commits without opening the todo list
+func autosquash(hash string) {
+	args := []string{"rebase", "--interactive", "--autosquash", "--autostash"}
+	if _, err := gitOutput("rev-parse", "--verify", "--quiet", hash+"^"); err != nil {
+		// the root commit is fixed up
+		args = append(args, "--root")
+	} else {
+		args = append(args, hash+"^")
+	}
+
+	rebase := exec.Command("git", args...)
+	rebase.Dir = gitRoot
+	rebase.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=true")
+	rebase.Stdout = os.Stdout
+	rebase.Stderr = os.Stderr
+	if err := rebase.Run(); err != nil {
+		pterm.Error.Println("The rebase stopped, resolve the conflicts and run git rebase --continue, or git rebase --abort:", err)
+		exit(exitcode.Failure)
+	}
+	pterm.Success.Println("Squashed the fixup into its commit")
+}
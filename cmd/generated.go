@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// generatedCache holds the files already checked by isGenerated
+var generatedCache = map[string]bool{}
+
+// generatedFiles returns which of paths are generated: marked with the
+// linguist-generated attribute in .gitattributes or matching
+// generated_paths. Generated files don't count for the suggestions and are
+// collapsed in previews, so large generated diffs don't dominate them.
+func generatedFiles(paths []string) map[string]bool {
+	var unchecked []string
+	for _, p := range paths {
+		if _, ok := generatedCache[p]; !ok {
+			unchecked = append(unchecked, p)
+		}
+	}
+	if len(unchecked) > 0 {
+		for p, generated := range generatedAttributes(unchecked) {
+			generatedCache[p] = generated
+		}
+		for _, p := range unchecked {
+			generatedCache[p] = generatedCache[p] || matchesGeneratedPaths(p)
+		}
+	}
+
+	generated := map[string]bool{}
+	for _, p := range paths {
+		if generatedCache[p] {
+			generated[p] = true
+		}
+	}
+	return generated
+}
+
+// generatedAttributes reads the linguist-generated attribute of paths
+func generatedAttributes(paths []string) map[string]bool {
+	cmd := exec.Command("git", "check-attr", "-z", "--stdin", "linguist-generated")
+	cmd.Dir = gitRoot
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+	out, err := cmd.Output()
+	if err != nil {
+		pterm.Debug.Println("Failed to read attributes:", err)
+		return nil
+	}
+
+	// path, attribute and value, each terminated by NUL
+	generated := map[string]bool{}
+	fields := bytes.Split(out, []byte{0})
+	for i := 0; i+2 < len(fields); i += 3 {
+		value := string(fields[i+2])
+		generated[string(fields[i])] = value == "set" || value == "true"
+	}
+	return generated
+}
+
+// matchesGeneratedPaths matches file against the generated_paths patterns
+func matchesGeneratedPaths(file string) bool {
+	return matchesPathPatterns(file, viper.GetStringSlice("generated_paths"))
+}
+
+// matchesPathPatterns matches file against patterns: patterns without a
+// slash match the file name, patterns ending with a slash a directory and
+// its contents, others the whole path
+func matchesPathPatterns(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(pattern, "/")
+		switch {
+		case strings.HasSuffix(pattern, "/"):
+			if strings.HasPrefix(file, pattern) {
+				return true
+			}
+		case !strings.Contains(pattern, "/"):
+			if ok, _ := path.Match(pattern, path.Base(file)); ok {
+				return true
+			}
+		default:
+			if ok, _ := path.Match(pattern, file); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withoutGenerated returns the files of paths which aren't generated, or
+// all of them when every one is
+func withoutGenerated(paths []string) []string {
+	generated := generatedFiles(paths)
+	if len(generated) == len(paths) {
+		return paths
+	}
+	kept := make([]string, 0, len(paths)-len(generated))
+	for _, p := range paths {
+		if !generated[p] {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// excludeGeneratedStaged returns pathspecs leaving the generated staged files
+// out of a git diff --cached, unless every staged file is generated
+func excludeGeneratedStaged() []string {
+	out, err := gitOutput("diff", "--cached", "--name-only", "--no-renames", "-z")
+	if err != nil || out == "" {
+		return nil
+	}
+	files := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	generated := generatedFiles(files)
+	if len(generated) == 0 || len(generated) == len(files) {
+		return nil
+	}
+	pathspecs := []string{"--", "."}
+	for _, f := range files {
+		if generated[f] {
+			pathspecs = append(pathspecs, ":(exclude,literal)"+f)
+		}
+	}
+	return pathspecs
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/pterm/pterm"
+)
+
+// openRepository opens the repository containing the working directory
+func openRepository() error {
+	var err error
+	repo, err = openGitRepo()
+	if err != nil {
+		return err
+	}
+
+	worktree, err = repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("error opening Git repository: %w", err)
+	}
+
+	gitRoot = worktree.Filesystem.Root()
+	pterm.Debug.Println("Root directory of Git repository:", gitRoot)
+	return nil
+}
+
+// stagedChanges reports whether anything is staged and, when nothing is,
+// whether untracked files are present. git answers that in a fraction of
+// the time go-git's Worktree.Status takes in large repositories, which is
+// only used without a git binary.
+func stagedChanges() (hasStagedChanges bool, hasUntracked bool, err error) {
+	defer startSpinner("Checking the staged changes")()
+	if commitWithoutGit() {
+		return worktreeStagedChanges()
+	}
+
+	// only compares the index with HEAD, without looking at the files
+	diff := exec.Command("git", "diff", "--cached", "--quiet")
+	diff.Dir = gitRoot
+	var exitErr *exec.ExitError
+	if err := diff.Run(); errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, false, nil
+	} else if err != nil {
+		return false, false, err
+	}
+
+	out, err := gitOutput("status", "--porcelain=v2", "-z", "--untracked-files=normal")
+	if err != nil {
+		return false, false, err
+	}
+	for _, entry := range strings.Split(out, "\x00") {
+		if strings.HasPrefix(entry, "? ") {
+			return false, true, nil
+		}
+	}
+	return false, false, nil
+}
+
+// worktreeStagedChanges is stagedChanges through go-git
+func worktreeStagedChanges() (hasStagedChanges bool, hasUntracked bool, err error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return false, false, err
+	}
+
+	// Check if there are staged changes
+	for _, entry := range status {
+		if entry.Staging != git.Untracked && entry.Staging != git.Unmodified {
+			hasStagedChanges = true
+			break
+		} else if entry.Staging == git.Untracked {
+			hasUntracked = true
+		}
+	}
+
+	return hasStagedChanges, hasUntracked, nil
+}
+
+// startSpinner shows a spinner with text on stderr when a check takes long
+// enough to notice, until the returned function is called. The plain
+// prompts and output which isn't a terminal get none.
+func startSpinner(text string) func() {
+	if _, plain := ui.(*plainUI); plain || !interactiveTerminal() {
+		return func() {}
+	}
+	var (
+		mu      sync.Mutex
+		spinner *pterm.SpinnerPrinter
+		stopped bool
+	)
+	timer := time.AfterFunc(200*time.Millisecond, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if !stopped {
+			spinner, _ = pterm.DefaultSpinner.WithWriter(os.Stderr).WithRemoveWhenDone().Start(text)
+		}
+	})
+	return func() {
+		timer.Stop()
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = true
+		if spinner != nil {
+			spinner.Stop()
+		}
+	}
+}
+
+func openGitRepo() (*git.Repository, error) {
+	// Validate the current directory is a git repository
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("error getting current working directory: %w", err)
+	}
+
+	// Open the Git repository at the current working directory
+	repo, err := git.PlainOpenWithOptions(cwd, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("not a git repository (or any of the parent directories): .git")
+	}
+
+	return repo, nil
+}
+
+// gitDir returns the path of the repository's .git directory
+func gitDir() string {
+	if storage, ok := repo.Storer.(*filesystem.Storage); ok {
+		return storage.Filesystem().Root()
+	}
+	return filepath.Join(gitRoot, ".git")
+}
+
+// gitOutput runs git in the repository root and returns its standard output
+func gitOutput(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = gitRoot
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// gitQuiet runs git in the repository root and returns its trimmed standard
+// output, discarding the errors it prints
+func gitQuiet(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = gitRoot
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// exit codes git uses for fatal errors and wrong usage
+const (
+	gitFatalExit = 128
+	gitUsageExit = 129
+)
+
+// usageError is an error in the command line, as opposed to one running it
+type usageError struct{ error }
+
+func (e usageError) Unwrap() error { return e.error }
+
+// flagError marks the errors cobra returns for unknown or invalid flags
+func flagError(cmd *cobra.Command, err error) error {
+	return usageError{err}
+}
+
+// gitExitCodes reports whether git-cc mirrors the exit codes and messages of
+// git commit, so scripts wrapping git commit can call git-cc instead
+func gitExitCodes() bool {
+	return viper.GetBool("git_exit_codes")
+}
+
+// gitMessageWriter rewrites the messages of a pterm prefix printer into git's
+// plain "error: message" format on stderr
+type gitMessageWriter struct {
+	label string
+}
+
+func (w gitMessageWriter) Write(p []byte) (int, error) {
+	var lines []string
+	for i, line := range strings.Split(strings.TrimRight(pterm.RemoveColorFromString(string(p)), "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if i == 0 {
+			line = w.label + ": " + line
+		}
+		lines = append(lines, line)
+	}
+	if _, err := fmt.Fprintln(os.Stderr, strings.Join(lines, "\n")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// useGitMessages prints errors and warnings the way git does
+func useGitMessages() {
+	pterm.Error = *pterm.Error.WithPrefix(pterm.Prefix{}).WithWriter(gitMessageWriter{label: "error"})
+	pterm.Warning = *pterm.Warning.WithPrefix(pterm.Prefix{}).WithWriter(gitMessageWriter{label: "warning"})
+}
+
+// gitExitCode maps the exit codes of git-cc onto the ones of git commit,
+// which exits with 1 both when nothing is staged and when committing fails,
+// and with 128 for a broken config
+func gitExitCode(code int) int {
+	if !gitExitCodes() {
+		return code
+	}
+	switch code {
+	case exitcode.NothingStaged, exitcode.CommitFailed, exitcode.Aborted, exitcode.Invalid:
+		return 1
+	case exitcode.Config:
+		return gitFatalExit
+	}
+	return code
+}
+
+// exitFatal reports an error which kept the command from running and exits
+func exitFatal(err error) {
+	if !gitExitCodes() || quiet {
+		pterm.Error.Println(err)
+		exit(exitcode.Code(err))
+	}
+	if errors.As(err, new(usageError)) {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		exit(gitUsageExit)
+	}
+	fmt.Fprintln(os.Stderr, "fatal:", err)
+	exit(gitFatalExit)
+}
+
+// commitFailed reports that the commit wasn't created and exits, with the
+// code of err if it has one. git has already explained why it failed, so
+// its exit code is passed on as is when mirroring git commit.
+func commitFailed(err error) {
+	var exitErr *exec.ExitError
+	if gitExitCodes() && errors.As(err, &exitErr) {
+		exit(exitErr.ExitCode())
+	}
+	pterm.Error.Println(err)
+	if code := exitcode.Code(err); code != exitcode.Failure {
+		exit(code)
+	}
+	exit(exitcode.CommitFailed)
+}
+
+// nothingToCommit lets git report the status the way git commit does when
+// nothing is staged, and exits with its exit code
+func nothingToCommit() {
+	status := exec.Command("git", "commit", "--dry-run")
+	status.Dir = gitRoot
+	status.Stdout = os.Stdout
+	status.Stderr = os.Stderr
+	err := status.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exit(exitErr.ExitCode())
+	}
+	exit(exitcode.Failure)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/pterm/pterm"
+)
+
+// gitConfigOption returns key of section in the git config, e.g. core
+// editor, with the repository's config winning over the global and system
+// ones. go-git doesn't follow include directives, so keys it doesn't find
+// are looked up with git config where git is installed.
+func gitConfigOption(section, key string) string {
+	if cfg, err := repo.ConfigScoped(config.SystemScope); err == nil {
+		if value := cfg.Raw.Section(section).Option(key); value != "" {
+			return value
+		}
+	} else {
+		pterm.Debug.Println("Failed to read the git config:", err)
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return ""
+	}
+	value, _ := gitQuiet("config", section+"."+key)
+	return value
+}
+
+// gitConfigBool returns a boolean of the git config like git config --bool
+func gitConfigBool(section, key string) bool {
+	return slices.Contains([]string{"true", "yes", "on", "1"}, strings.ToLower(gitConfigOption(section, key)))
+}
+
+// gitConfigPath returns a path of the git config with ~ expanded
+func gitConfigPath(section, key string) string {
+	path := gitConfigOption(section, key)
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// commitTemplate returns the message of commit.template without its
+// comments, the starting point of the long description
+func commitTemplate() string {
+	path := gitConfigPath("commit", "template")
+	if path == "" {
+		return ""
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		pterm.Warning.Println("Failed to read commit.template:", err)
+		return ""
+	}
+	return stripComments(string(content))
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/45413/git-cc/share/man"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var helpCmd = &cobra.Command{
+	Use:   "help [command | topic | property]",
+	Short: "Help about any command, config property or topic of the manual",
+	Long: `Show the help of a command together with its entry in the manual and the
+config properties it uses, a section of the manual such as environment or
+exit-status, or the description of a config property. git cc help man
+prints the whole manual, which is built in and needs no installed man page.`,
+	Example: `  git cc help log
+  git cc help configuration
+  git cc help scope_discovery
+  git cc help man`,
+	ValidArgsFunction: completeHelpTopics,
+	Run:               help,
+}
+
+func init() {
+	rootCmd.SetHelpCommand(helpCmd)
+	// --help shows the manual entry too
+	commandHelp := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		commandHelp(cmd, args)
+		printManualHelp(cmd)
+	})
+}
+
+var (
+	// manualHeading matches the section headings of the manual
+	manualHeading = regexp.MustCompile(`^(#{2,3}) (.+)$`)
+	// manualEntry matches the entries of the commands, options, environment,
+	// exit status and properties sections
+	manualEntry = regexp.MustCompile("^(-[^:]*|<[^>]+>|[\\w.<>-]+): (.*)$")
+	// markdownLink matches links, internal ones have a #fragment
+	markdownLink = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+)
+
+func help(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		rootCmd.Help()
+		return
+	}
+	if c, _, err := rootCmd.Find(args); err == nil && c != rootCmd {
+		c.Help()
+		return
+	}
+
+	topic := strings.ToLower(strings.Join(args, "-"))
+	switch topic {
+	case "man", "manual":
+		pterm.Print(renderManual(man.Page))
+		return
+	case "config":
+		topic = "configuration"
+	}
+	sections := manualSections()
+	if section, ok := sections[topic]; ok {
+		pterm.Print(renderManual(section))
+		return
+	}
+	for _, name := range []string{"properties", "environment"} {
+		for _, line := range strings.Split(sections[name], "\n") {
+			if match := manualEntry.FindStringSubmatch(line); match != nil && strings.EqualFold(match[1], args[0]) {
+				pterm.Print(renderManual(line))
+				return
+			}
+		}
+	}
+
+	pterm.Error.Printfln("No help for %q, try a command, a config property or one of the topics %s", strings.Join(args, " "), strings.Join(helpTopics(), ", "))
+	exit(exitcode.Failure)
+}
+
+// printManualHelp prints the manual entry of the command and the config
+// properties it mentions after its help, the topics after the root's
+func printManualHelp(cmd *cobra.Command) {
+	if cmd == rootCmd {
+		pterm.Println("\nHelp topics:\n  " + strings.Join(helpTopics(), ", ") + "\n\nRun git cc help <topic> or git cc help <property> to read them.")
+		return
+	}
+	// subcommands such as draft save share the entry of draft
+	for cmd.Parent() != rootCmd {
+		cmd = cmd.Parent()
+	}
+	sections := manualSections()
+	entry := ""
+	for _, line := range strings.Split(sections["commands"], "\n") {
+		if match := manualEntry.FindStringSubmatch(line); match != nil && match[1] == cmd.Name() {
+			entry = match[2]
+			break
+		}
+	}
+	if entry == "" {
+		return
+	}
+	pterm.Println("\nManual:")
+	pterm.Print(renderManual(entry))
+
+	mentioned := entry + cmd.Long + cmd.LocalFlags().FlagUsages()
+	var properties []string
+	for _, line := range strings.Split(sections["properties"], "\n") {
+		match := manualEntry.FindStringSubmatch(line)
+		// plain words such as scopes are only taken for properties in code spans
+		if match != nil && (strings.Contains(entry, "`"+match[1]+"`") ||
+			strings.Contains(match[1], "_") && strings.Contains(mentioned, match[1])) {
+			properties = append(properties, line)
+		}
+	}
+	if len(properties) > 0 {
+		pterm.Println("\nConfig properties:")
+		pterm.Print(renderManual(strings.Join(properties, "\n")))
+	}
+}
+
+// manualSections returns the sections of the manual by their topic, the
+// heading in lower case with dashes. A section includes its subsections.
+func manualSections() map[string]string {
+	lines := strings.Split(man.Page, "\n")
+	sections := map[string]string{}
+	for i, line := range lines {
+		match := manualHeading.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		end := len(lines)
+		for j := i + 1; j < len(lines); j++ {
+			if next := manualHeading.FindStringSubmatch(lines[j]); next != nil && len(next[1]) <= len(match[1]) {
+				end = j
+				break
+			}
+		}
+		topic := strings.ToLower(strings.ReplaceAll(match[2], " ", "-"))
+		sections[topic] = strings.Join(lines[i:end], "\n")
+	}
+	return sections
+}
+
+// helpTopics returns the topics git cc help knows besides the commands
+func helpTopics() []string {
+	var topics []string
+	for topic := range manualSections() {
+		topics = append(topics, topic)
+	}
+	slices.Sort(topics)
+	return append(topics, "man")
+}
+
+// renderManual renders Markdown of the manual for the terminal, as man
+// would: headings in bold, paragraphs indented and wrapped to its width,
+// entries with their description indented below them
+func renderManual(markdown string) string {
+	width := min(pterm.GetTerminalWidth(), 100)
+	var out strings.Builder
+	inCode := false
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			out.WriteString("        " + line + "\n")
+			continue
+		}
+		line = markdownLink.ReplaceAllStringFunc(line, func(link string) string {
+			match := markdownLink.FindStringSubmatch(link)
+			if strings.HasPrefix(match[2], "#") {
+				return match[1]
+			}
+			return match[1] + " (" + match[2] + ")"
+		})
+		line = strings.ReplaceAll(line, "`", "")
+
+		switch heading := manualHeading.FindStringSubmatch(line); {
+		case heading != nil:
+			out.WriteString(pterm.Bold.Sprint(strings.ToUpper(heading[2])) + "\n")
+		case line == "":
+			out.WriteString("\n")
+		case strings.Trim(line, "=") == "":
+			// underlines the title
+		case i+1 < len(lines) && strings.HasPrefix(lines[i+1], "==="):
+			out.WriteString(pterm.Bold.Sprint(line) + "\n")
+		case manualEntry.MatchString(line):
+			match := manualEntry.FindStringSubmatch(line)
+			out.WriteString("    " + pterm.Bold.Sprint(match[1]) + "\n")
+			out.WriteString(indentText(wrapText(match[2], width-8), "        ") + "\n")
+		default:
+			out.WriteString(indentText(wrapText(line, width-4), "    ") + "\n")
+		}
+	}
+	return out.String()
+}
+
+// indentText prefixes every line of text but the empty ones with indent
+func indentText(text, indent string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// completeHelpTopics completes commands, topics and config properties
+func completeHelpTopics(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, c := range rootCmd.Commands() {
+		if c.IsAvailableCommand() {
+			names = append(names, c.Name())
+		}
+	}
+	names = append(names, helpTopics()...)
+	for _, line := range strings.Split(manualSections()["properties"], "\n") {
+		if match := manualEntry.FindStringSubmatch(line); match != nil {
+			names = append(names, match[1])
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
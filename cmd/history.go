@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// conventionalCommit is a commit from the history together with its parsed message
+type conventionalCommit struct {
+	Commit *object.Commit
+	Data   CommitPromptData
+	// Body is the long description without the trailers
+	Body     string
+	Trailers []trailer
+	// Valid is false when the message isn't a conventional commit
+	Valid bool
+	// DependencyBot names the renovate or dependabot bot which made the
+	// commit, its Data is filled from the bot's config when it isn't Valid
+	DependencyBot string
+}
+
+// BreakingNotes returns the notes of all BREAKING CHANGE footers
+func (c conventionalCommit) BreakingNotes() []string {
+	var notes []string
+	for _, t := range c.Trailers {
+		if t.Token == "BREAKING CHANGE" || t.Token == "BREAKING-CHANGE" {
+			notes = append(notes, t.Value)
+		}
+	}
+	return notes
+}
+
+// DependencyUpdate reports whether c updates dependencies: it was made by a
+// dependency bot or is a build or chore commit scoped deps or deps-dev, as
+// the bots name their updates
+func (c conventionalCommit) DependencyUpdate() bool {
+	if c.DependencyBot != "" {
+		return true
+	}
+	return c.Valid && (c.Data.Type == "build" || c.Data.Type == "chore") && (c.Data.Scope == "deps" || c.Data.Scope == "deps-dev")
+}
+
+// dependencyUpdates counts n dependency updates, e.g. 27 dependency updates
+func dependencyUpdates(n int) string {
+	if n == 1 {
+		return "1 dependency update"
+	}
+	return formatNumber(n) + " dependency updates"
+}
+
+// commitRange returns the commits reachable from to but not from from,
+// newest first. An empty from returns the whole history of to.
+func commitRange(from, to string) ([]conventionalCommit, error) {
+	toHash, err := repo.ResolveRevision(plumbing.Revision(to))
+	if err != nil {
+		return nil, err
+	}
+	toCommit, err := repo.CommitObject(*toHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// everything reachable from from is excluded from the walk
+	exclude := map[plumbing.Hash]bool{}
+	if from != "" {
+		fromHash, err := repo.ResolveRevision(plumbing.Revision(from))
+		if err != nil {
+			return nil, err
+		}
+		fromCommit, err := repo.CommitObject(*fromHash)
+		if err != nil {
+			return nil, err
+		}
+		err = object.NewCommitPreorderIter(fromCommit, nil, nil).ForEach(func(c *object.Commit) error {
+			exclude[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var commits []conventionalCommit
+	err = object.NewCommitIterCTime(toCommit, exclude, nil).ForEach(func(c *object.Commit) error {
+		commits = append(commits, parseHistoryCommit(c))
+		return nil
+	})
+	return commits, err
+}
+
+func parseHistoryCommit(c *object.Commit) conventionalCommit {
+	data, err := parseCommitMessage(c.Message)
+
+	_, rest, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+	body, trailers := splitTrailers(rest)
+
+	commit := conventionalCommit{
+		Commit:   c,
+		Data:     data,
+		Body:     body,
+		Trailers: trailers,
+		Valid:    err == nil,
+	}
+	if bot := dependencyBotOf(c); bot != nil {
+		commit.DependencyBot = bot.Name
+		if !commit.Valid {
+			// Bump x from 1.0 to 1.1 becomes chore(deps): bump x from 1.0 to 1.1
+			subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+			if subject != "" {
+				subject = strings.ToLower(subject[:1]) + subject[1:]
+			}
+			commit.Data = CommitPromptData{Type: bot.Type, Scope: bot.Scope, ShortDescription: subject, LongDescription: body}
+		}
+	}
+	return commit
+}
+
+// previousTag returns the latest tag reachable from rev, not counting a tag
+// on rev itself, or an empty string if there is none
+func previousTag(rev string) string {
+	tag := describeTag(rev)
+	if tag == "" {
+		return ""
+	}
+
+	tagged, _ := gitOutput("rev-parse", tag+"^{commit}")
+	current, _ := gitOutput("rev-parse", rev+"^{commit}")
+	if tagged == current {
+		return describeTag(rev + "^")
+	}
+	return tag
+}
+
+// describeTag returns the latest tag reachable from rev
+func describeTag(rev string) string {
+	cmd := exec.Command("git", "describe", "--tags", "--abbrev=0", rev)
+	cmd.Dir = gitRoot
+	out, err := cmd.Output()
+	if err != nil {
+		// no tags or rev is the root commit
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
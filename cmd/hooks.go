@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// marks hooks written by git-cc so they are never mistaken for foreign ones
+const hookMarker = "# installed by git-cc"
+
+var hookScripts = map[string]string{
+	"commit-msg": `#!/bin/sh
+` + hookMarker + `
+# validate every commit message against the Conventional Commits spec
+exec git cc lint "$1"
+`,
+	"prepare-commit-msg": `#!/bin/sh
+` + hookMarker + `
+# prompt for the message on a plain "git commit", leave -m, templates,
+# merges, squashes and amends alone
+[ -z "$2" ] || exit 0
+# GUI clients, IDEs and CI have no terminal to prompt on, git opens the
+# editor or takes their message as usual
+( : < /dev/tty ) 2>/dev/null || exit 0
+exec < /dev/tty
+exec git cc --write-message "$1"
+`,
+}
+
+var installHooksCmd = &cobra.Command{
+	Use:   "install-hooks",
+	Short: "Install git hooks which prompt for or validate commit messages",
+	Long: `Install git hooks into .git/hooks, or the directory configured by
+core.hooksPath.
+
+The commit-msg hook validates every commit message with git cc lint. The
+prepare-commit-msg hook launches the interactive prompt when running a plain
+git commit.
+
+--merge-driver configures git cc merge-changelog as merge driver of
+CHANGELOG.md in .git/config and .git/info/attributes, so releases and
+entries added on two branches no longer conflict.
+
+Existing hooks are never overwritten unless --force is given, and hooks
+managed by husky or lefthook are detected so git cc can be added to their
+configuration instead.`,
+	Args: cobra.NoArgs,
+	Run:  installHooks,
+}
+
+func init() {
+	installHooksCmd.Flags().Bool("commit-msg", true, "Install the commit-msg hook validating messages")
+	installHooksCmd.Flags().Bool("prepare-commit-msg", false, "Install the prepare-commit-msg hook launching the prompt")
+	installHooksCmd.Flags().Bool("merge-driver", false, "Install the merge driver of CHANGELOG.md")
+	installHooksCmd.Flags().Bool("uninstall", false, "Remove the hooks and merge driver installed by git-cc")
+	installHooksCmd.Flags().Bool("force", false, "Overwrite existing hooks")
+	rootCmd.AddCommand(installHooksCmd)
+}
+
+func installHooks(cmd *cobra.Command, args []string) {
+	uninstall, _ := cmd.Flags().GetBool("uninstall")
+	force, _ := cmd.Flags().GetBool("force")
+
+	var hooks []string
+	for _, hook := range []string{"commit-msg", "prepare-commit-msg"} {
+		// uninstall removes every hook git-cc installed
+		if enabled, _ := cmd.Flags().GetBool(hook); enabled || uninstall {
+			hooks = append(hooks, hook)
+		}
+	}
+
+	dir, err := hooksDir()
+	if err != nil {
+		pterm.Error.Println("Failed to locate hooks directory:", err)
+		exit(exitcode.Failure)
+	}
+
+	if manager := hookManager(dir); manager != "" && len(hooks) > 0 && !force {
+		pterm.Error.Printfln("hooks in %s are managed by %s, add git cc to its configuration instead:", dir, manager)
+		fmt.Println(`  commit-msg:         git cc lint "$1"`)
+		fmt.Println(`  prepare-commit-msg: git cc --write-message "$1" (only when "$2" is empty)`)
+		exit(exitcode.Failure)
+	}
+
+	failed := false
+	for _, hook := range hooks {
+		if uninstall {
+			err = uninstallHook(dir, hook)
+		} else {
+			err = installHook(dir, hook, force)
+		}
+		if err != nil {
+			pterm.Error.Println(err)
+			failed = true
+		}
+	}
+	// the merge driver is configured in git, whoever manages the hooks
+	if driver, _ := cmd.Flags().GetBool("merge-driver"); driver || uninstall {
+		if uninstall {
+			err = uninstallMergeDriver()
+		} else {
+			err = installMergeDriver()
+		}
+		if err != nil {
+			pterm.Error.Println("Failed to configure the merge driver:", err)
+			failed = true
+		}
+	}
+	if failed {
+		exit(exitcode.Failure)
+	}
+}
+
+// hooksDir returns the hooks directory, honoring core.hooksPath
+func hooksDir() (string, error) {
+	out, err := gitOutput("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+
+	dir := strings.TrimSpace(out)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitRoot, dir)
+	}
+	return dir, nil
+}
+
+// hookManager returns the name of the tool managing the hooks in dir, if any
+func hookManager(dir string) string {
+	if strings.Contains(filepath.ToSlash(dir), ".husky") {
+		return "husky"
+	}
+
+	for _, config := range []string{"lefthook.yml", ".lefthook.yml", "lefthook.yaml", ".lefthook.yaml"} {
+		if _, err := os.Stat(filepath.Join(gitRoot, config)); err == nil {
+			return "lefthook"
+		}
+	}
+
+	return ""
+}
+
+func installHook(dir string, hook string, force bool) error {
+	path := filepath.Join(dir, hook)
+
+	existing, err := os.ReadFile(path)
+	if err == nil && !bytes.Contains(existing, []byte(hookMarker)) && !force {
+		return fmt.Errorf("%s hook already exists at %s, use --force to overwrite it", hook, path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(hookScripts[hook]), 0o755); err != nil {
+		return err
+	}
+
+	pterm.Success.Printfln("Installed %s hook to %s", hook, path)
+	return nil
+}
+
+func uninstallHook(dir string, hook string) error {
+	path := filepath.Join(dir, hook)
+
+	existing, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !bytes.Contains(existing, []byte(hookMarker)) {
+		pterm.Warning.Printfln("%s hook at %s was not installed by git-cc, leaving it alone", hook, path)
+		return nil
+	}
+
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	pterm.Success.Printfln("Removed %s hook from %s", hook, path)
+	return nil
+}
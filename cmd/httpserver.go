@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+)
+
+// maximum size of a request body
+const httpMaxBody = 1024 * 1024
+
+// httpServer offers the parse, validate, format and changelog logic of the
+// CLI over HTTP, it never changes the repository
+type httpServer struct {
+	// go-git repositories are not safe for concurrent use
+	mu sync.Mutex
+}
+
+// serveHTTP listens on addr until the process is stopped
+func serveHTTP(addr string) {
+	s := &httpServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /parse", s.parse)
+	mux.HandleFunc("POST /validate", s.validate)
+	mux.HandleFunc("POST /format", s.format)
+	mux.HandleFunc("GET /changelog", s.changelog)
+	mux.HandleFunc("GET /schema", s.schema)
+
+	pterm.Info.Printfln("Listening on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+}
+
+// parse answers {"message"} with the prompt answers of the message
+func (s *httpServer) parse(w http.ResponseWriter, r *http.Request) {
+	var args struct {
+		Message string `json:"message"`
+	}
+	if !readJSON(w, r, &args) {
+		return
+	}
+	data, err := parseCommitMessage(stripComments(args.Message))
+	if err != nil {
+		writeJSONError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+// validate answers {"message"} or {"commit"} like serve --lint
+func (s *httpServer) validate(w http.ResponseWriter, r *http.Request) {
+	var req lintRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	s.mu.Lock()
+	result := lintMessage(req)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, result)
+}
+
+// format answers prompt answers with the commit message built from them
+func (s *httpServer) format(w http.ResponseWriter, r *http.Request) {
+	var data CommitPromptData
+	if !readJSON(w, r, &data) {
+		return
+	}
+	if err := validateCommitData(data); err != nil {
+		writeJSONError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	message, err := renderCommitMessage(data)
+	if err != nil {
+		writeJSONError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": message})
+}
+
+// changelog renders the release between the from and to query parameters as
+// markdown, from defaults to the previous tag and to to HEAD
+func (s *httpServer) changelog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	to := query.Get("to")
+	if to == "" {
+		to = "HEAD"
+	}
+
+	s.mu.Lock()
+	from := query.Get("from")
+	if !query.Has("from") {
+		from = previousTag(to)
+	}
+	section, _, err := changelogSection(from, to, query.Get("release"))
+	s.mu.Unlock()
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Write([]byte(section))
+}
+
+func (s *httpServer) schema(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, promptSchema())
+}
+
+// readJSON decodes the request body into v, on failure it answers with an
+// error and returns false
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, httpMaxBody)).Decode(v); err != nil {
+		writeJSONError(w, http.StatusBadRequest, errors.New("invalid request: "+err.Error()))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		pterm.Debug.Println("Failed to write response:", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/45413/git-cc/share/locales"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// uiLanguages are the languages of the prompts and messages, English is
+// built in and the others have a catalog in share/locales
+var uiLanguages = []string{"en", "de", "es", "fr", "zh"}
+
+var (
+	translatorOnce sync.Once
+	translator     *message.Printer
+)
+
+// tr translates the English format string key into the language of the UI
+// and formats it with args, keys without a translation are used as is.
+// Commit messages are never translated, only what git-cc says.
+func tr(key string, args ...any) string {
+	translatorOnce.Do(func() {
+		translator = newTranslator(uiLanguage())
+	})
+	return translator.Sprintf(key, args...)
+}
+
+// uiLanguage returns the language config property or else the language of
+// LC_MESSAGES, English if there's no catalog for it
+func uiLanguage() language.Tag {
+	tag := posixLocale("LC_MESSAGES")
+	if configured := viper.GetString("language"); configured != "" {
+		parsed, err := language.Parse(strings.ReplaceAll(configured, "_", "-"))
+		if err != nil {
+			pterm.Warning.Printfln("Ignoring the language %q: %s", configured, err)
+		} else {
+			tag = parsed
+		}
+	}
+	base, _ := tag.Base()
+	if !slices.Contains(uiLanguages, base.String()) {
+		pterm.Debug.Printfln("No translation for %s, using English", tag)
+		return language.English
+	}
+	return language.Make(base.String())
+}
+
+// newTranslator returns a printer translating with the catalog of tag
+func newTranslator(tag language.Tag) *message.Printer {
+	builder := catalog.NewBuilder(catalog.Fallback(language.English))
+	if tag != language.English {
+		content, err := locales.Catalogs.ReadFile(tag.String() + ".json")
+		var translations map[string]string
+		if err == nil {
+			err = json.Unmarshal(content, &translations)
+		}
+		if err != nil {
+			pterm.Debug.Printfln("Failed to load the %s translations: %s", tag, err)
+		}
+		for key, translation := range translations {
+			if err := builder.SetString(tag, key, translation); err != nil {
+				pterm.Debug.Printfln("Ignoring the %s translation of %q: %s", tag, key, err)
+			}
+		}
+	}
+	return message.NewPrinter(tag, message.Catalog(builder))
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var (
+	answersMu sync.Mutex
+	// answersSoFar are the answers last saved by answersChanged, persisted
+	// again when a prompt times out
+	answersSoFar *CommitPromptData
+)
+
+// awaitAnswer starts the prompt_timeout of a prompt about to read the
+// keyboard, the returned function stops it once the prompt is answered
+func awaitAnswer() func() {
+	timeout := viper.GetDuration("prompt_timeout")
+	if timeout <= 0 {
+		return func() {}
+	}
+	// prompts switch the terminal to raw mode, it is given back as it is now
+	fd := int(os.Stdin.Fd())
+	state, _ := term.GetState(fd)
+	timer := time.AfterFunc(timeout, func() {
+		if state != nil {
+			term.Restore(fd, state)
+		}
+		promptTimedOut(timeout)
+	})
+	return func() { timer.Stop() }
+}
+
+// promptTimedOut saves the answers given so far as draft and exits, so an
+// abandoned prompt doesn't hold the terminal and the repository forever
+func promptTimedOut(timeout time.Duration) {
+	pterm.Println()
+	answersMu.Lock()
+	answers := answersSoFar
+	answersMu.Unlock()
+	if answers != nil && draftsEnabled() && !amend {
+		saveDraft(*answers)
+		pterm.Info.Println(tr("No answer for %s, the answers so far are saved, run git cc to resume them", timeout))
+	} else {
+		pterm.Warning.Println(tr("No answer for %s, giving up", timeout))
+	}
+	exit(exitcode.Aborted)
+}
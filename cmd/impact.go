@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var impactCmd = &cobra.Command{
+	Use:   "impact <commit>",
+	Short: "List the later commits a revert of a commit may conflict with",
+	Long: `List the commits made after a commit, up to HEAD, which change the same
+files or share its scope, before reverting it with git cc revert.
+
+Commits changing the same files may make the revert conflict, commits of
+the same scope may build on the reverted change without touching its
+files. Commits which already revert it are pointed out as well.`,
+	Example: `  git cc impact 1a2b3c4
+  git cc impact HEAD~5 && git cc revert HEAD~5`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.ExactArgs(1),
+	Run:         impact,
+}
+
+func init() {
+	rootCmd.AddCommand(impactCmd)
+}
+
+// impacted is a later commit with the files it shares with the analyzed one
+type impacted struct {
+	commit      conventionalCommit
+	files       []string
+	sameScope   bool
+	revertsThis bool
+}
+
+func impact(cmd *cobra.Command, args []string) {
+	hash, err := gitQuiet("rev-parse", "--verify", "--quiet", args[0]+"^{commit}")
+	if err != nil {
+		pterm.Error.Printfln("%s is not a commit", args[0])
+		exit(exitcode.Failure)
+	}
+	if _, err := gitQuiet("merge-base", "--is-ancestor", hash, "HEAD"); err != nil {
+		pterm.Error.Printfln("%s is not part of the history of HEAD", args[0])
+		exit(exitcode.Failure)
+	}
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		pterm.Error.Println("Failed to read commit:", err)
+		exit(exitcode.Failure)
+	}
+	target := parseHistoryCommit(commit)
+
+	out, err := gitOutput("diff-tree", "--root", "--no-commit-id", "--no-renames", "--name-only", "-r", hash)
+	if err != nil {
+		pterm.Error.Println("Failed to read the changed files:", err)
+		exit(exitcode.Failure)
+	}
+	files := strings.Fields(out)
+
+	// hashes are followed by the files each commit changed
+	out, err = gitOutput("log", "--format=%x00%H", "--name-only", "--no-renames", "--no-merges", hash+"..HEAD", "--")
+	if err != nil {
+		pterm.Error.Println("Failed to read history:", err)
+		exit(exitcode.Failure)
+	}
+	var found []impacted
+	later := 0
+	for _, entry := range strings.Split(out, "\x00")[1:] {
+		fields := strings.Fields(entry)
+		later++
+		c, err := repo.CommitObject(plumbing.NewHash(fields[0]))
+		if err != nil {
+			pterm.Error.Println("Failed to read commit:", err)
+			exit(exitcode.Failure)
+		}
+		i := impacted{commit: parseHistoryCommit(c)}
+		for _, file := range fields[1:] {
+			if slices.Contains(files, file) {
+				i.files = append(i.files, file)
+			}
+		}
+		i.sameScope = target.Valid && target.Data.Scope != "" &&
+			i.commit.Valid && i.commit.Data.Scope == target.Data.Scope
+		// by git revert or git cc revert
+		i.revertsThis = strings.Contains(c.Message, "This reverts commit "+hash) ||
+			i.commit.Data.Type == "revert" && slices.ContainsFunc(i.commit.Trailers, func(t trailer) bool {
+				return t.Token == "Refs" && t.Value == hash
+			})
+		if len(i.files) > 0 || i.sameScope || i.revertsThis {
+			found = append(found, i)
+		}
+	}
+
+	pterm.Println(logLine(target))
+	pterm.Printfln("changed %s files, followed by %s commits", formatNumber(len(files)), formatNumber(later))
+	pterm.Println()
+	if len(found) == 0 {
+		pterm.Success.Println("No later commit changes the same files or shares the scope, the revert should apply cleanly")
+		return
+	}
+
+	conflicts, reverts := 0, 0
+	for _, i := range found {
+		pterm.Println(logLine(i.commit))
+		switch {
+		case i.revertsThis:
+			reverts++
+			pterm.Println("  " + pterm.Red("already reverts it"))
+		case len(i.files) > 0:
+			conflicts++
+			pterm.Println("  " + pterm.Yellow("changes ") + strings.Join(i.files, ", "))
+		}
+		if i.sameScope && !i.revertsThis {
+			pterm.Println("  " + pterm.Gray("same scope "+i.commit.Data.Scope+", may depend on it"))
+		}
+	}
+	pterm.Println()
+	switch {
+	case reverts > 0:
+		pterm.Warning.Println("The commit has already been reverted")
+	case conflicts > 0:
+		pterm.Warning.Printfln("%s later commits change the same files, the revert may conflict", formatNumber(conflicts))
+	default:
+		pterm.Info.Println("No later commit changes the same files, check the commits of the same scope don't depend on it")
+	}
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/conventional"
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var defaultCommitTypes = []string{"feat", "fix", "build", "chore", "ci", "docs", "refactor", "test"}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a .git-cc.yaml for the repository",
+	Long: `Walk through the commit types, scopes, emoji and validation rules of the
+repository and write them to a commented .git-cc.yaml in its root.`,
+	Args: cobra.NoArgs,
+	Run:  initConfig,
+}
+
+func init() {
+	initCmd.Flags().Bool("force", false, "Overwrite an existing .git-cc.yaml")
+	rootCmd.AddCommand(initCmd)
+}
+
+// repoConfig holds the answers of the init wizard
+type repoConfig struct {
+	UseDefaults          bool
+	CustomTypes          []string
+	Scopes               []string
+	Emoji                bool
+	EmojiPosition        string
+	MaxSubjectLength     int
+	SubjectCase          string
+	ForbidTrailingPeriod bool
+	ImperativeMood       bool
+}
+
+func initConfig(cmd *cobra.Command, args []string) {
+	path := filepath.Join(gitRoot, ".git-cc.yaml")
+	if force, _ := cmd.Flags().GetBool("force"); !force {
+		if existing := findConfig(gitRoot, ".git-cc"); existing != "" {
+			pterm.Error.Printfln("%s already exists, use --force to overwrite it", existing)
+			exit(exitcode.Failure)
+		}
+	}
+
+	config := askRepoConfig()
+	if err := os.WriteFile(path, []byte(config.yaml()), 0o644); err != nil {
+		pterm.Error.Println("Failed to write config:", err)
+		exit(exitcode.Failure)
+	}
+	pterm.Success.Printfln("Wrote %s, commit it to share the settings", path)
+}
+
+// askRepoConfig prompts for the settings, starting with the current ones
+func askRepoConfig() repoConfig {
+	config := currentRepoConfig()
+	config.askTypes()
+	config.askScopes()
+	config.askEmoji()
+	config.askRules()
+	return config
+}
+
+// currentRepoConfig returns the settings in effect
+func currentRepoConfig() repoConfig {
+	position := viper.GetString("emoji_position")
+	if position != "type" {
+		position = "description"
+	}
+	return repoConfig{
+		UseDefaults:          viper.GetBool("use_defaults"),
+		CustomTypes:          viper.GetStringSlice("custom_commit_types"),
+		Scopes:               viper.GetStringSlice("scopes"),
+		Emoji:                viper.GetBool("emoji"),
+		EmojiPosition:        position,
+		MaxSubjectLength:     viper.GetInt("max_subject_length"),
+		SubjectCase:          viper.GetString("subject_case"),
+		ForbidTrailingPeriod: viper.GetBool("forbid_trailing_period"),
+		ImperativeMood:       viper.GetBool("imperative_mood"),
+	}
+}
+
+// settings returns the config properties of c
+func (c repoConfig) settings() map[string]any {
+	return map[string]any{
+		"use_defaults":           c.UseDefaults,
+		"custom_commit_types":    append([]string{}, c.CustomTypes...),
+		"scopes":                 append([]string{}, c.Scopes...),
+		"emoji":                  c.Emoji,
+		"emoji_position":         c.EmojiPosition,
+		"max_subject_length":     c.MaxSubjectLength,
+		"subject_case":           c.SubjectCase,
+		"forbid_trailing_period": c.ForbidTrailingPeriod,
+		"imperative_mood":        c.ImperativeMood,
+	}
+}
+
+// validName matches the types and scopes allowed in a header
+var validName = regexp.MustCompile(`^\w[\w-]*$`)
+
+func (c *repoConfig) askTypes() {
+	c.UseDefaults, _ = ui.Confirm(fmt.Sprintf("Use the default commit types (%s)", strings.Join(defaultCommitTypes, ", ")), c.UseDefaults)
+	label := "Additional commit types (comma separated, optional)"
+	if !c.UseDefaults {
+		label = "Commit types (comma separated)"
+	}
+	for {
+		custom, _ := ui.Input(label, strings.Join(c.CustomTypes, ", "))
+		types := splitList(custom)
+		if invalid := slices.IndexFunc(types, func(t string) bool { return !validName.MatchString(t) }); invalid >= 0 {
+			pterm.Warning.Printfln("%q is not a valid type, use letters, digits, _ and -", types[invalid])
+			continue
+		}
+		if c.UseDefaults || len(types) > 0 {
+			c.CustomTypes = types
+			break
+		}
+		pterm.Warning.Println("At least one commit type is needed")
+	}
+}
+
+func (c *repoConfig) askScopes() {
+	// scopes already used in the history are a good start
+	known := c.Scopes
+	if len(known) == 0 {
+		known = historyScopes()
+	}
+	for {
+		configured, _ := ui.Input("Scopes (comma separated, empty to allow any)", strings.Join(known, ", "))
+		list := splitList(configured)
+		if invalid := slices.IndexFunc(list, func(s string) bool { return strings.ContainsAny(s, "()\r\n") }); invalid >= 0 {
+			pterm.Warning.Printfln("%q is not a valid scope, it must not contain parentheses", list[invalid])
+			known = list
+			continue
+		}
+		c.Scopes = list
+		break
+	}
+}
+
+func (c *repoConfig) askEmoji() {
+	c.Emoji, _ = ui.Confirm("Add the gitmoji of the commit type to the header", c.Emoji)
+	c.EmojiPosition = "description"
+	if c.Emoji {
+		position, _ := ui.Select("Emoji position", []string{"description", "type"}, c.EmojiPosition)
+		c.EmojiPosition = position
+	}
+}
+
+func (c *repoConfig) askRules() {
+	for {
+		limit, _ := ui.Input("Maximum header length (0 for no limit)", strconv.Itoa(c.MaxSubjectLength))
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err == nil && n >= 0 {
+			c.MaxSubjectLength = n
+			break
+		}
+		pterm.Warning.Printfln("%q is not a number", limit)
+	}
+
+	options := []string{"any", "lower case (feat: add login)", "sentence case (feat: Add login)"}
+	cases := []string{"", conventional.LowerCase, conventional.SentenceCase}
+	current := options[max(slices.Index(cases, c.SubjectCase), 0)]
+	selected, _ := ui.Select("Case of the description", options, current)
+	c.SubjectCase = cases[slices.Index(options, selected)]
+
+	c.ForbidTrailingPeriod, _ = ui.Confirm("Forbid a period at the end of the description", c.ForbidTrailingPeriod)
+	c.ImperativeMood, _ = ui.Confirm("Require the imperative mood (add, not added)", c.ImperativeMood)
+}
+
+// yaml renders the config as a commented .git-cc.yaml
+func (c repoConfig) yaml() string {
+	var b strings.Builder
+
+	b.WriteString("# git-cc configuration, see https://github.com/45413/git-cc#configuration\n\n")
+
+	if c.UseDefaults {
+		fmt.Fprintf(&b, "# %s are offered, plus custom_commit_types\n", strings.Join(defaultCommitTypes, ", "))
+	} else {
+		b.WriteString("# only custom_commit_types are offered\n")
+	}
+	fmt.Fprintf(&b, "use_defaults: %t\n", c.UseDefaults)
+	b.WriteString("custom_commit_types:" + yamlList(c.CustomTypes) + "\n\n")
+
+	b.WriteString("# the scopes to choose from, any scope can be entered when empty\n")
+	b.WriteString("scopes:" + yamlList(c.Scopes) + "\n\n")
+
+	b.WriteString("# gitmoji of the type in front of the description or the type\n")
+	fmt.Fprintf(&b, "emoji: %t\n", c.Emoji)
+	fmt.Fprintf(&b, "emoji_position: %s\n\n", c.EmojiPosition)
+
+	b.WriteString("# rules the prompts, git cc lint and git cc serve validate messages with\n")
+	fmt.Fprintf(&b, "max_subject_length: %d # header length, 0 for no limit\n", c.MaxSubjectLength)
+	fmt.Fprintf(&b, "subject_case: %q # lower, sentence or empty for any\n", c.SubjectCase)
+	fmt.Fprintf(&b, "forbid_trailing_period: %t\n", c.ForbidTrailingPeriod)
+	fmt.Fprintf(&b, "imperative_mood: %t\n", c.ImperativeMood)
+
+	return b.String()
+}
+
+// yamlList renders items as a block sequence, or an empty flow sequence
+func yamlList(items []string) string {
+	if len(items) == 0 {
+		return " []"
+	}
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString("\n  - " + strconv.Quote(item))
+	}
+	return b.String()
+}
+
+// splitList splits a comma separated answer, dropping blanks and duplicates
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" && !slices.Contains(items, item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
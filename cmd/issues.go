@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// issueToken is the issue_token of the global config
+var issueToken string
+
+// issueTracker is the GitHub or GitLab project a remote points at
+type issueTracker struct {
+	// kind is github or gitlab
+	kind    string
+	host    string
+	project string
+}
+
+// issue is an open issue of the tracker
+type issue struct {
+	Number int
+	Title  string
+}
+
+// askIssue offers the open issues of the tracker the remote points at with
+// issue_prompt set, and returns a footer referencing or closing the one
+// picked. Nothing is asked when the issues can't be listed.
+func askIssue() (trailer, bool) {
+	if !viper.GetBool("issue_prompt") {
+		return trailer{}, false
+	}
+	tracker, err := remoteTracker()
+	if err != nil {
+		pterm.Debug.Println("No issue prompt:", err)
+		return trailer{}, false
+	}
+	issues, err := openIssues(tracker)
+	if err != nil {
+		pterm.Warning.Println(tr("Failed to list the open issues:"), err)
+		return trailer{}, false
+	}
+	if len(issues) == 0 {
+		return trailer{}, false
+	}
+
+	none := tr("none")
+	options := []string{none}
+	byOption := map[string]issue{}
+	for _, i := range issues {
+		option := fmt.Sprintf("#%d %s", i.Number, i.Title)
+		options = append(options, option)
+		byOption[option] = i
+	}
+	selected, _ := ui.Select(tr("Related issue"), options, none)
+	picked, ok := byOption[selected]
+	if !ok {
+		return trailer{}, false
+	}
+
+	// GitHub style references read "Closes #123"
+	number := strconv.Itoa(picked.Number)
+	footer := viper.GetString("issue_footer")
+	if footer == "" {
+		footer = "Refs"
+	}
+	choices := []string{footer + " #" + number}
+	for _, token := range []string{"Closes", "Refs"} {
+		if !strings.EqualFold(token, footer) {
+			choices = append(choices, token+" #"+number)
+		}
+	}
+	choice, _ := ui.Select(tr("Footer"), choices, choices[0])
+	token, _, _ := strings.Cut(choice, " #")
+	return trailer{Token: token, Separator: " #", Value: number}, true
+}
+
+// remoteTracker returns the tracker of the remote of the current branch,
+// or else of origin. issue_tracker tells the kind of self-hosted instances
+// whose host name contains neither github nor gitlab.
+func remoteTracker() (issueTracker, error) {
+	name := "origin"
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		if config, err := repo.Config(); err == nil {
+			if branch, ok := config.Branches[head.Name().Short()]; ok && branch.Remote != "" && branch.Remote != "." {
+				name = branch.Remote
+			}
+		}
+	}
+	remote, err := repo.Remote(name)
+	if err != nil {
+		return issueTracker{}, fmt.Errorf("remote %s: %w", name, err)
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return issueTracker{}, fmt.Errorf("remote %s has no URL", name)
+	}
+	host, project, err := parseRemoteURL(urls[0])
+	if err != nil {
+		return issueTracker{}, err
+	}
+
+	kind := viper.GetString("issue_tracker")
+	switch {
+	case kind == "github" || kind == "gitlab":
+	case kind != "" && kind != "auto":
+		return issueTracker{}, fmt.Errorf("unknown issue_tracker %q, use auto, github or gitlab", kind)
+	case strings.Contains(host, "github"):
+		kind = "github"
+	case strings.Contains(host, "gitlab"):
+		kind = "gitlab"
+	default:
+		return issueTracker{}, fmt.Errorf("%s is neither GitHub nor GitLab, set issue_tracker", host)
+	}
+	return issueTracker{kind: kind, host: host, project: project}, nil
+}
+
+// parseRemoteURL returns the host and project path of a remote URL, such as
+// github.com and org/repo for git@github.com:org/repo.git
+func parseRemoteURL(remote string) (string, string, error) {
+	host, path := "", ""
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		host, path = u.Host, u.Path
+		// the SSH port isn't the one of the API
+		if u.Scheme == "ssh" {
+			host = u.Hostname()
+		}
+	} else if at, rest, ok := strings.Cut(remote, ":"); ok && !strings.Contains(at, "/") {
+		// scp-like syntax, user@host:path
+		_, host, _ = strings.Cut(at, "@")
+		if host == "" {
+			host = at
+		}
+		path = rest
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || !strings.Contains(path, "/") {
+		return "", "", fmt.Errorf("can't tell the project of the remote %s", remote)
+	}
+	return host, path, nil
+}
+
+// openIssues returns the open issues of tracker, most recently updated
+// first. Pull requests, which GitHub lists as issues too, are left out.
+func openIssues(tracker issueTracker) ([]issue, error) {
+	token := trackerToken(tracker)
+	var issues []issue
+	if tracker.kind == "gitlab" {
+		api := "https://" + tracker.host + "/api/v4/projects/" + url.PathEscape(tracker.project) + "/issues?state=opened&order_by=updated_at&per_page=100"
+		body, err := apiGet(api, token)
+		if err != nil {
+			return nil, err
+		}
+		var found []struct {
+			IID   int    `json:"iid"`
+			Title string `json:"title"`
+		}
+		if err := json.Unmarshal(body, &found); err != nil {
+			return nil, fmt.Errorf("reading issues: %w", err)
+		}
+		for _, i := range found {
+			issues = append(issues, issue{Number: i.IID, Title: i.Title})
+		}
+		return issues, nil
+	}
+
+	base := "https://api.github.com"
+	if tracker.host != "github.com" {
+		// GitHub Enterprise Server
+		base = "https://" + tracker.host + "/api/v3"
+	}
+	body, err := apiGet(base+"/repos/"+tracker.project+"/issues?state=open&sort=updated&per_page=100", token)
+	if err != nil {
+		return nil, err
+	}
+	var found []struct {
+		Number      int    `json:"number"`
+		Title       string `json:"title"`
+		PullRequest any    `json:"pull_request"`
+	}
+	if err := json.Unmarshal(body, &found); err != nil {
+		return nil, fmt.Errorf("reading issues: %w", err)
+	}
+	for _, i := range found {
+		if i.PullRequest == nil {
+			issues = append(issues, issue{Number: i.Number, Title: i.Title})
+		}
+	}
+	return issues, nil
+}
+
+// trackerToken returns the API token for tracker: GITHUB_TOKEN or GH_TOKEN
+// for GitHub, GITLAB_TOKEN for GitLab, issue_token of the global config or
+// the credentials git and the gh and glab CLIs know. Public projects are
+// listed without one.
+func trackerToken(tracker issueTracker) string {
+	envs := []string{"GITHUB_TOKEN", "GH_TOKEN"}
+	if tracker.kind == "gitlab" {
+		envs = []string{"GITLAB_TOKEN"}
+	}
+	for _, env := range envs {
+		if token := os.Getenv(env); token != "" {
+			return token
+		}
+	}
+	if issueToken != "" {
+		return issueToken
+	}
+	token, err := hostToken(tracker.host)
+	if err != nil {
+		pterm.Debug.Println(err)
+	}
+	return token
+}
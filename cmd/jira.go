@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/conventional"
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+var (
+	// jiraToken is the jira_token of the global config, only sent to the
+	// host of jiraTokenURL, the jira_url of the global config
+	jiraToken    string
+	jiraTokenURL string
+	// jiraKeyPattern matches Jira issue keys such as PROJ-123
+	jiraKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9_]*-[1-9][0-9]*\b`)
+)
+
+// jiraIssue is the key and summary of a Jira issue
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary string `json:"summary"`
+	} `json:"fields"`
+}
+
+// jiraEnabled reports whether jira_url sets up the Jira integration
+func jiraEnabled() bool {
+	return viper.GetString("jira_url") != ""
+}
+
+// withJiraTicket checks the ticket of the branch, or one referenced by the
+// footers already, in Jira and adds it as jira_footer. Interactively its
+// summary is suggested as short description, and a ticket is asked for when
+// there is none or Jira doesn't know it; otherwise a missing ticket fails
+// with jira_required, and an unknown one always.
+func withJiraTicket(data CommitPromptData, interactive bool) (CommitPromptData, error) {
+	if !jiraEnabled() {
+		return data, nil
+	}
+	required := viper.GetBool("jira_required")
+	key := branchTicket()
+	if key == "" {
+		key = footerJiraKey(data.Footers)
+	}
+
+	var issue jiraIssue
+	for {
+		if key == "" && interactive {
+			label := tr("Jira ticket (optional)")
+			if required {
+				label = tr("Jira ticket")
+			}
+			answer, err := ui.Input(label, "")
+			if err != nil {
+				return data, err
+			}
+			key = strings.ToUpper(strings.TrimSpace(answer))
+		}
+		if key == "" {
+			if !required {
+				return data, nil
+			}
+			if !interactive {
+				return data, exitcode.New(exitcode.Invalid, errors.New(tr("a Jira ticket is required, name the branch after it or add it as footer")))
+			}
+			pterm.Error.Println(tr("A Jira ticket is required"))
+			continue
+		}
+		if jiraKeyPattern.FindString(key) != key {
+			if !interactive {
+				return data, exitcode.New(exitcode.Invalid, errors.New(tr("%s is not a Jira ticket key such as PROJ-123", key)))
+			}
+			pterm.Error.Println(tr("%s is not a Jira ticket key such as PROJ-123", key))
+			key = ""
+			continue
+		}
+
+		var err error
+		issue, err = fetchJiraIssue(key)
+		var rejected *apiError
+		if errors.As(err, &rejected) && rejected.code == http.StatusNotFound {
+			if !interactive {
+				return data, exitcode.New(exitcode.Invalid, errors.New(tr("%s doesn't exist in Jira", key)))
+			}
+			pterm.Error.Println(tr("%s doesn't exist in Jira", key))
+			key = ""
+			continue
+		}
+		if err != nil {
+			// an unreachable Jira doesn't block committing
+			pterm.Warning.Println(tr("Failed to check %s in Jira:", key), err)
+			issue.Key = key
+		}
+		break
+	}
+
+	footer, err := jiraFooter(issue)
+	if err != nil {
+		return data, err
+	}
+	data.Footers = setTrailer(append([]trailer{}, data.Footers...), footer, false)
+	if interactive && data.ShortDescription == "" && issue.Fields.Summary != "" {
+		data.ShortDescription = conventional.FixDescription(issue.Fields.Summary, commitRules())
+	}
+	return data, nil
+}
+
+// footerJiraKey returns the first Jira key the footers reference, e.g. of an
+// amended commit or a draft
+func footerJiraKey(footers []trailer) string {
+	for _, f := range footers {
+		if key := jiraKeyPattern.FindString(f.Value); key != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+// jiraFooter returns the footer jira_footer makes of issue, whose {key},
+// {summary} and {url} placeholders are replaced
+func jiraFooter(issue jiraIssue) (trailer, error) {
+	browse := strings.TrimRight(viper.GetString("jira_url"), "/") + "/browse/" + issue.Key
+	footer := strings.NewReplacer("{key}", issue.Key, "{summary}", issue.Fields.Summary, "{url}", browse).Replace(viper.GetString("jira_footer"))
+	t, err := parseTrailerArg(footer)
+	if err != nil {
+		return trailer{}, exitcode.New(exitcode.Config, fmt.Errorf("invalid jira_footer: %w", err))
+	}
+	return t, nil
+}
+
+// fetchJiraIssue fetches the summary of the issue key from the REST API of
+// jira_url, cached for api_cache_ttl
+func fetchJiraIssue(key string) (jiraIssue, error) {
+	base := strings.TrimRight(viper.GetString("jira_url"), "/")
+	body, err := apiGetAuthorized(base+"/rest/api/2/issue/"+url.PathEscape(key)+"?fields=summary", jiraAuthorization(base))
+	if err != nil {
+		return jiraIssue{}, err
+	}
+	var issue jiraIssue
+	if err := json.Unmarshal(body, &issue); err != nil {
+		return jiraIssue{}, fmt.Errorf("reading %s: %w", key, err)
+	}
+	return issue, nil
+}
+
+// jiraAuthorization returns the Authorization header for the Jira at base.
+// Jira Cloud takes the API token together with jira_email, Jira Server and
+// Data Center a personal access token. The token is jira_token for the
+// jira_url of the global config, so a repository can't redirect it, or else
+// the one git's credential helpers store for the host.
+func jiraAuthorization(base string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	token := jiraToken
+	if global, err := url.Parse(jiraTokenURL); err != nil || token == "" || global.Host != u.Host {
+		token = gitCredential(u.Host)
+	}
+	if token == "" {
+		return ""
+	}
+	if email := viper.GetString("jira_email"); email != "" {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(email+":"+token))
+	}
+	return "Bearer " + token
+}
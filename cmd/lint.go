@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/conventional"
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint <file> | --range <revisions> | --pr-title <title>",
+	Short: "Validate a commit message file, for use as a commit-msg hook",
+	Long: `Validate a commit message file against the Conventional Commits spec and the
+configured types and scopes. Exits non-zero and explains what's wrong when the
+message is invalid, so git-cc can be used as a commit-msg hook and enforce the
+convention for commits made outside of the interactive prompt.
+
+Comment lines are ignored, as are messages generated by git for merges,
+reverts and fixups. Use - to read the message from stdin.
+
+With --range the messages of all commits in a revision range are validated
+instead, as CI does for the commits of a pull request. Repositories squash
+merging pull requests validate their titles with --pr-title, or in GitHub
+Actions with --github-event, reading the title from the event payload.`,
+	Example: `  # .git/hooks/commit-msg
+  exec git cc lint "$1"
+
+  git cc lint --range origin/main..HEAD
+  git cc lint --pr-title "feat: add login"`,
+	Annotations: readOnlyCommand,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("range") || cmd.Flags().Changed("pr-title") || cmd.Flags().Changed("github-event") {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: lint,
+}
+
+func init() {
+	lintCmd.Flags().String("range", "", "Validate the messages of the commits in this revision `range` instead of a file")
+	lintCmd.Flags().String("pr-title", "", "Validate this pull request `title` instead of a file")
+	lintCmd.Flags().Bool("github-event", false, "Validate the title of the pull request of the GitHub Actions event")
+	lintCmd.MarkFlagsMutuallyExclusive("range", "pr-title", "github-event")
+	rootCmd.AddCommand(lintCmd)
+}
+
+func lint(cmd *cobra.Command, args []string) {
+	if revisions, _ := cmd.Flags().GetString("range"); cmd.Flags().Changed("range") {
+		lintRange(revisions)
+		return
+	}
+	if title, _ := cmd.Flags().GetString("pr-title"); cmd.Flags().Changed("pr-title") {
+		lintTitle(title)
+		return
+	}
+	if event, _ := cmd.Flags().GetBool("github-event"); event {
+		title, err := githubEventTitle()
+		if err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Failure)
+		}
+		lintTitle(title)
+		return
+	}
+
+	var content []byte
+	var err error
+	if args[0] == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		pterm.Error.Println("Failed to read commit message:", err)
+		exit(exitcode.Failure)
+	}
+
+	message := stripComments(string(content))
+	if ignoredMessage(message) {
+		pterm.Debug.Println("Skipping message generated by git")
+		return
+	}
+
+	problems := validateCommitMessage(message)
+	if len(problems) == 0 {
+		return
+	}
+
+	pterm.Error.Println("commit message does not follow the Conventional Commits spec")
+	printProblems(message, problems)
+	exit(exitcode.Invalid)
+}
+
+// lintRange validates the messages of the commits in a revision range
+func lintRange(revisions string) {
+	out, err := gitOutput("rev-list", "--reverse", revisions)
+	if err != nil {
+		pterm.Error.Printfln("Failed to list the commits of %s: %s", revisions, err)
+		exit(exitcode.Failure)
+	}
+
+	var invalid []string
+	table := pterm.TableData{{"Commit", "Subject", "Rule", "Problem", "Fix"}}
+	hashes := strings.Fields(out)
+	for _, hash := range hashes {
+		c, err := repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			pterm.Error.Printfln("Failed to read commit %s: %s", hash, err)
+			exit(exitcode.Failure)
+		}
+
+		message := strings.TrimSpace(c.Message)
+		if ignoredMessage(message) {
+			continue
+		}
+		if bot := dependencyBotOf(c); bot != nil {
+			pterm.Debug.Printfln("Skipping %s by %s", hash[:7], bot.Name)
+			continue
+		}
+		problems := validateCommitMessage(message)
+		if len(problems) == 0 {
+			continue
+		}
+		invalid = append(invalid, hash)
+		subject, _, _ := strings.Cut(message, "\n")
+		for i, problem := range problems {
+			// the commit is named on its first row only
+			fix := problem.Hint
+			if url := ruleURL(problem.Rule); url != "" {
+				fix += " (" + url + ")"
+			}
+			row := []string{"", "", problem.Rule, problem.String(), fix}
+			if i == 0 {
+				row[0], row[1] = hash[:7], subject
+			}
+			table = append(table, row)
+		}
+	}
+
+	if len(invalid) > 0 {
+		pterm.DefaultTable.WithHasHeader().WithData(table).Render()
+		printExpected()
+		pterm.Error.Printfln("%d of %d commits in %s are invalid", len(invalid), len(hashes), revisions)
+		exit(exitcode.Invalid)
+	}
+}
+
+// lintTitle validates a pull request title, which becomes the header of the
+// squashed commit
+func lintTitle(title string) {
+	title = strings.TrimSpace(title)
+	if problems := validateCommitMessage(title); len(problems) > 0 {
+		pterm.Error.Println("pull request title does not follow the Conventional Commits spec")
+		printProblems(title, problems)
+		exit(exitcode.Invalid)
+	}
+}
+
+// githubEventTitle reads the pull request title from the payload of the
+// GitHub Actions event that triggered the workflow
+func githubEventTitle() (string, error) {
+	path := os.Getenv("GITHUB_EVENT_PATH")
+	if path == "" {
+		return "", fmt.Errorf("GITHUB_EVENT_PATH is not set, --github-event only works in GitHub Actions")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var event struct {
+		PullRequest *struct {
+			Title string `json:"title"`
+		} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return "", fmt.Errorf("reading %s: %w", path, err)
+	}
+	if event.PullRequest == nil {
+		return "", fmt.Errorf("the %s event has no pull request", os.Getenv("GITHUB_EVENT_NAME"))
+	}
+	return event.PullRequest.Title, nil
+}
+
+// printProblems explains the problems of message on stderr, pointing at
+// the offending part of each line
+func printProblems(message string, problems []conventional.Problem) {
+	// --quiet reports them in its line
+	if quiet {
+		for _, problem := range problems {
+			quietErrors = append(quietErrors, problemTitle(problem))
+		}
+		return
+	}
+	writeProblems(os.Stderr, message, problems)
+	printExpected()
+}
+
+// writeProblems explains the problems of message to w
+func writeProblems(w io.Writer, message string, problems []conventional.Problem) {
+	lines := strings.Split(message, "\n")
+	for _, problem := range problems {
+		fmt.Fprintf(w, "  %d:%d: %s\n", problem.Line+1, problem.Column+1, problemTitle(problem))
+		if problem.Line < len(lines) {
+			fmt.Fprintf(w, "      %s\n", lines[problem.Line])
+			fmt.Fprintf(w, "      %s%s\n", strings.Repeat(" ", problem.Column), strings.Repeat("^", max(problem.EndColumn-problem.Column, 1)))
+		}
+		for _, line := range problemHelp(problem) {
+			fmt.Fprintf(w, "      %s\n", line)
+		}
+	}
+}
+
+// problemTitle returns the message of problem with the ID of its rule
+func problemTitle(problem conventional.Problem) string {
+	if problem.Rule == "" {
+		return problem.Message
+	}
+	return problem.Message + " [" + problem.Rule + "]"
+}
+
+// problemHelp returns how to fix problem and the page of rules_url about
+// its rule, if any
+func problemHelp(problem conventional.Problem) []string {
+	var help []string
+	if problem.Hint != "" {
+		help = append(help, "fix: "+problem.Hint)
+	}
+	if url := ruleURL(problem.Rule); url != "" {
+		help = append(help, "see: "+url)
+	}
+	return help
+}
+
+// ruleURL returns the page of the team's conventions at rules_url about
+// rule, with {rule} replaced by its ID. URLs without {rule} are linked once
+// by printExpected instead.
+func ruleURL(rule string) string {
+	url := viper.GetString("rules_url")
+	if rule == "" || !strings.Contains(url, "{rule}") {
+		return ""
+	}
+	return strings.ReplaceAll(url, "{rule}", rule)
+}
+
+// printExpected explains the header format and the allowed types and scopes
+// on stderr
+func printExpected() {
+	fmt.Fprintf(os.Stderr, "\nexpected: type(scope): description, with type one of %s\n", strings.Join(commitTypes, ", "))
+	if len(scopes) > 0 {
+		fmt.Fprintf(os.Stderr, "and scope one of %s\n", strings.Join(scopes, ", "))
+	}
+	fmt.Fprintln(os.Stderr, "run git cc examples for messages which pass")
+	if url := viper.GetString("rules_url"); url != "" && !strings.Contains(url, "{rule}") {
+		fmt.Fprintln(os.Stderr, "see", url, "for the commit conventions")
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/45413/git-cc/pkg/conventional"
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pterm/pterm"
+)
+
+// lintRequest asks to validate a message, given directly or as the revision
+// of a commit in the repository
+type lintRequest struct {
+	ID      any    `json:"id,omitempty"`
+	Message string `json:"message,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+}
+
+type lintResult struct {
+	ID       any                    `json:"id,omitempty"`
+	Commit   string                 `json:"commit,omitempty"`
+	Valid    bool                   `json:"valid"`
+	Ignored  bool                   `json:"ignored,omitempty"`
+	Problems []conventional.Problem `json:"problems"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// lintMessage validates the message of req exactly like git cc lint does
+func lintMessage(req lintRequest) lintResult {
+	result := lintResult{ID: req.ID, Problems: []conventional.Problem{}}
+
+	message := req.Message
+	if req.Commit != "" {
+		hash, err := repo.ResolveRevision(plumbing.Revision(req.Commit))
+		if err != nil {
+			result.Error = "unknown revision " + req.Commit + ": " + err.Error()
+			return result
+		}
+		c, err := repo.CommitObject(*hash)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.Commit = hash.String()
+		message = c.Message
+	}
+
+	message = stripComments(message)
+	if ignoredMessage(message) {
+		result.Valid, result.Ignored = true, true
+		return result
+	}
+
+	if problems := validateCommitMessage(message); len(problems) > 0 {
+		result.Problems = problems
+	}
+	result.Valid = len(result.Problems) == 0
+	return result
+}
+
+// serveLint answers newline delimited JSON lint requests from in with one
+// JSON result per line, so bots can validate messages at scale without
+// starting a process per message
+func serveLint(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	// messages may be long, allow up to 1MB per request
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var req lintRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			if err := encoder.Encode(lintResult{Problems: []conventional.Problem{}, Error: "invalid request: " + err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := encoder.Encode(lintMessage(req)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// serveLintStdio runs serveLint on stdin and stdout
+func serveLintStdio() {
+	if err := serveLint(os.Stdin, os.Stdout); err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// isoDate is the ISO 8601 date layout of machine-readable output
+const isoDate = "2006-01-02"
+
+// englishDates are the numeric date layouts of English by region, US
+// English for the others
+var englishDates = map[string]string{
+	"GB": "02/01/2006",
+	"AU": "02/01/2006",
+	"NZ": "02/01/2006",
+	"IN": "02/01/2006",
+	"IE": "02/01/2006",
+	"ZA": "2006/01/02",
+	"CA": isoDate,
+}
+
+// languageDates are the numeric date layouts by language
+var languageDates = map[string]string{
+	"en": "01/02/2006",
+	"de": "02.01.2006",
+	"ru": "02.01.2006",
+	"pl": "02.01.2006",
+	"cs": "02.01.2006",
+	"fi": "02.01.2006",
+	"nb": "02.01.2006",
+	"da": "02.01.2006",
+	"tr": "02.01.2006",
+	"uk": "02.01.2006",
+	"fr": "02/01/2006",
+	"es": "02/01/2006",
+	"it": "02/01/2006",
+	"pt": "02/01/2006",
+	"el": "02/01/2006",
+	"nl": "02-01-2006",
+	"ja": "2006/01/02",
+	"zh": "2006/01/02",
+	"ko": "2006.01.02",
+	"hu": "2006.01.02",
+	"sv": isoDate,
+	"lt": isoDate,
+}
+
+// posixLocale returns the locale of the category, such as LC_TIME, as tag.
+// The locale config property wins over LC_ALL, the category and LANG; C and
+// POSIX, like no locale at all, are English.
+func posixLocale(category string) language.Tag {
+	names := []string{os.Getenv("LC_ALL"), os.Getenv(category), os.Getenv("LANG")}
+	if configured := viper.GetString("locale"); configured != "" {
+		names = []string{configured}
+	}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		// de_DE.UTF-8@euro is de-DE
+		name, _, _ = strings.Cut(name, ".")
+		name, _, _ = strings.Cut(name, "@")
+		if name == "C" || name == "POSIX" {
+			break
+		}
+		tag, err := language.Parse(strings.ReplaceAll(name, "_", "-"))
+		if err != nil {
+			pterm.Debug.Printfln("Ignoring the locale %q: %s", name, err)
+			continue
+		}
+		return tag
+	}
+	return language.AmericanEnglish
+}
+
+// formatNumber formats n with the digit grouping of LC_NUMERIC
+func formatNumber(n int) string {
+	return message.NewPrinter(posixLocale("LC_NUMERIC")).Sprintf("%d", n)
+}
+
+// formatDate formats t as date_format configures: iso, a Go layout such as
+// "Jan 2 2006" or, by default, the numeric date of LC_TIME
+func formatDate(t time.Time) string {
+	switch layout := viper.GetString("date_format"); layout {
+	case "iso":
+		return t.Format(isoDate)
+	case "":
+		tag := posixLocale("LC_TIME")
+		base, _ := tag.Base()
+		region, _ := tag.Region()
+		if layout, ok := englishDates[region.String()]; ok && base.String() == "en" {
+			return t.Format(layout)
+		}
+		if layout, ok := languageDates[base.String()]; ok {
+			return t.Format(layout)
+		}
+		return t.Format(isoDate)
+	default:
+		return t.Format(layout)
+	}
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// typeBadges are the styles of the type badges, other types are gray
+var typeBadges = map[string]*pterm.Style{
+	"feat":     pterm.NewStyle(pterm.BgGreen, pterm.FgBlack),
+	"fix":      pterm.NewStyle(pterm.BgRed, pterm.FgWhite),
+	"perf":     pterm.NewStyle(pterm.BgMagenta, pterm.FgWhite),
+	"refactor": pterm.NewStyle(pterm.BgBlue, pterm.FgWhite),
+	"docs":     pterm.NewStyle(pterm.BgCyan, pterm.FgBlack),
+	"test":     pterm.NewStyle(pterm.BgYellow, pterm.FgBlack),
+	"revert":   pterm.NewStyle(pterm.BgLightRed, pterm.FgBlack),
+	"security": pterm.NewStyle(pterm.BgLightRed, pterm.FgBlack),
+}
+
+var logCmd = &cobra.Command{
+	Use:   "log [<revision range>]",
+	Short: "Browse the conventional commits of the history",
+	Long: `List the commits of the history with their type as a colored badge, the
+scope and a marker for breaking changes, newest first. The revision range
+is given as to git log and defaults to HEAD, merges are left out.
+
+--type, --scope and --breaking filter the list, a commit has to match all
+of them; commits which aren't conventional are listed grayed out unless
+one is given.`,
+	Example: `  git cc log
+  git cc log v1.2.0..HEAD --type feat,fix
+  git cc log --scope api --breaking`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.MaximumNArgs(1),
+	Run:         logCommits,
+}
+
+func init() {
+	logCmd.Flags().StringSlice("type", nil, "Only list commits of these types")
+	logCmd.Flags().StringSlice("scope", nil, "Only list commits of these scopes")
+	logCmd.Flags().Bool("breaking", false, "Only list breaking changes")
+	logCmd.Flags().IntP("max-count", "n", 0, "List at most this many commits")
+	registerTypeCompletion(logCmd, "type", "scope")
+	rootCmd.AddCommand(logCmd)
+}
+
+func logCommits(cmd *cobra.Command, args []string) {
+	types, _ := cmd.Flags().GetStringSlice("type")
+	scopeFilter, _ := cmd.Flags().GetStringSlice("scope")
+	breakingOnly, _ := cmd.Flags().GetBool("breaking")
+	maxCount, _ := cmd.Flags().GetInt("max-count")
+
+	revisions := "HEAD"
+	if len(args) == 1 {
+		revisions = args[0]
+	}
+	out, err := gitOutput("log", "--format=%H", "--no-merges", revisions, "--")
+	if err != nil {
+		pterm.Error.Println("Failed to read history:", err)
+		exit(exitcode.Failure)
+	}
+
+	filtered := len(types) > 0 || len(scopeFilter) > 0 || breakingOnly
+	listed := 0
+	for _, hash := range strings.Fields(out) {
+		if maxCount > 0 && listed == maxCount {
+			break
+		}
+		commit, err := repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			pterm.Error.Println("Failed to read commit:", err)
+			exit(exitcode.Failure)
+		}
+		c := parseHistoryCommit(commit)
+		conventional := c.Valid || c.DependencyBot != ""
+		if filtered && (!conventional ||
+			len(types) > 0 && !slices.Contains(types, c.Data.Type) ||
+			len(scopeFilter) > 0 && !slices.Contains(scopeFilter, c.Data.Scope) ||
+			breakingOnly && !c.Data.BreakingChange) {
+			continue
+		}
+		pterm.Println(logLine(c))
+		listed++
+	}
+	if listed == 0 && filtered {
+		pterm.Info.Println("No commits match the filters")
+	}
+}
+
+// logLine renders c on one line, as hash, badge, scope and description
+// followed by author and date
+func logLine(c conventionalCommit) string {
+	hash := pterm.Yellow(c.Commit.Hash.String()[:7])
+	meta := pterm.Gray(fmt.Sprintf("%s, %s", c.Commit.Author.Name, formatDate(c.Commit.Author.When)))
+	if c.DependencyBot != "" {
+		meta = pterm.Gray(c.DependencyBot + ", " + formatDate(c.Commit.Author.When))
+	}
+	if !c.Valid && c.DependencyBot == "" {
+		subject, _, _ := strings.Cut(strings.TrimSpace(c.Commit.Message), "\n")
+		return hash + " " + pterm.Gray(subject) + "  " + meta
+	}
+
+	badge, ok := typeBadges[c.Data.Type]
+	if !ok {
+		badge = pterm.NewStyle(pterm.BgGray, pterm.FgWhite)
+	}
+	line := hash + " " + badge.Sprint(" "+c.Data.Type+" ")
+	if c.Data.Scope != "" {
+		line += " " + pterm.Cyan(c.Data.Scope)
+	}
+	if c.Data.BreakingChange {
+		line += " " + pterm.NewStyle(pterm.FgRed, pterm.Bold).Sprint("BREAKING")
+	}
+	return line + " " + c.Data.ShortDescription + "  " + meta
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// url "…" of Homebrew formulae and "url": "…" of Scoop manifests
+	manifestURLPattern = regexp.MustCompile(`\burl"?\s*:?\s*"([^"]+)"`)
+	// sha256 "…" and "hash": "…", which follow the url they belong to
+	manifestChecksumPattern = regexp.MustCompile(`\b(?:sha256|hash)"?\s*:?\s*"(?:sha256:)?([0-9a-fA-F]{64})"`)
+)
+
+// updateManifest returns a Homebrew formula or Scoop manifest with previous
+// replaced by version and the checksum following each URL replaced by the
+// one of the artifact of the same file name in sums
+func updateManifest(content, previous, version string, sums map[string]string) (string, error) {
+	if previous != "" {
+		content = strings.ReplaceAll(content, previous, version)
+	}
+	lines := strings.Split(content, "\n")
+	artifact := ""
+	for i, line := range lines {
+		if match := manifestURLPattern.FindStringSubmatch(line); match != nil {
+			artifact = path.Base(match[1])
+		}
+		match := manifestChecksumPattern.FindStringSubmatchIndex(line)
+		if match == nil || artifact == "" {
+			continue
+		}
+		sum, ok := sums[artifact]
+		if !ok {
+			return "", fmt.Errorf("no release artifact %s to take the checksum of", artifact)
+		}
+		lines[i] = line[:match[2]] + sum + line[match[3]:]
+		artifact = ""
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// artifactChecksums returns the SHA-256 checksums of the files matching the
+// glob pattern by file name
+func artifactChecksums(pattern string) (map[string]string, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(gitRoot, pattern)
+	}
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sums := map[string]string{}
+	for _, file := range files {
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			continue
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		sums[filepath.Base(file)] = hex.EncodeToString(h.Sum(nil))
+	}
+	return sums, nil
+}
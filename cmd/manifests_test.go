@@ -0,0 +1,46 @@
+package cmd
+
+import "testing"
+
+func TestUpdateManifest(t *testing.T) {
+	sums := map[string]string{
+		"tool_1.1.0_darwin_arm64.tar.gz": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"tool_1.1.0_windows_amd64.zip":   "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+	}
+	const old = "0000000000000000000000000000000000000000000000000000000000000000"
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		err     bool
+	}{
+		{
+			name:    "Homebrew formula",
+			content: "  version \"1.0.0\"\n  url \"https://example.com/v1.0.0/tool_1.0.0_darwin_arm64.tar.gz\"\n  sha256 \"" + old + "\"\n",
+			want:    "  version \"1.1.0\"\n  url \"https://example.com/v1.1.0/tool_1.1.0_darwin_arm64.tar.gz\"\n  sha256 \"" + sums["tool_1.1.0_darwin_arm64.tar.gz"] + "\"\n",
+		},
+		{
+			name:    "Scoop manifest",
+			content: "{\n  \"version\": \"1.0.0\",\n  \"url\": \"https://example.com/v1.0.0/tool_1.0.0_windows_amd64.zip\",\n  \"hash\": \"sha256:" + old + "\"\n}\n",
+			want:    "{\n  \"version\": \"1.1.0\",\n  \"url\": \"https://example.com/v1.1.0/tool_1.1.0_windows_amd64.zip\",\n  \"hash\": \"sha256:" + sums["tool_1.1.0_windows_amd64.zip"] + "\"\n}\n",
+		},
+		{
+			name:    "checksums without a URL are kept",
+			content: "  version \"1.0.0\"\n  sha256 \"" + old + "\"\n",
+			want:    "  version \"1.1.0\"\n  sha256 \"" + old + "\"\n",
+		},
+		{
+			name:    "missing artifact",
+			content: "  url \"https://example.com/v1.0.0/tool_1.0.0_linux_amd64.tar.gz\"\n  sha256 \"" + old + "\"\n",
+			err:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := updateManifest(tt.content, "1.0.0", "1.1.0", sums)
+			if (err != nil) != tt.err || got != tt.want {
+				t.Errorf("updateManifest() = %q, %v, want %q, error %v", got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,413 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// mergeDriver is the name the changelog merge driver is configured under
+const mergeDriver = "git-cc-changelog"
+
+// mergeAttributes assigns the merge driver to the changelogs, in
+// .git/info/attributes
+const mergeAttributes = hookMarker + "\nCHANGELOG.md merge=" + mergeDriver + "\n"
+
+var mergeChangelogCmd = &cobra.Command{
+	Use:   "merge-changelog <base> <current> <other> [<path>]",
+	Short: "Merge two versions of a changelog release by release",
+	Long: `Merge the changes made to a changelog on two branches, as the git merge
+driver installed by git cc install-hooks --merge-driver does for
+CHANGELOG.md. The merged changelog is written to <current>.
+
+Releases are matched by their heading and the entries of their sections
+combined, so entries added on both sides are all kept, entries removed on
+one side are removed. Only headings and text changed on both sides are left
+as conflicts, marked as git does, and git cc exits with 1.`,
+	Example: `  # .git/config
+  [merge "git-cc-changelog"]
+  	driver = git cc merge-changelog %O %A %B %P`,
+	Args: cobra.RangeArgs(3, 4),
+	Run:  mergeChangelogFiles,
+}
+
+func init() {
+	rootCmd.AddCommand(mergeChangelogCmd)
+}
+
+func mergeChangelogFiles(cmd *cobra.Command, args []string) {
+	var versions [3]string
+	for i, path := range args[:3] {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			pterm.Error.Println("Failed to read changelog:", err)
+			exit(exitcode.Failure)
+		}
+		versions[i] = string(content)
+	}
+	merged, conflicts := mergeChangelogVersions(versions[0], versions[1], versions[2])
+	if err := os.WriteFile(args[1], []byte(merged), 0o644); err != nil {
+		pterm.Error.Println("Failed to write changelog:", err)
+		exit(exitcode.Failure)
+	}
+	if conflicts > 0 {
+		name := args[1]
+		if len(args) == 4 {
+			name = args[3]
+		}
+		pterm.Warning.Printfln("Conflicts left in %s", name)
+		exit(exitcode.Failure)
+	}
+}
+
+// changelogRelease is a release of a changelog: its heading, the text
+// below it and its sections
+type changelogRelease struct {
+	Heading  string
+	Text     string
+	Sections []changelogGroup
+}
+
+// changelogGroup is a ### section of a release with its entries, list
+// items with their continuation lines, and any other text
+type changelogGroup struct {
+	Heading string
+	Text    string
+	Entries []string
+}
+
+// name is the release without its date, which identifies it
+func (r changelogRelease) name() string {
+	name, _, _ := strings.Cut(r.Heading, " - ")
+	return name
+}
+
+func (r changelogRelease) section(heading string) (changelogGroup, bool) {
+	i := slices.IndexFunc(r.Sections, func(g changelogGroup) bool { return g.Heading == heading })
+	if i < 0 {
+		return changelogGroup{}, false
+	}
+	return r.Sections[i], true
+}
+
+func (r changelogRelease) String() string {
+	var md strings.Builder
+	md.WriteString(r.Heading + "\n")
+	if r.Text != "" {
+		md.WriteString("\n" + r.Text + "\n")
+	}
+	for _, g := range r.Sections {
+		if g.Heading != "" {
+			md.WriteString("\n" + g.Heading + "\n")
+		}
+		if g.Text != "" {
+			md.WriteString("\n" + g.Text + "\n")
+		}
+		if len(g.Entries) > 0 {
+			md.WriteString("\n" + strings.Join(g.Entries, "\n") + "\n")
+		}
+	}
+	return md.String()
+}
+
+// parseChangelog splits a changelog into the text before the first
+// release and the releases
+func parseChangelog(content string) (string, []changelogRelease) {
+	var preamble []string
+	var releases []changelogRelease
+	var text []string
+	// continuation lines follow an entry directly
+	inEntry := false
+	// text collects the lines which aren't entries until the next heading
+	flush := func() string {
+		joined := strings.TrimSpace(strings.Join(text, "\n"))
+		text = nil
+		return joined
+	}
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "## "):
+			if len(releases) == 0 {
+				preamble = text
+				text = nil
+			} else {
+				finishChangelogBlock(&releases[len(releases)-1], flush())
+			}
+			releases = append(releases, changelogRelease{Heading: line})
+		case len(releases) == 0:
+			text = append(text, line)
+		case strings.HasPrefix(line, "### "):
+			release := &releases[len(releases)-1]
+			finishChangelogBlock(release, flush())
+			release.Sections = append(release.Sections, changelogGroup{Heading: line})
+		case strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* "):
+			release := &releases[len(releases)-1]
+			if len(release.Sections) == 0 {
+				// entries of a release without sections
+				release.Sections = append(release.Sections, changelogGroup{})
+			}
+			group := &release.Sections[len(release.Sections)-1]
+			group.Entries = append(group.Entries, line)
+		case inEntry && strings.HasPrefix(line, "  ") && strings.TrimSpace(line) != "":
+			*lastEntry(releases) += "\n" + line
+			continue
+		default:
+			text = append(text, line)
+		}
+		inEntry = len(releases) > 0 && (strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* "))
+	}
+	if len(releases) == 0 {
+		return strings.Join(text, "\n"), nil
+	}
+	finishChangelogBlock(&releases[len(releases)-1], flush())
+	return strings.TrimRight(strings.Join(preamble, "\n"), "\n"), releases
+}
+
+// finishChangelogBlock stores the text collected since the last heading
+// in the release or its last section
+func finishChangelogBlock(release *changelogRelease, text string) {
+	if len(release.Sections) == 0 {
+		release.Text = text
+		return
+	}
+	group := &release.Sections[len(release.Sections)-1]
+	group.Text = strings.TrimSpace(group.Text + "\n\n" + text)
+}
+
+// lastEntry returns the entry continuation lines belong to
+func lastEntry(releases []changelogRelease) *string {
+	sections := releases[len(releases)-1].Sections
+	entries := sections[len(sections)-1].Entries
+	return &entries[len(entries)-1]
+}
+
+// mergeChangelogVersions merges the changes from base to ours and to theirs
+// and returns the result with the number of conflicts in it
+func mergeChangelogVersions(base, ours, theirs string) (string, int) {
+	if ours == theirs || theirs == base {
+		return ours, 0
+	}
+	if ours == base {
+		return theirs, 0
+	}
+	basePreamble, baseReleases := parseChangelog(base)
+	ourPreamble, ourReleases := parseChangelog(ours)
+	theirPreamble, theirReleases := parseChangelog(theirs)
+	if ourReleases == nil || theirReleases == nil {
+		return mergeText(base, ours, theirs)
+	}
+
+	conflicts := 0
+	preamble, conflict := mergeText(basePreamble, ourPreamble, theirPreamble)
+	conflicts += conflict
+	blocks := []string{preamble}
+	for _, name := range mergeOrder(releaseNames(ourReleases), releaseNames(theirReleases)) {
+		baseRelease, inBase := findRelease(baseReleases, name)
+		ourRelease, inOurs := findRelease(ourReleases, name)
+		theirRelease, inTheirs := findRelease(theirReleases, name)
+		switch {
+		case inOurs && inTheirs:
+			release, conflict := mergeRelease(baseRelease, ourRelease, theirRelease)
+			conflicts += conflict
+			blocks = append(blocks, release)
+		case inBase && (inOurs && ourRelease.String() == baseRelease.String() || inTheirs && theirRelease.String() == baseRelease.String()):
+			// removed on one side and unchanged on the other
+		case inBase:
+			// removed on one side and changed on the other
+			text, conflict := mergeText(baseRelease.String(), optionalRelease(ourRelease, inOurs), optionalRelease(theirRelease, inTheirs))
+			conflicts += conflict
+			blocks = append(blocks, text)
+		case inOurs:
+			blocks = append(blocks, ourRelease.String())
+		default:
+			blocks = append(blocks, theirRelease.String())
+		}
+	}
+	merged := ""
+	for _, block := range blocks {
+		if block = strings.Trim(block, "\n"); block != "" {
+			merged += block + "\n\n"
+		}
+	}
+	return strings.TrimRight(merged, "\n") + "\n", conflicts
+}
+
+// mergeRelease merges a release changed on both sides, section by section
+func mergeRelease(base, ours, theirs changelogRelease) (string, int) {
+	conflicts := 0
+	merged := changelogRelease{}
+	var conflict int
+	merged.Heading, conflict = mergeText(base.Heading, ours.Heading, theirs.Heading)
+	conflicts += conflict
+	merged.Text, conflict = mergeText(base.Text, ours.Text, theirs.Text)
+	conflicts += conflict
+
+	var headings []string
+	for _, g := range ours.Sections {
+		headings = append(headings, g.Heading)
+	}
+	var theirHeadings []string
+	for _, g := range theirs.Sections {
+		theirHeadings = append(theirHeadings, g.Heading)
+	}
+	for _, heading := range mergeOrder(headings, theirHeadings) {
+		baseGroup, _ := base.section(heading)
+		ourGroup, _ := ours.section(heading)
+		theirGroup, _ := theirs.section(heading)
+		group := changelogGroup{Heading: heading}
+		group.Text, conflict = mergeText(baseGroup.Text, ourGroup.Text, theirGroup.Text)
+		conflicts += conflict
+		group.Entries = mergeEntries(baseGroup.Entries, ourGroup.Entries, theirGroup.Entries)
+		if group.Text != "" || len(group.Entries) > 0 {
+			merged.Sections = append(merged.Sections, group)
+		}
+	}
+	return merged.String(), conflicts
+}
+
+// mergeEntries keeps our entries except those they removed and adds their
+// new entries after the entry preceding them on their side
+func mergeEntries(base, ours, theirs []string) []string {
+	merged := slices.DeleteFunc(slices.Clone(ours), func(entry string) bool {
+		return slices.Contains(base, entry) && !slices.Contains(theirs, entry)
+	})
+	at := 0
+	for _, entry := range theirs {
+		if i := slices.Index(merged, entry); i >= 0 {
+			at = i + 1
+			continue
+		}
+		if slices.Contains(base, entry) {
+			// removed by us
+			continue
+		}
+		merged = slices.Insert(merged, at, entry)
+		at++
+	}
+	return merged
+}
+
+// mergeOrder returns the names of ours followed by those only in theirs,
+// each inserted before the name following it in theirs
+func mergeOrder(ours, theirs []string) []string {
+	merged := slices.Clone(ours)
+	for i := len(theirs) - 1; i >= 0; i-- {
+		if slices.Contains(merged, theirs[i]) {
+			continue
+		}
+		at := len(merged)
+		for _, next := range theirs[i+1:] {
+			if j := slices.Index(merged, next); j >= 0 {
+				at = j
+				break
+			}
+		}
+		merged = slices.Insert(merged, at, theirs[i])
+	}
+	return merged
+}
+
+// mergeText merges a text changed on both sides as a whole, the changes of
+// either side are taken and different changes are a conflict
+func mergeText(base, ours, theirs string) (string, int) {
+	switch {
+	case ours == theirs, theirs == base:
+		return ours, 0
+	case ours == base:
+		return theirs, 0
+	}
+	return fmt.Sprintf("<<<<<<< ours\n%s\n=======\n%s\n>>>>>>> theirs", strings.Trim(ours, "\n"), strings.Trim(theirs, "\n")), 1
+}
+
+func releaseNames(releases []changelogRelease) []string {
+	var names []string
+	for _, r := range releases {
+		names = append(names, r.name())
+	}
+	return names
+}
+
+func findRelease(releases []changelogRelease, name string) (changelogRelease, bool) {
+	i := slices.IndexFunc(releases, func(r changelogRelease) bool { return r.name() == name })
+	if i < 0 {
+		return changelogRelease{}, false
+	}
+	return releases[i], true
+}
+
+// optionalRelease renders release, an empty text if it was removed
+func optionalRelease(release changelogRelease, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return release.String()
+}
+
+// installMergeDriver configures the changelog merge driver in the git
+// config of the repository and assigns it to CHANGELOG.md
+func installMergeDriver() error {
+	if _, err := gitOutput("config", "merge."+mergeDriver+".name", "git-cc changelog merge"); err != nil {
+		return err
+	}
+	if _, err := gitOutput("config", "merge."+mergeDriver+".driver", "git cc merge-changelog %O %A %B %P"); err != nil {
+		return err
+	}
+	path, err := attributesPath()
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if !strings.Contains(string(existing), mergeAttributes) {
+		if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+			existing = append(existing, '\n')
+		}
+		if err := writeFileAtomic(path, append(existing, mergeAttributes...), 0o644); err != nil {
+			return err
+		}
+	}
+	pterm.Success.Printfln("Installed the changelog merge driver for CHANGELOG.md in %s", path)
+	return nil
+}
+
+// uninstallMergeDriver removes the changelog merge driver again
+func uninstallMergeDriver() error {
+	if value, _ := gitQuiet("config", "merge."+mergeDriver+".driver"); value == "" {
+		return nil
+	}
+	if _, err := gitOutput("config", "--remove-section", "merge."+mergeDriver); err != nil {
+		return err
+	}
+	path, err := attributesPath()
+	if err != nil {
+		return err
+	}
+	if existing, err := os.ReadFile(path); err == nil {
+		if err := writeFileAtomic(path, []byte(strings.Replace(string(existing), mergeAttributes, "", 1)), 0o644); err != nil {
+			return err
+		}
+	}
+	pterm.Success.Println("Removed the changelog merge driver")
+	return nil
+}
+
+// attributesPath returns the path of .git/info/attributes
+func attributesPath() (string, error) {
+	out, err := gitOutput("rev-parse", "--git-path", "info/attributes")
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(out)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(gitRoot, path)
+	}
+	return path, nil
+}
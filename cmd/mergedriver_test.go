@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeChangelogVersions(t *testing.T) {
+	const preamble = "# Changelog\n\n"
+	const released = "## [1.0.0] - 2024-01-01\n\n### Added\n\n- first\n"
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		conflicts          int
+	}{
+		{
+			name:   "only one side changed",
+			base:   preamble + "## [Unreleased]\n\n### Added\n\n- a\n\n" + released,
+			ours:   preamble + "## [Unreleased]\n\n### Added\n\n- a\n- b\n\n" + released,
+			theirs: preamble + "## [Unreleased]\n\n### Added\n\n- a\n\n" + released,
+			want:   preamble + "## [Unreleased]\n\n### Added\n\n- a\n- b\n\n" + released,
+		},
+		{
+			name:   "both sides add to Unreleased",
+			base:   preamble + "## [Unreleased]\n\n### Added\n\n- a\n\n" + released,
+			ours:   preamble + "## [Unreleased]\n\n### Added\n\n- a\n- ours\n\n" + released,
+			theirs: preamble + "## [Unreleased]\n\n### Added\n\n- a\n- theirs\n\n### Fixed\n\n- their fix\n\n" + released,
+			// their entries follow the one preceding them on their side
+			want: preamble + "## [Unreleased]\n\n### Added\n\n- a\n- theirs\n- ours\n\n### Fixed\n\n- their fix\n\n" + released,
+		},
+		{
+			name:   "both sides add Unreleased",
+			base:   preamble + released,
+			ours:   preamble + "## [Unreleased]\n\n### Added\n\n- ours\n\n" + released,
+			theirs: preamble + "## [Unreleased]\n\n### Fixed\n\n- theirs\n\n" + released,
+			want:   preamble + "## [Unreleased]\n\n### Added\n\n- ours\n\n### Fixed\n\n- theirs\n\n" + released,
+		},
+		{
+			name:   "the same entry on both sides",
+			base:   preamble + "## [Unreleased]\n\n### Added\n\n- a\n\n" + released,
+			ours:   preamble + "## [Unreleased]\n\n### Added\n\n- a\n- same\n- ours\n\n" + released,
+			theirs: preamble + "## [Unreleased]\n\n### Added\n\n- a\n- same\n\n" + released,
+			want:   preamble + "## [Unreleased]\n\n### Added\n\n- a\n- same\n- ours\n\n" + released,
+		},
+		{
+			name:   "entries with continuation lines",
+			base:   preamble + "## [Unreleased]\n\n### Changed\n\n- a\n  more about a\n\n" + released,
+			ours:   preamble + "## [Unreleased]\n\n### Changed\n\n- a\n  more about a\n- ours\n\n" + released,
+			theirs: preamble + "## [Unreleased]\n\n### Changed\n\n- theirs\n  more about theirs\n- a\n  more about a\n\n" + released,
+			want:   preamble + "## [Unreleased]\n\n### Changed\n\n- theirs\n  more about theirs\n- a\n  more about a\n- ours\n\n" + released,
+		},
+		{
+			name:   "an entry removed on one side",
+			base:   preamble + "## [Unreleased]\n\n### Added\n\n- a\n- b\n\n" + released,
+			ours:   preamble + "## [Unreleased]\n\n### Added\n\n- b\n- ours\n\n" + released,
+			theirs: preamble + "## [Unreleased]\n\n### Added\n\n- a\n- b\n- theirs\n\n" + released,
+			want:   preamble + "## [Unreleased]\n\n### Added\n\n- b\n- theirs\n- ours\n\n" + released,
+		},
+		{
+			name:      "a heading changed differently on both sides",
+			base:      preamble + "## [Unreleased]\n\n- a\n\n" + released,
+			ours:      preamble + "## [1.1.0] - 2024-02-01\n\n- a\n\n" + released,
+			theirs:    preamble + "## [1.1.0] - 2024-02-02\n\n- a\n\n" + released,
+			want:      preamble + "<<<<<<< ours\n## [1.1.0] - 2024-02-01\n=======\n## [1.1.0] - 2024-02-02\n>>>>>>> theirs\n\n- a\n\n" + released,
+			conflicts: 1,
+		},
+		{
+			name:      "a text changed differently on both sides",
+			base:      preamble + "## [Unreleased]\n\nNotes.\n\n### Added\n\n- a\n\n" + released,
+			ours:      preamble + "## [Unreleased]\n\nOur notes.\n\n### Added\n\n- a\n\n" + released,
+			theirs:    preamble + "## [Unreleased]\n\nTheir notes.\n\n### Added\n\n- a\n\n" + released,
+			want:      preamble + "## [Unreleased]\n\n<<<<<<< ours\nOur notes.\n=======\nTheir notes.\n>>>>>>> theirs\n\n### Added\n\n- a\n\n" + released,
+			conflicts: 1,
+		},
+		{
+			name:      "a release removed on one side and changed on the other",
+			base:      preamble + "## [Unreleased]\n\n- a\n\n" + released,
+			ours:      preamble + released,
+			theirs:    preamble + "## [Unreleased]\n\n- a\n- b\n\n" + released,
+			want:      preamble + "<<<<<<< ours\n\n=======\n## [Unreleased]\n\n- a\n- b\n>>>>>>> theirs\n\n" + released,
+			conflicts: 1,
+		},
+		{
+			name:   "a release removed on one side and unchanged on the other",
+			base:   preamble + "## [Unreleased]\n\n- a\n\n" + released,
+			ours:   preamble + released,
+			theirs: preamble + "## [Unreleased]\n\n- a\n\n" + released,
+			want:   preamble + released,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := mergeChangelogVersions(tt.base, tt.ours, tt.theirs)
+			if got != tt.want || conflicts != tt.conflicts {
+				t.Errorf("mergeChangelogVersions() = %d conflicts\n%s\nwant %d conflicts\n%s", conflicts, got, tt.conflicts, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeEntries(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs []string
+		want               []string
+	}{
+		{name: "both add", base: []string{"- a"}, ours: []string{"- a", "- b"}, theirs: []string{"- a", "- c"}, want: []string{"- a", "- c", "- b"}},
+		{name: "they add before ours", base: []string{"- a"}, ours: []string{"- a", "- b"}, theirs: []string{"- c", "- a"}, want: []string{"- c", "- a", "- b"}},
+		{name: "both add the same", base: nil, ours: []string{"- a"}, theirs: []string{"- a"}, want: []string{"- a"}},
+		{name: "they remove", base: []string{"- a", "- b"}, ours: []string{"- a", "- b", "- c"}, theirs: []string{"- b"}, want: []string{"- b", "- c"}},
+		{name: "we remove", base: []string{"- a", "- b"}, ours: []string{"- b"}, theirs: []string{"- a", "- b"}, want: []string{"- b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeEntries(tt.base, tt.ours, tt.theirs); !slices.Equal(got, tt.want) {
+				t.Errorf("mergeEntries() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
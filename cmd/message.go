@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/45413/git-cc/pkg/conventional"
+	"github.com/spf13/viper"
+)
+
+var (
+	headerPattern = conventional.HeaderPattern
+	footerPattern = conventional.FooterPattern
+)
+
+// scissors is the line git cuts the message at with --cleanup=scissors and
+// commit -v, everything below it such as the diff is not part of the message
+const scissors = " ------------------------ >8 ------------------------"
+
+// autoCommentChars are the comment chars git picks from with
+// core.commentChar=auto
+const autoCommentChars = "#;@!$%^&|:"
+
+var (
+	configuredCommentChar     string
+	configuredCommentCharOnce sync.Once
+)
+
+// commentChar returns the comment char of the message in lines, as
+// configured by core.commentChar. With auto it is the one of the scissors
+// line, or # when there is none.
+func commentChar(lines []string) string {
+	configuredCommentCharOnce.Do(func() {
+		configuredCommentChar = "#"
+		if repo == nil {
+			return
+		}
+		if char := gitConfigOption("core", "commentChar"); char != "" {
+			configuredCommentChar = char
+		}
+	})
+	if configuredCommentChar != "auto" {
+		return configuredCommentChar
+	}
+
+	for _, line := range lines {
+		if prefix, ok := strings.CutSuffix(line, scissors); ok && len(prefix) == 1 && strings.Contains(autoCommentChars, prefix) {
+			return prefix
+		}
+	}
+	return "#"
+}
+
+// commentLines returns the lines of a commit message file with git's comment
+// lines blanked and the scissors line and everything below it removed, so
+// positions in the remaining lines still match the file
+func commentLines(message string) []string {
+	lines := strings.Split(message, "\n")
+	comment := commentChar(lines)
+	for i, line := range lines {
+		if line == comment+scissors {
+			return lines[:i]
+		}
+		if strings.HasPrefix(line, comment) {
+			lines[i] = ""
+		}
+	}
+	return lines
+}
+
+// stripComments removes git's comment lines and the part below the scissors
+// line, such as the diff of commit -v, from a commit message file
+func stripComments(message string) string {
+	original := strings.Split(message, "\n")
+	var lines []string
+	for i, line := range commentLines(message) {
+		// blanked comment lines are dropped rather than kept empty
+		if line != "" || original[i] == "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// ignoredMessage reports whether message was generated by git itself (merges,
+// reverts, fixups) and is therefore exempt from validation
+func ignoredMessage(message string) bool {
+	for _, prefix := range []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCommitMessage parses a conventional commit message back into the
+// prompt data it would have been built from.
+func parseCommitMessage(message string) (CommitPromptData, error) {
+	c, err := conventional.Parse(message)
+	if err != nil {
+		return CommitPromptData{}, err
+	}
+	return CommitPromptData{
+		Type:                c.Type,
+		Scope:               c.Scope,
+		ShortDescription:    c.Description,
+		LongDescription:     c.Body,
+		BreakingChange:      c.Breaking,
+		BreakingChangeNote:  c.BreakingNote,
+		BreakingChangeNotes: c.BreakingNotes,
+		Footers:             c.Footers,
+	}, nil
+}
+
+// trailer is a git trailer, which Conventional Commits calls a footer
+type trailer = conventional.Footer
+
+// splitTrailers splits the trailer block off the end of a message body
+func splitTrailers(body string) (string, []trailer) {
+	return conventional.SplitFooters(body)
+}
+
+// joinTrailers appends the trailers to body as the trailer block
+func joinTrailers(body string, trailers []trailer) string {
+	return conventional.JoinFooters(body, trailers)
+}
+
+// setTrailer adds t to trailers unless it is already present. With replace
+// set, existing trailers with the same token are replaced instead.
+func setTrailer(trailers []trailer, t trailer, replace bool) []trailer {
+	var result []trailer
+	for _, existing := range trailers {
+		if strings.EqualFold(existing.Token, t.Token) {
+			if replace {
+				continue
+			} else if existing.Value == t.Value {
+				return trailers
+			}
+		}
+		result = append(result, existing)
+	}
+	return append(result, t)
+}
+
+// commitRules returns the rules configured for this repository
+func commitRules() conventional.Rules {
+	return conventional.Rules{
+		Types:             commitTypes,
+		Scopes:            scopes,
+		MaxHeaderLength:   viper.GetInt("max_subject_length"),
+		MaxBodyLineLength: viper.GetInt("max_body_line_length"),
+		SubjectCase:       viper.GetString("subject_case"),
+		NoTrailingPeriod:  viper.GetBool("forbid_trailing_period"),
+		Imperative:        viper.GetBool("imperative_mood"),
+		Conditions:        commitConditions(),
+	}
+}
+
+// revertRefPattern matches the hash git cc revert references the reverted
+// commit with
+var revertRefPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// isRevert reports whether a message of commitType with footers was written
+// by git cc revert: the revert type and a Refs footer naming the reverted
+// commit. Such reverts are valid whatever types are configured and need no
+// scope.
+func isRevert(commitType string, footers []trailer) bool {
+	return commitType == "revert" && slices.ContainsFunc(footers, func(t trailer) bool {
+		return strings.EqualFold(t.Token, "Refs") && revertRefPattern.MatchString(strings.TrimSpace(t.Value))
+	})
+}
+
+// revertRules returns rules accepting the reverts of isRevert
+func revertRules(rules conventional.Rules) conventional.Rules {
+	if len(rules.Types) > 0 && !slices.Contains(rules.Types, "revert") {
+		rules.Types = append(slices.Clip(rules.Types), "revert")
+	}
+	if len(rules.Scopes) > 0 && !slices.Contains(rules.Scopes, "none") {
+		rules.Scopes = append(slices.Clip(rules.Scopes), "none")
+	}
+	return rules
+}
+
+// validateCommitMessage checks a commit message against the Conventional
+// Commits spec and the configured rules. The prompts, lint and serve all
+// validate through it so they can't disagree.
+func validateCommitMessage(message string) []conventional.Problem {
+	rules := commitRules()
+	if c, err := conventional.Parse(message); err == nil && isRevert(c.Type, c.Footers) {
+		rules = revertRules(rules)
+	}
+	return conventional.Validate(message, rules)
+}
+
+// wrapText hard-wraps the lines of text longer than width at spaces, lines
+// without spaces such as URLs are kept
+func wrapText(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+
+	var wrapped []string
+	for _, line := range strings.Split(text, "\n") {
+		for utf8.RuneCountInString(line) > width {
+			// break at the last space that keeps the line within width,
+			// or the first one if a word is longer than width
+			cut := -1
+			for i, r := range line {
+				if r == ' ' && i > 0 && (cut < 0 || utf8.RuneCountInString(line[:i]) <= width) {
+					cut = i
+				}
+			}
+			if cut < 0 {
+				break
+			}
+			wrapped = append(wrapped, strings.TrimRight(line[:cut], " "))
+			line = strings.TrimLeft(line[cut:], " ")
+		}
+		wrapped = append(wrapped, line)
+	}
+	return strings.Join(wrapped, "\n")
+}
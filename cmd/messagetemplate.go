@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/spf13/viper"
+)
+
+// messageTemplateData is what message_template renders: the answers of the
+// prompts and the parts of the message git-cc would write
+type messageTemplateData struct {
+	CommitPromptData
+	// Header is type(scope)!: description, with the emoji if configured
+	Header string
+	// Body is everything below the header: the long description, the
+	// breaking change note and the footers
+	Body string
+	// Custom holds the answers of the custom_prompts by their names
+	Custom map[string]string
+}
+
+// Footer returns the value of the first footer with token, e.g. the ticket
+// of {{.Footer "Refs"}}
+func (d messageTemplateData) Footer(token string) string {
+	return footerValue(d.Footers, token)
+}
+
+// renderCommitMessage renders the message of the answers in data with
+// message_template, or as a conventional commit without one
+func renderCommitMessage(data CommitPromptData) (string, error) {
+	message := buildCommitMessage(data)
+	text := viper.GetString("message_template")
+	if text == "" {
+		return message, nil
+	}
+
+	funcs := template.FuncMap{"upper": strings.ToUpper, "lower": strings.ToLower, "join": strings.Join, "trim": strings.TrimSpace}
+	tmpl, err := template.New("message_template").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", exitcode.New(exitcode.Config, fmt.Errorf("invalid message_template: %w", err))
+	}
+
+	header, body, _ := strings.Cut(message, "\n")
+	if data.Scope == "none" {
+		data.Scope = ""
+	}
+	if !data.BreakingChange {
+		data.BreakingChangeNote, data.BreakingChangeNotes = "", nil
+	}
+	var rendered strings.Builder
+	err = tmpl.Execute(&rendered, messageTemplateData{CommitPromptData: data, Header: header, Body: strings.TrimSpace(body), Custom: customAnswers(data.Footers)})
+	if err != nil {
+		return "", fmt.Errorf("message_template failed: %w", err)
+	}
+	if strings.TrimSpace(rendered.String()) == "" {
+		return "", fmt.Errorf("message_template rendered an empty message")
+	}
+	return strings.TrimSpace(rendered.String()), nil
+}
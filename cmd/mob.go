@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// mobSession is a pair or mob programming session, its co-authors are
+// credited on every commit until the session is stopped
+type mobSession struct {
+	Started   time.Time `json:"started"`
+	CoAuthors []string  `json:"co_authors"`
+}
+
+var mobCmd = &cobra.Command{
+	Use:   "mob",
+	Short: "Credit pair or mob programming partners on every commit",
+	Long: `Start a pair or mob programming session to add Co-authored-by trailers for
+its members to every commit until the session is stopped. Members are given
+as "Name <email>" or as a name, first name or email user matching an author
+in the history of the repository.
+
+Without a command the current session is shown.`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		mobStatus()
+	},
+}
+
+var mobStartCmd = &cobra.Command{
+	Use:   "start <co-author>[,<co-author>...]",
+	Short: "Start a session with the given co-authors",
+	Example: `  git cc mob start alice,bob
+  git cc mob start "Alice Smith <alice@example.com>"`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  mobStart,
+}
+
+var mobStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the current session",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := os.Remove(mobPath()); errors.Is(err, os.ErrNotExist) {
+			pterm.Info.Println("No mob session running")
+			return
+		} else if err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Failure)
+		}
+		pterm.Success.Println("Mob session stopped")
+	},
+}
+
+func init() {
+	mobCmd.AddCommand(mobStartCmd, mobStopCmd)
+	rootCmd.AddCommand(mobCmd)
+}
+
+// mobPath returns the file the current session is stored in
+func mobPath() string {
+	return filepath.Join(gitDir(), "git-cc", "mob.json")
+}
+
+func mobStart(cmd *cobra.Command, args []string) {
+	session := mobSession{Started: time.Now()}
+	for _, arg := range args {
+		for _, name := range strings.Split(arg, ",") {
+			if strings.TrimSpace(name) == "" {
+				continue
+			}
+			author, err := resolveAuthor(strings.TrimSpace(name))
+			if err != nil {
+				pterm.Error.Println(err)
+				exit(exitcode.Failure)
+			}
+			session.CoAuthors = append(session.CoAuthors, author)
+		}
+	}
+	session.CoAuthors = removeDuplicateStr(session.CoAuthors)
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(mobPath()), 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(mobPath(), data, 0o644)
+	}
+	if err != nil {
+		pterm.Error.Println("Failed to save mob session:", err)
+		exit(exitcode.Failure)
+	}
+
+	pterm.Success.Printfln("Mob session started, crediting %s until git cc mob stop", strings.Join(session.CoAuthors, ", "))
+}
+
+func mobStatus() {
+	session, err := loadMobSession()
+	if err != nil {
+		pterm.Error.Println("Failed to read mob session:", err)
+		exit(exitcode.Failure)
+	}
+	if session == nil {
+		pterm.Info.Println("No mob session running")
+		return
+	}
+
+	pterm.Info.Printfln("Mob session running since %s", session.Started.Format(time.Kitchen))
+	for _, author := range session.CoAuthors {
+		fmt.Println("Co-authored-by: " + author)
+	}
+}
+
+// loadMobSession returns the running session or nil if there is none
+func loadMobSession() (*mobSession, error) {
+	data, err := os.ReadFile(mobPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	session := &mobSession{}
+	return session, json.Unmarshal(data, session)
+}
+
+// resolveAuthor turns a co-author given on the command line into "Name <email>"
+// by looking it up among the authors of the repository
+func resolveAuthor(name string) (string, error) {
+	if strings.Contains(name, "<") {
+		return name, nil
+	}
+
+	log, err := gitOutput("log", "--all", "--format=%an <%ae>")
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, author := range removeDuplicateStr(strings.Split(strings.TrimSpace(log), "\n")) {
+		fullName, email, _ := strings.Cut(strings.TrimSuffix(author, ">"), " <")
+		user, _, _ := strings.Cut(email, "@")
+		first, _, _ := strings.Cut(fullName, " ")
+		for _, candidate := range []string{fullName, first, user, email} {
+			if strings.EqualFold(candidate, name) {
+				matches = append(matches, author)
+				break
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no author matching %q found, give the co-author as \"Name <email>\"", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%q matches several authors (%s), give the co-author as \"Name <email>\"", name, strings.Join(matches, ", "))
+	}
+}
+
+// addMobTrailers credits the co-authors of the running session in message
+func addMobTrailers(message string) string {
+	session, err := loadMobSession()
+	if err != nil {
+		pterm.Warning.Println("Failed to read mob session:", err)
+		return message
+	}
+	if session == nil {
+		return message
+	}
+
+	header, rest, _ := strings.Cut(message, "\n")
+	body, trailers := splitTrailers(rest)
+	for _, author := range session.CoAuthors {
+		trailers = setTrailer(trailers, trailer{Token: "Co-authored-by", Separator: ": ", Value: author}, false)
+	}
+	return strings.TrimSpace(header + "\n\n" + joinTrailers(body, trailers))
+}
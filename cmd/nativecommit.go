@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// commitWithoutGit reports whether commits are created through go-git, as
+// no_exec asks for or because there is no git binary, e.g. in minimal
+// containers
+func commitWithoutGit() bool {
+	if viper.GetBool("no_exec") {
+		return true
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		pterm.Debug.Println("No git binary, committing through go-git:", err)
+		return true
+	}
+	return false
+}
+
+// nativeCommit creates the commit git commit would for args through go-git,
+// running the pre-commit, commit-msg and post-commit hooks itself. Only the
+// arguments git-cc passes are understood.
+func nativeCommit(args []string, stdout io.Writer) error {
+	cfg, err := repo.ConfigScoped(config.SystemScope)
+	if err != nil {
+		return err
+	}
+	var file, signKey string
+	signoff, amendHead, verify := false, false, true
+	sign := gitConfigBool("commit", "gpgsign")
+	for i := 1; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-F" && i+1 < len(args):
+			i++
+			file = args[i]
+		case arg == "--signoff":
+			signoff = true
+		case arg == "--gpg-sign":
+			sign = true
+		case strings.HasPrefix(arg, "--gpg-sign="):
+			sign, signKey = true, strings.TrimPrefix(arg, "--gpg-sign=")
+		case arg == "--no-gpg-sign":
+			sign = false
+		case arg == "--amend":
+			amendHead = true
+		case arg == "--no-verify" || arg == "-n":
+			verify = false
+		default:
+			return fmt.Errorf("git commit argument %s needs the git binary, commits are made through go-git", arg)
+		}
+	}
+
+	committer, err := commitSignature(cfg, "COMMITTER")
+	if err != nil {
+		return err
+	}
+	options := &git.CommitOptions{Committer: committer}
+	if amendHead {
+		// go-git's Amend keeps the tree of HEAD, so its parents are
+		// committed to instead
+		head, err := repo.Head()
+		if err != nil {
+			return fmt.Errorf("nothing to amend: %w", err)
+		}
+		last, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			return err
+		}
+		if len(last.ParentHashes) == 0 {
+			return errors.New("amending the root commit needs the git binary")
+		}
+		author := last.Author
+		options.Author, options.Parents, options.AllowEmptyCommits = &author, last.ParentHashes, true
+	} else if options.Author, err = commitSignature(cfg, "AUTHOR"); err != nil {
+		return err
+	}
+
+	if verify {
+		if err := runHook("pre-commit"); err != nil {
+			return err
+		}
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	message := cleanupWhitespace(string(content))
+	if signoff {
+		header, rest, _ := strings.Cut(message, "\n")
+		body, trailers := splitTrailers(rest)
+		trailers = setTrailer(trailers, trailer{Token: "Signed-off-by", Separator: ": ", Value: fmt.Sprintf("%s <%s>", committer.Name, committer.Email)}, false)
+		message = strings.TrimSpace(header + "\n\n" + joinTrailers(body, trailers))
+	}
+	if verify {
+		// commit-msg gets the message as git would write it and may
+		// change it
+		if err := os.WriteFile(file, []byte(message+"\n"), 0o600); err != nil {
+			return err
+		}
+		if err := runHook("commit-msg", file); err != nil {
+			return err
+		}
+		if content, err = os.ReadFile(file); err != nil {
+			return err
+		}
+		message = cleanupWhitespace(string(content))
+	}
+	if message == "" {
+		return errors.New("aborting commit due to empty commit message")
+	}
+
+	previous, _ := repo.Head()
+	hash, err := worktree.Commit(message+"\n", options)
+	if err != nil {
+		return err
+	}
+	if sign {
+		if signKey == "" {
+			signKey = gitConfigOption("user", "signingkey")
+		}
+		if signKey == "" {
+			signKey = committer.Email
+		}
+		if hash, err = signCommit(hash, signKey); err != nil {
+			// git commits nothing when signing fails
+			if previous != nil {
+				moveHead(previous.Hash())
+			} else if head, err := repo.Storer.Reference(plumbing.HEAD); err == nil {
+				// the branch of the first commit is unborn again
+				repo.Storer.RemoveReference(head.Target())
+			}
+			return err
+		}
+	}
+
+	// the summary git commit prints
+	branch := "detached HEAD"
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		branch = head.Name().Short()
+	}
+	header, _, _ := strings.Cut(message, "\n")
+	fmt.Fprintf(stdout, "[%s %s] %s\n", branch, hash.String()[:7], header)
+
+	if verify {
+		// like git, a failing post-commit hook doesn't undo the commit
+		if err := runHook("post-commit"); err != nil {
+			pterm.Warning.Println(err)
+		}
+	}
+	return nil
+}
+
+// cleanupWhitespace cleans up message like git commit --cleanup=whitespace,
+// the default for messages which aren't edited
+func cleanupWhitespace(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// commitSignature returns the AUTHOR or COMMITTER identity, from the
+// GIT_<role>_NAME and GIT_<role>_EMAIL variables or else the git config
+func commitSignature(cfg *config.Config, role string) (*object.Signature, error) {
+	name, email := cfg.User.Name, cfg.User.Email
+	configured := cfg.Author
+	if role == "COMMITTER" {
+		configured = cfg.Committer
+	}
+	if configured.Name != "" {
+		name = configured.Name
+	}
+	if configured.Email != "" {
+		email = configured.Email
+	}
+	if value := os.Getenv("GIT_" + role + "_NAME"); value != "" {
+		name = value
+	}
+	if value := os.Getenv("GIT_" + role + "_EMAIL"); value != "" {
+		email = value
+	}
+	if name == "" || email == "" {
+		return nil, errors.New("no identity to commit as, set user.name and user.email in the git config")
+	}
+	return &object.Signature{Name: name, Email: email, When: time.Now()}, nil
+}
+
+// runHook runs the hook name with args if it is installed and executable
+func runHook(name string, args ...string) error {
+	dir := filepath.Join(gitDir(), "hooks")
+	if path := gitConfigPath("core", "hooksPath"); path != "" {
+		dir = path
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitRoot, dir)
+	}
+	path := filepath.Join(dir, name)
+	if info, err := os.Stat(path); err != nil || info.Mode()&0o111 == 0 {
+		return nil
+	}
+
+	pterm.Debug.Println("Running hook", path)
+	hook := exec.Command(path, args...)
+	hook.Dir = gitRoot
+	// git prints the output of hooks on stderr
+	hook.Stdout = os.Stderr
+	hook.Stderr = os.Stderr
+	passTerminal(hook)
+	if hook.Env == nil {
+		hook.Env = os.Environ()
+	}
+	hook.Env = append(hook.Env, "GIT_DIR="+gitDir(), "GIT_EDITOR=:")
+	if err := hook.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return nil
+}
+
+// signCommit signs the commit hash with gpg.program like git does, stores
+// the signed commit and moves HEAD to it
+func signCommit(hash plumbing.Hash, key string) (plumbing.Hash, error) {
+	if format := gitConfigOption("gpg", "format"); format != "" && format != "openpgp" {
+		return hash, fmt.Errorf("signing with gpg.format %s needs the git binary", format)
+	}
+	program := gitConfigOption("gpg", "program")
+	if program == "" {
+		program = "gpg"
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return hash, err
+	}
+	unsigned := repo.Storer.NewEncodedObject()
+	if err := commit.EncodeWithoutSignature(unsigned); err != nil {
+		return hash, err
+	}
+	reader, err := unsigned.Reader()
+	if err != nil {
+		return hash, err
+	}
+	defer reader.Close()
+
+	var signature, status bytes.Buffer
+	gpg := exec.Command(program, "--status-fd=2", "-bsau", key)
+	gpg.Stdin = reader
+	gpg.Stdout = &signature
+	// the status lines are only of interest when signing fails
+	gpg.Stderr = &status
+	// the data to sign is on stdin, pinentry still needs the terminal
+	if os.Getenv("GPG_TTY") == "" && terminalName() != "" {
+		gpg.Env = append(os.Environ(), "GPG_TTY="+terminalName())
+	}
+	if err := gpg.Run(); err != nil {
+		return hash, fmt.Errorf("gpg failed to sign the data: %w\n%s", err, strings.TrimSpace(status.String()))
+	}
+
+	commit.PGPSignature = signature.String()
+	signed := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(signed); err != nil {
+		return hash, err
+	}
+	signedHash, err := repo.Storer.SetEncodedObject(signed)
+	if err != nil {
+		return hash, err
+	}
+	return signedHash, moveHead(signedHash)
+}
+
+// moveHead points HEAD, or the branch it is on, to hash
+func moveHead(hash plumbing.Hash) error {
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return err
+	}
+	name := plumbing.HEAD
+	if head.Type() == plumbing.SymbolicReference {
+		name = head.Target()
+	}
+	return repo.Storer.SetReference(plumbing.NewHashReference(name, hash))
+}
+
+// headCommit returns the hash and message of HEAD like git log -1
+// --format=%H%n%B
+func headCommit() (string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", err
+	}
+	return commit.Hash.String() + "\n" + commit.Message, nil
+}
+
+// stageTrackedChanges stages the changes to tracked files like git add
+// --update
+func stageTrackedChanges() error {
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+	for path, entry := range status {
+		switch entry.Worktree {
+		case git.Modified:
+			_, err = worktree.Add(path)
+		case git.Deleted:
+			_, err = worktree.Remove(path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var nextVersionCmd = &cobra.Command{
+	Use:   "next-version",
+	Short: "Compute the next semantic version from the commits since the latest release",
+	Long: `Compute the next semantic version from the conventional commits since the
+latest semver tag: a breaking change bumps the major version, a feature the
+minor and a fix the patch version. The version is printed to stdout for use
+in release pipelines.
+
+Without releasable changes the latest version is printed unchanged.
+
+In a monorepo each package is versioned by its own tags: --tag-prefix pkgA/
+only reads tags such as pkgA/v1.2.3 and counts only the commits changing
+--path or with the --scope. Without either, the commits changing the pkgA
+directory are counted, or those scoped pkgA when there is no such
+directory.`,
+	Example: `  git cc next-version
+  git cc next-version --prerelease rc
+  git cc next-version --tag
+  git cc next-version --tag-prefix pkgA/ --tag`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         nextVersion,
+}
+
+func init() {
+	nextVersionCmd.Flags().String("prerelease", "", "Compute a prerelease version with this identifier, e.g. rc for v1.3.0-rc.1")
+	nextVersionCmd.Flags().Bool("tag", false, "Create an annotated tag for the computed version")
+	nextVersionCmd.Flags().String("tag-prefix", "", "Version the package whose tags start with this prefix, e.g. pkgA/ for pkgA/v1.2.3")
+	nextVersionCmd.Flags().String("path", "", "Count only commits changing this `path` (default: the directory named by --tag-prefix)")
+	nextVersionCmd.Flags().String("scope", "", "Count only commits with this scope (default: the --tag-prefix without its separator when it isn't a directory)")
+	rootCmd.AddCommand(nextVersionCmd)
+}
+
+func nextVersion(cmd *cobra.Command, args []string) {
+	prerelease, _ := cmd.Flags().GetString("prerelease")
+	tag, _ := cmd.Flags().GetBool("tag")
+	prefix, _ := cmd.Flags().GetString("tag-prefix")
+	path, _ := cmd.Flags().GetString("path")
+	scope, _ := cmd.Flags().GetString("scope")
+
+	if tag {
+		refuseWrite("--tag")
+	}
+
+	bump, err := computeNextVersion(prefix, path, scope, prerelease)
+	if err != nil {
+		pterm.Error.Println("Failed to compute the next version:", err)
+		exit(exitcode.Failure)
+	}
+	if !bump.Bumped {
+		pterm.Warning.Printfln("No features, fixes or breaking changes since %s", bump.Latest)
+		if tag {
+			exit(exitcode.Failure)
+		}
+		fmt.Println(bump.Latest)
+		return
+	}
+	next := bump.Next
+
+	if tag {
+		create := exec.Command("git", "tag", "--annotate", next.String(), "--message", "Release "+next.String())
+		create.Dir = gitRoot
+		create.Stderr = os.Stderr
+		if err := create.Run(); err != nil {
+			pterm.Error.Println("Failed to create tag:", err)
+			exit(exitcode.CommitFailed)
+		}
+	}
+
+	fmt.Println(next)
+}
+
+// versionBump is the next version of the repository or of a package of it
+type versionBump struct {
+	Latest semVersion
+	// LatestTag is the tag of Latest, empty before the first release
+	LatestTag string
+	Next      semVersion
+	// Bumped is false without releasable changes since Latest
+	Bumped bool
+	// Commits are the commits since LatestTag counted for the bump
+	Commits []conventionalCommit
+}
+
+// computeNextVersion returns the version following the latest release
+// tagged with prefix, from the commits changing path or with scope in a
+// monorepo, as a prerelease when an id is given
+func computeNextVersion(prefix, path, scope, prerelease string) (versionBump, error) {
+	tags, err := semverTags("HEAD", prefix)
+	if err != nil {
+		return versionBump{}, fmt.Errorf("listing tags: %w", err)
+	}
+
+	// releases are computed from the latest stable release, prereleases of
+	// the next version are counted up separately
+	bump := versionBump{Latest: semVersion{Prefix: prefix + "v"}}
+	for name, v := range tags {
+		if v.Prerelease == "" && (bump.LatestTag == "" || v.Compare(bump.Latest) > 0) {
+			bump.Latest, bump.LatestTag = v, name
+		}
+	}
+
+	commits, err := commitRange(bump.LatestTag, "HEAD")
+	if err != nil {
+		return bump, err
+	}
+	if prefix != "" || path != "" || scope != "" {
+		if path == "" && scope == "" {
+			path, scope = packageOf(prefix)
+		}
+		commits, err = packageCommits(commits, bump.LatestTag, path, scope)
+		if err != nil {
+			return bump, err
+		}
+	}
+	bump.Commits = commits
+
+	bump.Next, bump.Bumped = bumpVersion(bump.Latest, commits)
+	if bump.Bumped && prerelease != "" {
+		bump.Next = nextPrerelease(bump.Next, prerelease, tags)
+	}
+	return bump, nil
+}
+
+// semverTags returns the semver tags reachable from rev starting with
+// prefix by name, their Prefix includes it
+func semverTags(rev, prefix string) (map[string]semVersion, error) {
+	out, err := gitOutput("tag", "--merged", rev)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := map[string]semVersion{}
+	for _, name := range strings.Fields(out) {
+		if v, ok := parseTagVersion(name, prefix); ok {
+			tags[name] = v
+		}
+	}
+	return tags, nil
+}
+
+// parseTagVersion parses the version of a tag starting with prefix, whose
+// Prefix includes it
+func parseTagVersion(name, prefix string) (semVersion, bool) {
+	version, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return semVersion{}, false
+	}
+	v, ok := parseSemver(version)
+	v.Prefix = prefix + v.Prefix
+	return v, ok
+}
+
+// nextPrerelease returns the prerelease id of next, numbered after the
+// prereleases of the same version among tags, such as v1.3.0-rc.2 after
+// v1.3.0-rc.1
+func nextPrerelease(next semVersion, id string, tags map[string]semVersion) semVersion {
+	number := 1
+	for _, v := range tags {
+		current, n, ok := strings.Cut(v.Prerelease, ".")
+		if v.Major == next.Major && v.Minor == next.Minor && v.Patch == next.Patch && current == id && ok {
+			if i, err := strconv.Atoi(n); err == nil && i >= number {
+				number = i + 1
+			}
+		}
+	}
+	next.Prerelease = fmt.Sprintf("%s.%d", id, number)
+	return next
+}
+
+// packageOf returns the directory of the package tagged with prefix when
+// it exists, otherwise the scope named as the package
+func packageOf(prefix string) (string, string) {
+	name := strings.TrimRight(prefix, "/-@_")
+	if name == "" {
+		return "", ""
+	}
+	if _, err := gitQuiet("rev-parse", "--verify", "--quiet", "HEAD:"+name); err == nil {
+		return name, ""
+	}
+	// packages/pkgA/ is scoped as pkgA
+	return "", name[strings.LastIndex(name, "/")+1:]
+}
+
+// packageCommits returns the commits changing path or with scope, which
+// belong to a package of a monorepo. from is where commits start, as in
+// commitRange.
+func packageCommits(commits []conventionalCommit, from, path, scope string) ([]conventionalCommit, error) {
+	changed := map[string]bool{}
+	if path != "" {
+		rev := "HEAD"
+		if from != "" {
+			rev = from + "..HEAD"
+		}
+		out, err := gitOutput("log", "--full-history", "--format=%H", rev, "--", path)
+		if err != nil {
+			return nil, err
+		}
+		for _, hash := range strings.Fields(out) {
+			changed[hash] = true
+		}
+	}
+
+	var matching []conventionalCommit
+	for _, c := range commits {
+		if changed[c.Commit.Hash.String()] || scope != "" && c.Data.Scope == scope {
+			matching = append(matching, c)
+		}
+	}
+	return matching, nil
+}
+
+// bumpVersion returns the version following v after commits, and whether
+// they contain any releasable change
+func bumpVersion(v semVersion, commits []conventionalCommit) (semVersion, bool) {
+	var major, minor, patch bool
+	for _, c := range commits {
+		if !c.Valid {
+			continue
+		}
+		switch {
+		case c.Data.BreakingChange:
+			major = true
+		case c.Data.Type == "feat":
+			minor = true
+		case c.Data.Type == "fix":
+			patch = true
+		}
+	}
+
+	next := semVersion{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch {
+	case major:
+		next.Major, next.Minor, next.Patch = v.Major+1, 0, 0
+	case minor:
+		next.Minor, next.Patch = v.Minor+1, 0
+	case patch:
+		next.Patch++
+	default:
+		return v, false
+	}
+	return next, true
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// notifyCommitDone rings the terminal bell or sends a desktop notification,
+// as notify configures, when git commit took longer than notify_after, so
+// slow hooks can be left running in the background
+func notifyCommitDone(err error, took time.Duration) {
+	mode := viper.GetString("notify")
+	if mode == "off" || took < viper.GetDuration("notify_after") {
+		return
+	}
+
+	title := "git commit finished"
+	if err != nil {
+		title = "git commit failed"
+	}
+	message := fmt.Sprintf("%s in %s after %s", title, filepath.Base(gitRoot), took.Round(time.Second))
+
+	switch mode {
+	case "bell":
+		fmt.Fprint(os.Stderr, "\a")
+	case "desktop":
+		if notifyErr := desktopNotification(title, message); notifyErr != nil {
+			pterm.Debug.Println("Failed to send a desktop notification:", notifyErr)
+			fmt.Fprint(os.Stderr, "\a")
+		}
+	default:
+		pterm.Warning.Printfln("Unknown notify %q, expected off, bell or desktop", mode)
+	}
+}
+
+// desktopNotification shows a notification with notify-send or, on macOS,
+// osascript
+func desktopNotification(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", message, title))
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("notify-send", "--app-name=git-cc", title, message)
+	default:
+		return fmt.Errorf("no desktop notifications on %s", runtime.GOOS)
+	}
+	return cmd.Run()
+}
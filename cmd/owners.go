@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var ownersCmd = &cobra.Command{
+	Use:   "owners [scope]",
+	Short: "Show the owners of a scope",
+	Long: `Show the owners or teams responsible for a scope, as configured by
+scope_owners. Without a scope the owners of all scopes are listed.`,
+	Example: `  git cc owners api
+  git cc owners`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeScopes(cmd, args, toComplete)
+	},
+	Run: owners,
+}
+
+func init() {
+	rootCmd.AddCommand(ownersCmd)
+}
+
+func owners(cmd *cobra.Command, args []string) {
+	if len(args) == 1 {
+		list := scopeOwners(args[0])
+		if len(list) == 0 {
+			pterm.Error.Printfln("no owners configured for scope %q", args[0])
+			exit(exitcode.Failure)
+		}
+		// one per line so the output can be used by scripts
+		for _, owner := range list {
+			fmt.Println(owner)
+		}
+		return
+	}
+
+	configured := viper.GetStringMapStringSlice("scope_owners")
+	if len(configured) == 0 {
+		pterm.Info.Println("No scope owners configured")
+		return
+	}
+
+	names := make([]string, 0, len(configured))
+	for scope := range configured {
+		names = append(names, scope)
+	}
+	slices.Sort(names)
+
+	table := pterm.TableData{{"Scope", "Owners"}}
+	for _, scope := range names {
+		table = append(table, []string{scope, strings.Join(configured[scope], ", ")})
+	}
+	pterm.DefaultTable.WithHasHeader().WithData(table).Render()
+}
+
+// scopeOwners returns the owners configured for scope
+func scopeOwners(scope string) []string {
+	// viper lowercases map keys
+	return viper.GetStringMapStringSlice("scope_owners")[strings.ToLower(scope)]
+}
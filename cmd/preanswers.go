@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+)
+
+var (
+	// read the pre-answers as JSON from stdin, set by --stdin
+	stdinAnswers bool
+	// answers given up front by wrappers, their prompts aren't asked
+	preAnswers  CommitPromptData
+	preAnswered = map[promptField]bool{}
+)
+
+// preAnswerEnv are the environment variables pre-answering prompts
+var preAnswerEnv = []struct {
+	Name  string
+	Field promptField
+}{
+	{"GIT_CC_TYPE", typeField},
+	{"GIT_CC_SCOPE", scopeField},
+	{"GIT_CC_SUBJECT", shortDescriptionField},
+	{"GIT_CC_BODY", longDescriptionField},
+	{"GIT_CC_BREAKING", breakingChangeField},
+	{"GIT_CC_BREAKING_NOTE", breakingChangeField},
+	{"GIT_CC_FOOTERS", footersField},
+}
+
+// loadPreAnswers reads the pre-answers of the environment and, with
+// --stdin, the JSON on stdin whose fields win over the environment
+func loadPreAnswers() error {
+	for _, env := range preAnswerEnv {
+		value, ok := os.LookupEnv(env.Name)
+		if !ok {
+			continue
+		}
+		if err := setPreAnswer(env.Name, value); err != nil {
+			return err
+		}
+		preAnswered[env.Field] = true
+	}
+	if !stdinAnswers {
+		return nil
+	}
+
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+	// only the fields given are answered, so their presence counts
+	var given map[string]json.RawMessage
+	if err := json.Unmarshal(content, &given); err != nil {
+		return fmt.Errorf("invalid answers on stdin: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&preAnswers); err != nil {
+		return fmt.Errorf("invalid answers on stdin: %w", err)
+	}
+	fields := map[string]promptField{
+		"type":                  typeField,
+		"scope":                 scopeField,
+		"short_description":     shortDescriptionField,
+		"long_description":      longDescriptionField,
+		"breaking_change":       breakingChangeField,
+		"breaking_change_note":  breakingChangeField,
+		"breaking_change_notes": breakingChangeField,
+		"footers":               footersField,
+	}
+	for name := range given {
+		preAnswered[fields[name]] = true
+	}
+	reopenTerminal()
+	return nil
+}
+
+// setPreAnswer sets the answer of the environment variable name
+func setPreAnswer(name, value string) error {
+	switch name {
+	case "GIT_CC_TYPE":
+		preAnswers.Type = value
+	case "GIT_CC_SCOPE":
+		preAnswers.Scope = value
+	case "GIT_CC_SUBJECT":
+		preAnswers.ShortDescription = value
+	case "GIT_CC_BODY":
+		preAnswers.LongDescription = value
+	case "GIT_CC_BREAKING":
+		breaking, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false", name)
+		}
+		preAnswers.BreakingChange = breaking
+	case "GIT_CC_BREAKING_NOTE":
+		preAnswers.BreakingChangeNote = value
+		preAnswers.BreakingChange = preAnswers.BreakingChange || value != ""
+	case "GIT_CC_FOOTERS":
+		// one "Token: value" per line
+		for _, line := range strings.Split(strings.TrimSpace(value), "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			t, err := parseTrailerArg(line)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			preAnswers.Footers = append(preAnswers.Footers, t)
+		}
+	}
+	return nil
+}
+
+// reopenTerminal answers the remaining prompts from the terminal once the
+// piped stdin was read
+func reopenTerminal() {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return
+	}
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		// fine as long as everything was answered
+		pterm.Debug.Println("No terminal for the remaining prompts:", err)
+		return
+	}
+	os.Stdin = tty
+	if plain, ok := ui.(*plainUI); ok {
+		plain.in = bufio.NewReader(tty)
+	}
+}
+
+// preAnswer answers the prompts of field with preAnswers
+func preAnswer(field promptField, data CommitPromptData) CommitPromptData {
+	switch field {
+	case typeField:
+		data.Type = preAnswers.Type
+	case scopeField:
+		data.Scope = preAnswers.Scope
+	case shortDescriptionField:
+		data.ShortDescription = preAnswers.ShortDescription
+	case longDescriptionField:
+		data.LongDescription = preAnswers.LongDescription
+	case breakingChangeField:
+		data.BreakingChange = preAnswers.BreakingChange || len(preAnswers.BreakingNotes()) > 0
+		data.BreakingChangeNote = preAnswers.BreakingChangeNote
+		data.BreakingChangeNotes = preAnswers.BreakingChangeNotes
+	case footersField:
+		data.Footers = keepFooters(preAnswers.Footers, data.Footers)
+	}
+	answersChanged(data)
+	return data
+}
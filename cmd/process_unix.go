@@ -0,0 +1,31 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+// ownProcessGroup starts cmd in a process group of its own, so a timeout
+// and signals reach the hooks it runs too. Not done on a terminal: the
+// group would be in the background and couldn't read a credential prompt,
+// and ctrl+c reaches the whole foreground group anyway.
+func ownProcessGroup(cmd *exec.Cmd) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalProcess sends sig to the process group of cmd, or to its process
+// when it shares the group of git-cc
+func signalProcess(cmd *exec.Cmd, sig syscall.Signal) error {
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+		return syscall.Kill(-cmd.Process.Pid, sig)
+	}
+	return cmd.Process.Signal(sig)
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package cmd
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// ownProcessGroup does nothing, Windows has no process groups to signal
+func ownProcessGroup(cmd *exec.Cmd) {}
+
+// signalProcess kills the process of cmd, signals can't be sent on Windows
+func signalProcess(cmd *exec.Cmd, sig syscall.Signal) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// releaseTag returns the tag on to itself, empty without one
+func releaseTag(to string) string {
+	if tag := describeTag(to); tag != "" && tag != previousTag(to) {
+		return tag
+	}
+	return ""
+}
+
+// publishRelease creates the GitHub or GitLab release of tag with notes, or
+// updates it when it exists, with the forge credentials issues are listed
+// with. It returns the web page of the release.
+func publishRelease(tag, name, notes string) (string, error) {
+	tracker, err := remoteTracker()
+	if err != nil {
+		return "", err
+	}
+	token := trackerToken(tracker)
+	if token == "" {
+		return "", fmt.Errorf("no token for %s, set %s or log in with git credential", tracker.host, map[string]string{"github": "GITHUB_TOKEN", "gitlab": "GITLAB_TOKEN"}[tracker.kind])
+	}
+	authorization := "Bearer " + token
+
+	if tracker.kind == "gitlab" {
+		api := "https://" + tracker.host + "/api/v4/projects/" + url.PathEscape(tracker.project) + "/releases"
+		payload, _ := json.Marshal(map[string]string{"tag_name": tag, "name": name, "description": notes})
+		method, target := http.MethodPut, api+"/"+url.PathEscape(tag)
+		if _, err := apiRequest(http.MethodGet, target, authorization, nil); isNotFound(err) {
+			method, target = http.MethodPost, api
+		} else if err != nil {
+			return "", err
+		}
+		body, err := apiRequest(method, target, authorization, payload)
+		if err != nil {
+			return "", err
+		}
+		var release struct {
+			Links struct {
+				Self string `json:"self"`
+			} `json:"_links"`
+		}
+		if err := json.Unmarshal(body, &release); err != nil {
+			return "", fmt.Errorf("reading release: %w", err)
+		}
+		return release.Links.Self, nil
+	}
+
+	base := "https://api.github.com"
+	if tracker.host != "github.com" {
+		// GitHub Enterprise Server
+		base = "https://" + tracker.host + "/api/v3"
+	}
+	api := base + "/repos/" + tracker.project + "/releases"
+	var release struct {
+		ID      int    `json:"id"`
+		HTMLURL string `json:"html_url"`
+	}
+	method, target := http.MethodPost, api
+	payload, _ := json.Marshal(map[string]string{"tag_name": tag, "name": name, "body": notes})
+	existing, err := apiRequest(http.MethodGet, api+"/tags/"+url.PathEscape(tag), authorization, nil)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(existing, &release); err != nil {
+			return "", fmt.Errorf("reading release: %w", err)
+		}
+		method, target = http.MethodPatch, fmt.Sprintf("%s/%d", api, release.ID)
+	case !isNotFound(err):
+		return "", err
+	}
+	body, err := apiRequest(method, target, authorization, payload)
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(body, &release); err != nil {
+		return "", fmt.Errorf("reading release: %w", err)
+	}
+	return release.HTMLURL, nil
+}
+
+// isNotFound reports whether err is an API answering 404 Not Found
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.code == http.StatusNotFound
+}
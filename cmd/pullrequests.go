@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+var (
+	// pullRequestSuffix matches the pull request GitHub appends to the header
+	// of squash merges
+	pullRequestSuffix = regexp.MustCompile(`\s*\(#(\d+)\)$`)
+	// githubMergeHeader matches the header of GitHub's pull request merges
+	githubMergeHeader = regexp.MustCompile(`^Merge pull request #(\d+) `)
+	// gitlabMergeReference matches the line GitLab ends the message of a
+	// merge request's merge commit with
+	gitlabMergeReference = regexp.MustCompile(`(?m)^See merge request \S*!(\d+)$`)
+)
+
+// pullRequests finds the pull requests commits were merged with, as
+// pull_request_style tells: squash merges mention them as (#123) in the
+// header, merge commits in their message
+type pullRequests struct {
+	squash bool
+	// merged maps the commits brought in by merge commits to the pull
+	// request of the merge
+	merged map[plumbing.Hash]string
+}
+
+// pullRequestsOf returns the pull requests of commits, a range of history
+func pullRequestsOf(commits []conventionalCommit) pullRequests {
+	style := viper.GetString("pull_request_style")
+	prs := pullRequests{squash: style != "merge", merged: map[plumbing.Hash]string{}}
+	if style == "squash" {
+		return prs
+	}
+	for _, c := range commits {
+		if c.Commit.NumParents() < 2 {
+			continue
+		}
+		number := mergedPullRequest(c.Commit.Message)
+		if number == "" {
+			continue
+		}
+		// the commits of the branch, which aren't on the mainline
+		branch := c.Commit.Hash.String()
+		out, err := gitQuiet("rev-list", branch+"^1.."+branch+"^2")
+		if err != nil {
+			pterm.Debug.Println("Failed to list the commits of", branch, err)
+			continue
+		}
+		for _, hash := range strings.Fields(out) {
+			// the outermost merge of nested ones is the pull request
+			if _, ok := prs.merged[plumbing.NewHash(hash)]; !ok {
+				prs.merged[plumbing.NewHash(hash)] = number
+			}
+		}
+	}
+	return prs
+}
+
+// mergedPullRequest returns the number of the pull request a merge commit
+// with message merged, empty for other merges
+func mergedPullRequest(message string) string {
+	if match := githubMergeHeader.FindStringSubmatch(message); match != nil {
+		return match[1]
+	}
+	if match := gitlabMergeReference.FindStringSubmatch(strings.TrimSpace(message)); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// of returns the description of c without the pull request suffix of a
+// squash merge and the number of its pull request, empty without one
+func (p pullRequests) of(c conventionalCommit) (string, string) {
+	description := c.Data.ShortDescription
+	if p.squash {
+		if match := pullRequestSuffix.FindStringSubmatch(description); match != nil {
+			return strings.TrimSuffix(description, match[0]), match[1]
+		}
+	}
+	return description, p.merged[c.Commit.Hash]
+}
+
+// pullRequestURL returns the web page of the pull request number, empty
+// without repoURL
+func pullRequestURL(repoURL, number string) string {
+	if repoURL == "" || number == "" {
+		return ""
+	}
+	return webURL(repoURL, "pull/"+number)
+}
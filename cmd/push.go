@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+)
+
+// authFailures are printed by git and its remote helpers when the
+// credentials for a remote are missing or rejected
+var authFailures = []string{
+	"Authentication failed",
+	"Permission denied",
+	"could not read Username",
+	"could not read Password",
+	"Invalid username or password",
+	"terminal prompts disabled",
+	"The requested URL returned error: 403",
+	"The requested URL returned error: 401",
+}
+
+// pushBranch pushes the current branch to its upstream after committing,
+// setting the upstream on the push remote when the branch has none
+func pushBranch() {
+	branch, err := gitQuiet("symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		pterm.Warning.Println("Not pushing, HEAD is detached")
+		return
+	}
+
+	args := []string{"push"}
+	// git can't tell the terminal behind the copy of its errors kept below
+	if term.IsTerminal(int(os.Stderr.Fd())) {
+		args = append(args, "--progress")
+	}
+	if _, err := gitQuiet("rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err != nil {
+		remote := pushRemote(branch)
+		if remote == "" {
+			pterm.Warning.Println("Not pushing, the repository has no remote")
+			return
+		}
+		args = append(args, "--set-upstream", remote, branch)
+	}
+
+	var stderr bytes.Buffer
+	push := exec.Command("git", args...)
+	push.Dir = gitRoot
+	// git's progress goes to stderr, keeping stdout clean for --output json
+	push.Stdout = os.Stderr
+	push.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	// a username, password or ssh passphrase is asked for on the terminal
+	passTerminal(push)
+	if err := push.Run(); err != nil {
+		for _, failure := range authFailures {
+			if strings.Contains(stderr.String(), failure) {
+				pterm.Error.Println("Push failed, the remote rejected the credentials. The commit was created, check your credentials and run git push")
+				exit(exitcode.Failure)
+			}
+		}
+		pterm.Error.Println("Push failed, the commit was created, run git push once the problem is solved:", err)
+		exit(exitcode.Failure)
+	}
+}
+
+// pushRemote returns the remote a branch without upstream is pushed to:
+// the one configured for it, remote.pushDefault, origin or the only remote
+func pushRemote(branch string) string {
+	for _, key := range []string{"branch." + branch + ".pushRemote", "remote.pushDefault", "branch." + branch + ".remote"} {
+		if remote, err := gitQuiet("config", "--get", key); err == nil && remote != "" {
+			return remote
+		}
+	}
+	out, err := gitQuiet("remote")
+	if err != nil {
+		return ""
+	}
+	remotes := strings.Fields(out)
+	for _, remote := range remotes {
+		if remote == "origin" {
+			return remote
+		}
+	}
+	if len(remotes) == 1 {
+		return remotes[0]
+	}
+	return ""
+}
@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// hash of git's well known empty tree, used as diff base before the first commit
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// commitQueue holds commit messages together with the staged changes they
+// belong to, so the commits can be created later, possibly on another machine.
+type commitQueue struct {
+	// Base is the commit the queue was started on
+	Base string `json:"base"`
+	// Tree is the index tree recorded by the last queued entry
+	Tree    string       `json:"tree"`
+	Entries []queueEntry `json:"entries"`
+}
+
+type queueEntry struct {
+	Message string `json:"message"`
+	Patch   string `json:"patch"`
+}
+
+var queueFile string
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Queue commit messages and create the commits later",
+	Long: `Queue commit messages together with the changes staged since the previous
+entry and create the commits in order later, possibly in another clone
+checked out at the same commit.`,
+}
+
+// commit flags which only take effect on committing, queued commits are
+// created by queue apply
+var queueIgnoredFlags = []string{"write-message", "dry-run", "print", "output", "json", "signoff", "gpg-sign", "no-gpg-sign", "push", "no-exec", "keep-message-file"}
+
+var queueAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Prompt for a commit message and queue it with the staged changes",
+	Args:  cobra.NoArgs,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		for _, name := range queueIgnoredFlags {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s has no effect on queued commits", name)
+			}
+		}
+		return commitFlagsPreRun(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		queueAdd(queuePath())
+	},
+}
+
+var queueListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "List the queued commit messages",
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		queueList(queuePath())
+	},
+}
+
+var queueApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Create the queued commits in order",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		queueApply(queuePath())
+	},
+}
+
+var queueClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Drop all queued commits",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := os.Remove(queuePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			pterm.Error.Println(err)
+			exit(exitcode.Failure)
+		}
+		pterm.Success.Println("Commit queue cleared")
+	},
+}
+
+func init() {
+	addCommitFlags(queueAddCmd)
+	for _, name := range queueIgnoredFlags {
+		queueAddCmd.Flags().MarkHidden(name)
+	}
+	queueCmd.PersistentFlags().StringVar(&queueFile, "file", "", "Path of the queue file (default .git/git-cc/queue.json)")
+	queueCmd.AddCommand(queueAddCmd, queueListCmd, queueApplyCmd, queueClearCmd)
+	rootCmd.AddCommand(queueCmd)
+}
+
+// queuePath returns the queue file given by --file or the default one in the git dir
+func queuePath() string {
+	if queueFile != "" {
+		return queueFile
+	}
+	return filepath.Join(gitDir(), "git-cc", "queue.json")
+}
+
+// queueAdd prompts for a commit message and queues it together with
+// everything staged since the previously queued entry.
+func queueAdd(file string) {
+	checkStagedChanges()
+
+	q, err := loadQueue(file)
+	if err != nil {
+		pterm.Error.Println("Failed to read commit queue:", err)
+		exit(exitcode.Failure)
+	}
+
+	tree, err := gitOutput("write-tree")
+	if err != nil {
+		pterm.Error.Println("Failed to write index tree:", err)
+		exit(exitcode.Failure)
+	}
+	tree = strings.TrimSpace(tree)
+
+	from := q.Tree
+	if len(q.Entries) == 0 {
+		from = emptyTree
+		if head, err := repo.Head(); err == nil {
+			from = head.Hash().String()
+		}
+		q.Base = from
+	}
+
+	patch, err := gitOutput("diff", "--binary", "--full-index", from, tree)
+	if err != nil {
+		pterm.Error.Println("Failed to diff staged changes:", err)
+		exit(exitcode.Failure)
+	}
+	if len(patch) == 0 {
+		pterm.Error.Println("nothing staged since the last queued commit")
+		exit(exitcode.NothingStaged)
+	}
+
+	commitMsg, err := promptForCommit(commitTypes)
+	if err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Code(err))
+	}
+
+	q.Tree = tree
+	q.Entries = append(q.Entries, queueEntry{Message: commitMsg, Patch: patch})
+	if err := saveQueue(file, q); err != nil {
+		pterm.Error.Println("Failed to write commit queue:", err)
+		exit(exitcode.Failure)
+	}
+	removeDraft()
+
+	pterm.Success.Printfln("Queued commit #%d, keep staging and queueing or run git cc queue apply", len(q.Entries))
+}
+
+func queueList(file string) {
+	q, err := loadQueue(file)
+	if err != nil {
+		pterm.Error.Println("Failed to read commit queue:", err)
+		exit(exitcode.Failure)
+	}
+
+	if len(q.Entries) == 0 {
+		pterm.Info.Println("Commit queue is empty")
+		return
+	}
+
+	for i, entry := range q.Entries {
+		subject := strings.SplitN(entry.Message, "\n", 2)[0]
+		fmt.Printf("%d. %s\n", i+1, subject)
+	}
+}
+
+// queueApply creates the queued commits in order. Entries are removed from
+// the queue once committed so a failed run can be resumed.
+func queueApply(file string) {
+	q, err := loadQueue(file)
+	if err != nil {
+		pterm.Error.Println("Failed to read commit queue:", err)
+		exit(exitcode.Failure)
+	}
+
+	if len(q.Entries) == 0 {
+		pterm.Error.Println("commit queue is empty")
+		exit(exitcode.NothingStaged)
+	}
+
+	head := emptyTree
+	if ref, err := repo.Head(); err == nil {
+		head = ref.Hash().String()
+	}
+	if head != q.Base {
+		pterm.Error.Printfln("HEAD has moved since the queue was started, expected %s", q.Base)
+		exit(exitcode.Failure)
+	}
+
+	// queued changes are re-applied from the patches, so the index may only
+	// hold nothing or exactly what has been queued
+	index, err := gitQuiet("write-tree")
+	if err != nil {
+		pterm.Error.Println("Failed to write index tree:", err)
+		exit(exitcode.Failure)
+	}
+	base, _ := gitQuiet("rev-parse", q.Base+"^{tree}")
+	if index != base && index != q.Tree {
+		pterm.Error.Println("the index has staged changes which aren't queued, commit, queue or unstage them first")
+		exit(exitcode.Failure)
+	}
+	if index != base {
+		if _, err := gitOutput("reset", "--quiet"); err != nil {
+			pterm.Error.Println("Failed to reset index:", err)
+			exit(exitcode.Failure)
+		}
+	}
+
+	for len(q.Entries) > 0 {
+		entry := q.Entries[0]
+
+		if err := applyQueueEntry(entry); err != nil {
+			commitFailed(err)
+		}
+
+		q.Entries = q.Entries[1:]
+		if ref, err := repo.Head(); err == nil {
+			q.Base = ref.Hash().String()
+		}
+		if err := saveQueue(file, q); err != nil {
+			pterm.Error.Println("Failed to write commit queue:", err)
+			exit(exitcode.Failure)
+		}
+	}
+
+	os.Remove(file)
+	pterm.Success.Println("All queued commits applied")
+}
+
+func applyQueueEntry(entry queueEntry) error {
+	patch, err := writeTempFile("queuePatch", entry.Patch)
+	if err != nil {
+		return err
+	}
+	defer atExit(func() { os.Remove(patch) })()
+
+	// a clean working tree (e.g. on another machine) receives the changes as
+	// well, otherwise they are already present and only the index is updated
+	mode := "--index"
+	check := exec.Command("git", "apply", "--check", mode, patch)
+	check.Dir = gitRoot
+	if err := check.Run(); err != nil {
+		mode = "--cached"
+	}
+	if _, err := gitOutput("apply", mode, patch); err != nil {
+		return fmt.Errorf("failed to apply queued changes: %w", err)
+	}
+
+	message, err := writeMessageFile(entry.Message)
+	if err != nil {
+		return err
+	}
+	defer atExit(func() { removeMessageFile(message) })()
+
+	cmd := exec.Command("git", append([]string{"commit", "-F", message}, signingArgs()...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	passTerminal(cmd)
+	return cmd.Run()
+}
+
+func loadQueue(file string) (*commitQueue, error) {
+	q := &commitQueue{}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return q, json.Unmarshal(data, q)
+}
+
+func saveQueue(file string, q *commitQueue) error {
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o644)
+}
+
+// writeTempFile writes content into a new temporary file only the user can
+// read and returns its path. It is created in the git dir unless
+// temp_file_dir says otherwise, as commit messages don't belong into the
+// shared system temp directory.
+func writeTempFile(pattern string, content string) (string, error) {
+	dir, err := tempFileDir()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
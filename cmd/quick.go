@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// quickCommit asks for the type with a single key and the subject only,
+// set by quick and -q
+var quickCommit bool
+
+var quickCmd = &cobra.Command{
+	Use:   "quick",
+	Short: "Commit picking the type with a single key and asking the subject only",
+	Long: `Commit picking the type with a single keypress, such as f for feat or x
+for fix, and asking for the subject only. The message is committed right
+away without the review. The keys are configured by quick_keys, enter lists
+all types instead.
+
+The message is validated like in the full prompts, answers the rules
+require such as a mandatory scope are asked for when it fails. Same as
+git cc -q.`,
+	Example: `  git cc quick
+  git cc -q -a`,
+	Args:    gitCommitArgsOnly,
+	PreRunE: commitFlagsPreRun,
+	Run: func(cmd *cobra.Command, args []string) {
+		quickCommit = true
+		commit(cmd, args)
+	},
+}
+
+func init() {
+	addCommitFlags(quickCmd)
+	rootCmd.AddCommand(quickCmd)
+}
+
+// defaultQuickKeys are the keys of the types in quick commits
+var defaultQuickKeys = map[string]string{
+	"f": "feat",
+	"x": "fix",
+	"c": "chore",
+	"d": "docs",
+	"r": "refactor",
+	"t": "test",
+	"p": "perf",
+	"s": "style",
+	"b": "build",
+	"i": "ci",
+}
+
+// quickTypeKeys returns the keys of quick_keys for the configured types,
+// in the order of the types, warning about keys of more than one character
+func quickTypeKeys(commitTypes []string) (map[rune]string, []rune) {
+	byKey := map[rune]string{}
+	for key, t := range viper.GetStringMapString("quick_keys") {
+		r, size := utf8.DecodeRuneInString(key)
+		if size == 0 || size != len(key) {
+			pterm.Warning.Printfln("Ignoring the quick_keys key %q, use a single character", key)
+			continue
+		}
+		if slices.Contains(commitTypes, t) {
+			byKey[unicode.ToLower(r)] = t
+		}
+	}
+	var keys []rune
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b rune) int {
+		return slices.Index(commitTypes, byKey[a]) - slices.Index(commitTypes, byKey[b])
+	})
+	return byKey, keys
+}
+
+// askQuickType asks for the type with a single key, enter or an unknown
+// type without a key offers the full list
+func askQuickType(commitTypes []string) string {
+	byKey, keys := quickTypeKeys(commitTypes)
+	var legend []string
+	for _, key := range keys {
+		legend = append(legend, string(key)+" "+byKey[key])
+	}
+	label := fmt.Sprintf("%s [%s, %s]", promptLabel("Commit Type"), strings.Join(legend, ", "), tr("enter for all types"))
+	for len(keys) > 0 {
+		key, err := readKey(label)
+		if err != nil || key == '\r' || key == '\n' {
+			break
+		}
+		if t, ok := byKey[unicode.ToLower(key)]; ok {
+			return t
+		}
+	}
+
+	options, optionTypes := typeOptions(commitTypes)
+	selected, _ := ui.Select(promptLabel("Commit Type"), options, "")
+	return optionTypes[selected]
+}
+
+// readKey prints label and reads a single keypress, or the first character
+// of a line in the plain prompts
+func readKey(label string) (rune, error) {
+	if plain, ok := ui.(*plainUI); ok {
+		fmt.Fprintf(plain.out, "%s: ", label)
+		line, err := plain.readLine()
+		if err != nil {
+			return 0, err
+		}
+		if line = strings.TrimSpace(line); line == "" {
+			return '\n', nil
+		}
+		r, _ := utf8.DecodeRuneInString(line)
+		return r, nil
+	}
+
+	pterm.ThemeDefault.PrimaryStyle.Print(label + ": ")
+	fd := int(os.Stdin.Fd())
+	defer awaitAnswer()()
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return 0, err
+	}
+	buf := make([]byte, utf8.UTFMax)
+	n, err := os.Stdin.Read(buf)
+	term.Restore(fd, state)
+	if err != nil {
+		return 0, err
+	}
+	r, _ := utf8.DecodeRune(buf[:n])
+	// ctrl+c doesn't raise SIGINT in raw mode
+	if r == 3 {
+		pterm.Println()
+		interrupted()
+	}
+	if unicode.IsPrint(r) {
+		pterm.Println(string(r))
+	} else {
+		pterm.Println()
+	}
+	return r, nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+)
+
+var (
+	// quiet replaces the messages with a single line for tools when git-cc
+	// fails, set by --quiet
+	quiet bool
+	// quietErrors are the errors printed so far, reported in that line
+	quietErrors []string
+)
+
+// quietWriter collects the messages of the error printer
+type quietWriter struct{}
+
+func (quietWriter) Write(p []byte) (int, error) {
+	if message := strings.Join(strings.Fields(pterm.RemoveColorFromString(string(p))), " "); message != "" {
+		quietErrors = append(quietErrors, message)
+	}
+	return len(p), nil
+}
+
+// useQuietMode silences the informational messages and collects the errors
+func useQuietMode() {
+	pterm.Error = *pterm.Error.WithPrefix(pterm.Prefix{}).WithWriter(quietWriter{})
+	for _, printer := range []*pterm.PrefixPrinter{&pterm.Info, &pterm.Success, &pterm.Warning, &pterm.Description} {
+		*printer = *printer.WithWriter(io.Discard)
+	}
+}
+
+// printQuietError prints the line --quiet reports a failure with to stderr:
+// the exit code, its name and the errors, separated by tabs
+func printQuietError(code int) {
+	message := strings.Join(quietErrors, "; ")
+	if message == "" {
+		message = exitcode.Name(code)
+	}
+	fmt.Fprintf(os.Stderr, "%d\t%s\t%s\n", gitExitCode(code), exitcode.Name(code), message)
+}
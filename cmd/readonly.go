@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// readOnlyAnnotation marks commands which never write to the repository or
+// filesystem, only these run in read-only mode
+const readOnlyAnnotation = "git-cc/read-only"
+
+var readOnlyCommand = map[string]string{readOnlyAnnotation: "true"}
+
+// readOnly reports whether writing is forbidden by --read-only or read_only
+func readOnly() bool {
+	return viper.GetBool("read_only")
+}
+
+// checkReadOnly fails if cmd may write while in read-only mode
+func checkReadOnly(cmd *cobra.Command) error {
+	if readOnly() && cmd.Annotations[readOnlyAnnotation] != "true" {
+		return fmt.Errorf("%s writes to the repository and is not allowed in read-only mode", cmd.CommandPath())
+	}
+	return nil
+}
+
+// refuseWrite exits in read-only mode, for read-only commands with flags
+// that write
+func refuseWrite(flag string) {
+	if readOnly() {
+		pterm.Error.Printfln("%s writes and is not allowed in read-only mode", flag)
+		exit(exitcode.Failure)
+	}
+}
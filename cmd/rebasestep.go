@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// rebaseStep is the command of an interactive rebase git is running its
+// editor for
+type rebaseStep struct {
+	// Command is reword, squash, fixup or edit, spelled out
+	Command string
+	Commit  string
+	Subject string
+	// Num of End commands are done
+	Num, End int
+}
+
+// rebaseCommands spells out the abbreviated commands of a todo list
+var rebaseCommands = map[string]string{"p": "pick", "r": "reword", "e": "edit", "s": "squash", "f": "fixup"}
+
+// currentRebaseStep returns the last command done by the interactive rebase
+// in progress, if there is one
+func currentRebaseStep() (rebaseStep, bool) {
+	dir, err := gitQuiet("rev-parse", "--git-path", "rebase-merge")
+	if err != nil {
+		return rebaseStep{}, false
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitRoot, dir)
+	}
+	done, err := os.ReadFile(filepath.Join(dir, "done"))
+	if err != nil {
+		return rebaseStep{}, false
+	}
+
+	var step rebaseStep
+	for _, line := range strings.Split(string(done), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		command := fields[0]
+		if full, ok := rebaseCommands[command]; ok {
+			command = full
+		}
+		args := fields[1:]
+		// fixup -C and -c use the message of the commit
+		if command == "fixup" && len(args) > 1 && (args[0] == "-C" || args[0] == "-c") {
+			args = args[1:]
+		}
+		step = rebaseStep{Command: command, Commit: args[0], Subject: strings.Join(args[1:], " ")}
+	}
+	if step.Command == "" {
+		return rebaseStep{}, false
+	}
+	step.Num, _ = strconv.Atoi(readTrimmed(filepath.Join(dir, "msgnum")))
+	step.End, _ = strconv.Atoi(readTrimmed(filepath.Join(dir, "end")))
+	return step, true
+}
+
+// String describes the step for the user
+func (s rebaseStep) String() string {
+	commit := s.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	description := fmt.Sprintf("%s %s %s", s.Command, commit, s.Subject)
+	if s.End > 0 {
+		description += fmt.Sprintf(" (%d/%d)", s.Num, s.End)
+	}
+	return strings.TrimSpace(description)
+}
+
+// readTrimmed returns the content of a small state file, empty if it can't
+// be read
+func readTrimmed(path string) string {
+	content, _ := os.ReadFile(path)
+	return strings.TrimSpace(string(content))
+}
+
+// squashMarker starts each message git combines for squash: "This is the
+// 1st commit message:", "This is the commit message #2:"
+var squashMarker = regexp.MustCompile(`^ This is the (\d+\w\w commit message|commit message #\d+):$`)
+
+// squashedMessages splits the combined message git prepares for squash into
+// the messages of its commits, nil if content isn't one
+func squashedMessages(content string) []string {
+	lines := strings.Split(content, "\n")
+	comment := commentChar(lines)
+	var messages []string
+	var current []string
+	inMessage := false
+	flush := func() {
+		if inMessage {
+			if message := stripComments(strings.Join(current, "\n")); message != "" {
+				messages = append(messages, message)
+			}
+		}
+		current = nil
+	}
+	for _, line := range lines {
+		if line == comment+scissors {
+			break
+		}
+		if rest, ok := strings.CutPrefix(line, comment); ok && squashMarker.MatchString(rest) {
+			flush()
+			inMessage = true
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	if len(messages) < 2 {
+		return nil
+	}
+	return messages
+}
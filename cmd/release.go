@@ -0,0 +1,600 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Version, commit, tag and push a release",
+	Long: `Release the changes since the latest semver tag: the next version is
+computed as by next-version, written into the release_files in place of
+the latest one and added to the release_changelog, CHANGELOG.md by default.
+The changed files are committed as chore(release), the commit is tagged
+with an annotated tag and both are pushed to the push remote of the branch
+in one atomic push.
+
+For tools distributing binaries, the Homebrew formula and Scoop manifest
+named by homebrew_formula and scoop_manifest are updated as well: the
+version is replaced and the checksum following each download URL is set to
+the SHA-256 of the release artifact of the same file name among the files
+matching release_artifacts, so build them before releasing.
+
+The working tree must not have changes to tracked files, as those would
+end up in the release commit. --dry-run prints the plan instead: the
+version, the changes to the files, the release commit and tag, the release
+added to the changelog and where it is pushed to, without changing
+anything.
+
+Each step done is recorded in a journal in the git dir. When a step fails,
+e.g. the push on a flaky network, running git cc release again resumes the
+same release at the failed step instead of computing a new one. --rollback
+undoes the steps done instead: the tag is deleted, the release commit
+removed and the files restored.
+
+In a monorepo, release_packages lists the package directories to release
+instead, each by its own tags such as core/v1.2.0, in the order of the
+dependencies read from their go.mod and package.json. Packages depending
+on a package released get its new version and are released as a patch at
+least.`,
+	Example: `  git cc release
+  git cc release --prerelease rc
+  git cc release --dry-run
+  git cc release --rollback`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         release,
+}
+
+func init() {
+	releaseCmd.Flags().String("prerelease", "", "Release a prerelease version with this identifier, e.g. rc for v1.3.0-rc.1")
+	releaseCmd.Flags().Bool("no-push", false, "Create the release commit and tag without pushing them")
+	releaseCmd.Flags().Bool("dry-run", false, "Print what the release would change without changing anything")
+	releaseCmd.Flags().Bool("rollback", false, "Undo the steps done by a release which failed halfway")
+	releaseCmd.MarkFlagsMutuallyExclusive("rollback", "dry-run")
+	rootCmd.AddCommand(releaseCmd)
+}
+
+// releasePlan is everything a release changes, computed before any of it
+// is done
+type releasePlan struct {
+	// Releases are the repository or the packages released, in order
+	Releases []versionRelease `json:"releases"`
+	Files    []releaseFile    `json:"files"`
+	// Changelogs are the paths of the changelogs among the Files
+	Changelogs []string `json:"changelogs"`
+	// CommitMessage is empty when no file changes
+	CommitMessage string `json:"commit_message"`
+	// Remote and Refs are pushed to, Remote is empty with --no-push
+	Remote string   `json:"remote"`
+	Refs   []string `json:"refs"`
+}
+
+// versionRelease is the release of the repository or of a package of it
+type versionRelease struct {
+	// Package is the directory of the package, empty for the repository
+	Package string `json:"package,omitempty"`
+	// Version is written into the files, the tag without its prefix
+	Version string `json:"version"`
+	// Previous is the version of the latest release, which is replaced
+	Previous   string `json:"previous"`
+	Tag        string `json:"tag"`
+	TagMessage string `json:"tag_message"`
+}
+
+// releaseFile is a file changed by a release, by its path in the repository
+type releaseFile struct {
+	Path string `json:"path"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+func release(cmd *cobra.Command, args []string) {
+	prerelease, _ := cmd.Flags().GetString("prerelease")
+	noPush, _ := cmd.Flags().GetBool("no-push")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	rollback, _ := cmd.Flags().GetBool("rollback")
+	if !dryRun {
+		refuseWrite("release without --dry-run")
+	}
+
+	journal, err := loadReleaseJournal()
+	if err != nil {
+		pterm.Error.Println("Failed to read the release journal:", err)
+		exit(exitcode.Failure)
+	}
+	if rollback {
+		if journal == nil {
+			pterm.Error.Println("no release in progress to roll back")
+			exit(exitcode.Failure)
+		}
+		if err := rollbackRelease(journal); err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Failure)
+		}
+		pterm.Success.Printfln("Rolled back the release of %s", strings.Join(journal.Plan.tags(), ", "))
+		return
+	}
+	if journal != nil {
+		// the plan of the release in progress is kept, whatever changed since
+		if err := journal.checkResumable(); err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Failure)
+		}
+		pterm.Info.Printfln("Resuming the release of %s after the steps done: %s", strings.Join(journal.Plan.tags(), ", "), strings.Join(journal.Done, ", "))
+	} else {
+		plan, err := planRelease(prerelease, !noPush)
+		if err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Failure)
+		}
+		head, _ := gitQuiet("rev-parse", "HEAD")
+		journal = &releaseJournal{Plan: plan, Head: head}
+	}
+	if dryRun {
+		printReleasePlan(journal.Plan)
+		return
+	}
+	if err := runRelease(journal); err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+	pterm.Success.Printfln("Released %s, publish its notes with git cc release-notes --publish", strings.Join(journal.Plan.tags(), ", "))
+}
+
+// planRelease computes the next release and the changes making it
+func planRelease(prerelease string, push bool) (*releasePlan, error) {
+	if changes, err := gitQuiet("status", "--porcelain", "--untracked-files=no"); err != nil {
+		return nil, fmt.Errorf("failed to read the status: %w", err)
+	} else if changes != "" {
+		return nil, errors.New("the working tree has uncommitted changes, commit or stash them first")
+	}
+	branch, err := gitQuiet("symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return nil, errors.New("HEAD is detached, check out the branch to release")
+	}
+
+	plan := &releasePlan{}
+	if packages := viper.GetStringSlice("release_packages"); len(packages) > 0 {
+		err = plan.addPackageReleases(packages, prerelease)
+	} else {
+		err = plan.addRepositoryRelease(prerelease)
+	}
+	if err != nil {
+		return nil, err
+	}
+	plan.Files = slices.DeleteFunc(plan.Files, func(file releaseFile) bool { return file.Old == file.New })
+
+	if len(plan.Files) > 0 {
+		plan.CommitMessage = "chore(release): " + strings.Join(plan.tags(), ", ")
+	}
+	if push {
+		if plan.Remote = pushRemote(branch); plan.Remote == "" {
+			pterm.Warning.Println("Not pushing, the repository has no remote")
+		} else {
+			plan.Refs = []string{"refs/heads/" + branch}
+			for _, tag := range plan.tags() {
+				plan.Refs = append(plan.Refs, "refs/tags/"+tag)
+			}
+		}
+	}
+	return plan, nil
+}
+
+// addRepositoryRelease plans the release of the repository as a whole
+func (p *releasePlan) addRepositoryRelease(prerelease string) error {
+	bump, err := computeNextVersion("", "", "", prerelease)
+	if err != nil {
+		return fmt.Errorf("failed to compute the next version: %w", err)
+	}
+	if !bump.Bumped {
+		return fmt.Errorf("nothing to release, no features, fixes or breaking changes since %s", bump.Latest)
+	}
+	release := p.addRelease("", bump)
+
+	for _, file := range viper.GetStringSlice("release_files") {
+		err := p.editFile(file, func(content string) (string, error) {
+			if !strings.Contains(content, release.Previous) {
+				return "", fmt.Errorf("no version %s to replace", release.Previous)
+			}
+			return strings.ReplaceAll(content, release.Previous, release.Version), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if file := viper.GetString("release_changelog"); file != "" {
+		if err := p.addChangelog(file, release, bump.Commits); err != nil {
+			return err
+		}
+	}
+
+	var sums map[string]string
+	for _, file := range []string{viper.GetString("homebrew_formula"), viper.GetString("scoop_manifest")} {
+		if file == "" {
+			continue
+		}
+		if sums == nil {
+			if sums, err = artifactChecksums(viper.GetString("release_artifacts")); err != nil {
+				return fmt.Errorf("failed to read the release artifacts: %w", err)
+			}
+		}
+		err := p.editFile(file, func(content string) (string, error) {
+			if !strings.Contains(content, release.Previous) {
+				return "", fmt.Errorf("no version %s to replace", release.Previous)
+			}
+			return updateManifest(content, release.Previous, release.Version, sums)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addPackageReleases plans the releases of the packages of a monorepo in
+// the directories dirs, each after the packages it depends on. A package
+// depending on a package released is released too, as a patch at least,
+// with its references to the new versions updated.
+func (p *releasePlan) addPackageReleases(dirs []string, prerelease string) error {
+	var packages []releasePackage
+	for _, dir := range dirs {
+		pkg, err := readReleasePackage(dir)
+		if err != nil {
+			return err
+		}
+		packages = append(packages, pkg)
+	}
+	packages, err := releaseOrder(packages)
+	if err != nil {
+		return err
+	}
+
+	// the versions released by package name
+	released := map[string]string{}
+	for _, pkg := range packages {
+		updated := false
+		for _, name := range pkg.Dependencies {
+			version, ok := released[name]
+			if !ok {
+				continue
+			}
+			updated = true
+			for _, manifest := range []string{"go.mod", "package.json"} {
+				err := p.editFile(path.Join(pkg.Path, manifest), func(content string) (string, error) {
+					return updateDependencyVersion(manifest, content, name, version), nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		bump, err := computeNextVersion(pkg.Path+"/", pkg.Path, "", prerelease)
+		if err != nil {
+			return fmt.Errorf("failed to compute the next version of %s: %w", pkg.Path, err)
+		}
+		if !bump.Bumped && !updated {
+			continue
+		}
+		if !bump.Bumped {
+			// new versions of its dependencies make a patch release
+			bump.Next = bump.Latest
+			bump.Next.Patch++
+			bump.Next.Prerelease, bump.Next.Build = "", ""
+			if prerelease != "" {
+				tags, err := semverTags("HEAD", pkg.Path+"/")
+				if err != nil {
+					return fmt.Errorf("listing tags: %w", err)
+				}
+				bump.Next = nextPrerelease(bump.Next, prerelease, tags)
+			}
+		}
+		release := p.addRelease(pkg.Path, bump)
+		for _, name := range pkg.Names {
+			released[name] = release.Version
+		}
+
+		err = p.editFile(path.Join(pkg.Path, "package.json"), func(content string) (string, error) {
+			return updatePackageVersion(content, release.Version), nil
+		})
+		if err != nil {
+			return err
+		}
+		if file := viper.GetString("release_changelog"); file != "" && bump.Bumped {
+			if err := p.addChangelog(path.Join(pkg.Path, file), release, bump.Commits); err != nil {
+				return err
+			}
+		}
+	}
+	if len(p.Releases) == 0 {
+		return fmt.Errorf("nothing to release, no features, fixes or breaking changes in %s", strings.Join(dirs, ", "))
+	}
+	return nil
+}
+
+// addRelease adds the release of bump of the package in the directory pkg
+func (p *releasePlan) addRelease(pkg string, bump versionBump) versionRelease {
+	release := versionRelease{
+		Package:    pkg,
+		Version:    bareVersion(bump.Next),
+		Previous:   bareVersion(bump.Latest),
+		Tag:        bump.Next.String(),
+		TagMessage: "Release " + bump.Next.String(),
+	}
+	p.Releases = append(p.Releases, release)
+	return release
+}
+
+// addChangelog adds the release of commits to the changelog file
+func (p *releasePlan) addChangelog(file string, release versionRelease, commits []conventionalCommit) error {
+	section := releaseChangelog(release.Version, commits)
+	p.Changelogs = append(p.Changelogs, file)
+	return p.editFile(file, func(content string) (string, error) {
+		return mergeChangelog(content, section), nil
+	})
+}
+
+// editFile changes file by edit, which is given the content planned so
+// far. A file which doesn't exist is empty.
+func (p *releasePlan) editFile(file string, edit func(content string) (string, error)) error {
+	i := slices.IndexFunc(p.Files, func(f releaseFile) bool { return f.Path == file })
+	if i < 0 {
+		content, err := os.ReadFile(filepath.Join(gitRoot, file))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		p.Files = append(p.Files, releaseFile{Path: file, Old: string(content), New: string(content)})
+		i = len(p.Files) - 1
+	}
+	updated, err := edit(p.Files[i].New)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	p.Files[i].New = updated
+	return nil
+}
+
+// tags returns the tags of the releases
+func (p *releasePlan) tags() []string {
+	tags := make([]string, len(p.Releases))
+	for i, release := range p.Releases {
+		tags[i] = release.Tag
+	}
+	return tags
+}
+
+// printReleasePlan prints what runRelease would do with plan
+func printReleasePlan(plan *releasePlan) {
+	pterm.DefaultSection.Printfln("Release %s", strings.Join(plan.tags(), ", "))
+	for _, release := range plan.Releases {
+		name := "Version"
+		if release.Package != "" {
+			name = release.Package + " version"
+		}
+		fmt.Printf("%s %s, after %s\n", name, release.Version, release.Previous)
+	}
+
+	for _, file := range plan.Files {
+		if slices.Contains(plan.Changelogs, file.Path) {
+			continue
+		}
+		pterm.DefaultSection.Println(file.Path)
+		printLineDiff(file.Old, file.New)
+	}
+	if plan.CommitMessage != "" {
+		pterm.DefaultSection.Println("Commit")
+		fmt.Println(plan.CommitMessage)
+	}
+	pterm.DefaultSection.Println("Tag")
+	for _, release := range plan.Releases {
+		fmt.Printf("%s, annotated with %q\n", release.Tag, release.TagMessage)
+	}
+
+	for _, file := range plan.Files {
+		if slices.Contains(plan.Changelogs, file.Path) {
+			pterm.DefaultSection.Printfln("Changelog %s", file.Path)
+			printLineDiff(file.Old, file.New)
+		}
+	}
+
+	pterm.DefaultSection.Println("Push")
+	if plan.Remote == "" {
+		fmt.Println("Nothing is pushed")
+	} else {
+		fmt.Printf("git push --atomic %s %s\n", plan.Remote, strings.Join(plan.Refs, " "))
+	}
+}
+
+// releaseSteps make a release in order, each is recorded in the journal
+// once done. undo reverts a step done, the push is the last step and can't
+// fail halfway.
+var releaseSteps = []struct {
+	name string
+	run  func(plan *releasePlan) error
+	undo func(journal *releaseJournal) error
+}{
+	{"files", writeReleaseFiles, restoreReleaseFiles},
+	{"commit", commitRelease, uncommitRelease},
+	{"tag", tagRelease, untagRelease},
+	{"push", pushRelease, nil},
+}
+
+// runRelease runs the steps of the release the journal hasn't recorded as
+// done yet, recording each one done. The journal is removed once the
+// release is complete.
+func runRelease(journal *releaseJournal) error {
+	// a release failing at its first step is resumed or rolled back too
+	if err := saveReleaseJournal(journal); err != nil {
+		return fmt.Errorf("failed to write the release journal: %w", err)
+	}
+	for _, step := range releaseSteps {
+		if slices.Contains(journal.Done, step.name) {
+			continue
+		}
+		if err := step.run(journal.Plan); err != nil {
+			return err
+		}
+		journal.Done = append(journal.Done, step.name)
+		if err := saveReleaseJournal(journal); err != nil {
+			return fmt.Errorf("failed to write the release journal: %w", err)
+		}
+	}
+	return os.Remove(releaseJournalPath())
+}
+
+// rollbackRelease undoes the steps the journal has recorded as done and
+// the step which failed after them, the latest first, and removes the
+// journal
+func rollbackRelease(journal *releaseJournal) error {
+	for i := min(len(journal.Done), len(releaseSteps)-1); i >= 0; i-- {
+		if undo := releaseSteps[i].undo; undo != nil {
+			if err := undo(journal); err != nil {
+				return err
+			}
+		}
+		journal.Done = journal.Done[:min(i, len(journal.Done))]
+		if err := saveReleaseJournal(journal); err != nil {
+			return fmt.Errorf("failed to write the release journal: %w", err)
+		}
+	}
+	return os.Remove(releaseJournalPath())
+}
+
+func writeReleaseFiles(plan *releasePlan) error {
+	for _, file := range plan.Files {
+		if err := os.WriteFile(filepath.Join(gitRoot, file.Path), []byte(file.New), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+// restoreReleaseFiles unstages the files of the release and restores their
+// content, removing the files the release created
+func restoreReleaseFiles(journal *releaseJournal) error {
+	for _, file := range journal.Plan.Files {
+		if _, err := gitQuiet("reset", "--quiet", journal.Head, "--", file.Path); err != nil {
+			return fmt.Errorf("failed to unstage %s: %w", file.Path, err)
+		}
+		path := filepath.Join(gitRoot, file.Path)
+		if _, err := gitQuiet("cat-file", "-e", journal.Head+":"+file.Path); err != nil && file.Old == "" {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(path, []byte(file.Old), 0o644); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+func commitRelease(plan *releasePlan) error {
+	if plan.CommitMessage == "" {
+		return nil
+	}
+	paths := make([]string, len(plan.Files))
+	for i, file := range plan.Files {
+		paths[i] = file.Path
+	}
+	if err := runReleaseGit(append([]string{"add", "--"}, paths...)...); err != nil {
+		return fmt.Errorf("failed to stage the release: %w", err)
+	}
+	if err := runReleaseGit(append([]string{"commit", "--message", plan.CommitMessage}, signingArgs()...)...); err != nil {
+		return fmt.Errorf("failed to commit the release: %w", err)
+	}
+	return nil
+}
+
+// uncommitRelease removes the release commit, if it was made, keeping
+// other changes of the working tree
+func uncommitRelease(journal *releaseJournal) error {
+	head, _ := gitQuiet("rev-parse", "HEAD")
+	if journal.Plan.CommitMessage == "" || head == journal.Head {
+		return nil
+	}
+	if parent, _ := gitQuiet("rev-parse", "HEAD^"); parent != journal.Head {
+		return fmt.Errorf("HEAD isn't the release commit of %s anymore, remove it yourself and then %s", strings.Join(journal.Plan.tags(), ", "), releaseJournalPath())
+	}
+	if _, err := gitOutput("reset", "--quiet", "--keep", journal.Head); err != nil {
+		return fmt.Errorf("failed to remove the release commit: %w", err)
+	}
+	return nil
+}
+
+// tagRelease tags HEAD with the tags of the releases, but those a failed
+// run already created
+func tagRelease(plan *releasePlan) error {
+	for _, release := range plan.Releases {
+		if taggedHead(release.Tag) {
+			continue
+		}
+		if err := runReleaseGit("tag", "--annotate", release.Tag, "--message", release.TagMessage); err != nil {
+			return fmt.Errorf("failed to create tag %s: %w", release.Tag, err)
+		}
+	}
+	return nil
+}
+
+// untagRelease deletes the tags of the releases created on HEAD
+func untagRelease(journal *releaseJournal) error {
+	for _, tag := range journal.Plan.tags() {
+		if !taggedHead(tag) {
+			continue
+		}
+		if _, err := gitOutput("tag", "--delete", tag); err != nil {
+			return fmt.Errorf("failed to delete tag %s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// taggedHead reports whether tag exists and points at HEAD
+func taggedHead(tag string) bool {
+	commit, err := gitQuiet("rev-parse", "--verify", "--quiet", "refs/tags/"+tag+"^{commit}")
+	head, _ := gitQuiet("rev-parse", "HEAD")
+	return err == nil && commit == head
+}
+
+func pushRelease(plan *releasePlan) error {
+	if plan.Remote == "" {
+		return nil
+	}
+	if err := runReleaseGit(append([]string{"push", "--atomic", plan.Remote}, plan.Refs...)...); err != nil {
+		return fmt.Errorf("failed to push the release, run git cc release again once the problem is solved or undo it with git cc release --rollback: %w", err)
+	}
+	return nil
+}
+
+// runReleaseGit runs git in the repository root, printing what it prints to
+// stderr and asking for credentials on the terminal
+func runReleaseGit(args ...string) error {
+	git := exec.Command("git", args...)
+	git.Dir = gitRoot
+	git.Stdout = os.Stderr
+	git.Stderr = os.Stderr
+	passTerminal(git)
+	return git.Run()
+}
+
+// bareVersion returns v without the tag prefix, as written into files
+func bareVersion(v semVersion) string {
+	v.Prefix = ""
+	return v.String()
+}
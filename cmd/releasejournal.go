@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// releaseJournal records a release in progress and its steps done so far,
+// so a release failing halfway resumes where it stopped
+type releaseJournal struct {
+	Plan *releasePlan `json:"plan"`
+	// Head is the commit the release started on
+	Head string   `json:"head"`
+	Done []string `json:"done"`
+}
+
+func releaseJournalPath() string {
+	return filepath.Join(gitDir(), "git-cc", "release.json")
+}
+
+// loadReleaseJournal returns the journal of the release in progress, nil
+// when there is none
+func loadReleaseJournal() (*releaseJournal, error) {
+	data, err := os.ReadFile(releaseJournalPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	journal := &releaseJournal{}
+	if err := json.Unmarshal(data, journal); err != nil {
+		return nil, err
+	}
+	if journal.Plan == nil {
+		return nil, fmt.Errorf("%s holds no release", releaseJournalPath())
+	}
+	return journal, nil
+}
+
+func saveReleaseJournal(journal *releaseJournal) error {
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(releaseJournalPath()), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(releaseJournalPath(), data, 0o644)
+}
+
+// checkResumable returns an error when HEAD moved since the release
+// started, other than by the release commit
+func (j *releaseJournal) checkResumable() error {
+	rev := "HEAD"
+	if slices.Contains(j.Done, "commit") && j.Plan.CommitMessage != "" {
+		// the release commit is on top of where the release started
+		rev = "HEAD^"
+	}
+	if head, _ := gitQuiet("rev-parse", rev); head != j.Head {
+		return fmt.Errorf("HEAD moved since the release of %s started, remove %s to start over", strings.Join(j.Plan.tags(), ", "), releaseJournalPath())
+	}
+	return nil
+}
@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// releaseNoteSections are the sections of the release notes by commit type,
+// in the order they are listed, as conventional-changelog names them
+var releaseNoteSections = []struct{ Type, Title string }{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"revert", "Reverts"},
+}
+
+// defaultReleaseNotesTemplate renders Markdown for GitHub and GitLab releases
+const defaultReleaseNotesTemplate = `{{define "entry"}}{{if .Scope}}**{{.Scope}}:** {{end}}{{.Description}} ({{if .URL}}[{{.ShortHash}}]({{.URL}}){{else}}{{.ShortHash}}{{end}}{{if .PullRequest}}, {{if .PullRequestURL}}[#{{.PullRequest}}]({{.PullRequestURL}}){{else}}#{{.PullRequest}}{{end}}{{end}}){{end -}}
+{{if .Breaking}}## Breaking Changes
+
+{{range .Breaking}}- {{template "entry" .}}{{range .BreakingNotes}}
+  {{.}}{{end}}
+{{end}}
+{{end}}{{range .Sections}}## {{.Title}}
+
+{{range .Entries}}- {{template "entry" .}}
+{{end}}
+{{end}}{{if .Contributors}}## Contributors
+
+{{range .Contributors}}- {{.}}
+{{end}}
+{{end}}{{if .NewContributors}}## New Contributors
+
+{{range .NewContributors}}- {{.Name}} made their first contribution in {{with .First}}{{if .PullRequest}}{{if .PullRequestURL}}[#{{.PullRequest}}]({{.PullRequestURL}}){{else}}#{{.PullRequest}}{{end}}{{else if .URL}}[{{.ShortHash}}]({{.URL}}){{else}}{{.ShortHash}}{{end}}{{end}}
+{{end}}
+{{end}}{{if .CompareURL}}**Full Changelog**: {{.CompareURL}}
+{{end}}`
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "release-notes",
+	Short: "Render grouped release notes through a template",
+	Long: `Render the release notes of the conventional commits between two refs
+through a Go text/template, ready to be pasted into GitHub or GitLab
+releases. The default template lists the breaking changes, the features,
+bug fixes, performance improvements and reverts, with links to the commits
+and pull requests, the contributors and those contributing for the first
+time.
+
+The template is given by --template or release_notes_template. It gets the
+release as ., see the manual page for its fields, and the functions join,
+upper and lower. Commit and pull request links point to repository_url, by
+default the web page of the origin remote.
+
+--publish creates the GitHub or GitLab release of the tag on --to with the
+notes, or updates it, instead of printing them. It uses the token issues
+are listed with: GITHUB_TOKEN, GH_TOKEN or GITLAB_TOKEN, issue_token or the
+credentials of git and the gh and glab CLIs.`,
+	Example: `  git cc release-notes
+  git cc release-notes --from v1.2.0 --to v1.3.0
+  git cc release-notes --template .github/release-notes.tmpl | gh release create v1.3.0 -F -
+  git cc release-notes --to v1.3.0 --publish`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         releaseNotes,
+}
+
+func init() {
+	releaseNotesCmd.Flags().String("from", "", "List commits after this ref (default: latest tag)")
+	releaseNotesCmd.Flags().String("to", "HEAD", "List commits up to this ref")
+	releaseNotesCmd.Flags().String("release", "", "Name of the release (default: the tag on --to, otherwise Unreleased)")
+	releaseNotesCmd.Flags().String("template", "", "Go text/template `file` to render (default release_notes_template or the built-in one)")
+	releaseNotesCmd.Flags().Bool("publish", false, "Create or update the GitHub or GitLab release of the tag on --to instead of printing the notes")
+	rootCmd.AddCommand(releaseNotesCmd)
+}
+
+// releaseNotesData is what release notes templates render
+type releaseNotesData struct {
+	Release string
+	// Date of the last commit, empty for unreleased changes
+	Date     string
+	From, To string
+	// Sections holds the entries of releaseNoteSections which have any
+	Sections []releaseSection
+	// Breaking are the breaking changes of all types
+	Breaking []releaseEntry
+	// Types holds the entries of every type, for templates grouping
+	// differently
+	Types map[string][]releaseEntry
+	// Commits are all conventional commits, oldest first
+	Commits []releaseEntry
+	// Contributors are the authors in the order of their first commit, as
+	// .mailmap names them, dependency bots left out
+	Contributors []string
+	// NewContributors are the contributors whose first commit is part of
+	// the release, in its order
+	NewContributors []newContributor
+	RepositoryURL   string
+	CompareURL      string
+}
+
+// newContributor is a contributor of the release who never committed before
+type newContributor struct {
+	Name string
+	// First is their first commit
+	First releaseEntry
+}
+
+type releaseSection struct {
+	Type    string
+	Title   string
+	Entries []releaseEntry
+}
+
+type releaseEntry struct {
+	Type        string
+	Scope       string
+	Description string
+	Body        string
+	Breaking    bool
+	// BreakingNotes are the notes of the BREAKING CHANGE footers
+	BreakingNotes []string
+	Hash          string
+	ShortHash     string
+	Author        string
+	// URL links to the commit, empty without repository_url
+	URL string
+	// PullRequest is the number of the pull request the commit was merged
+	// with, a squash merge mentions it as (#123) in the header
+	PullRequest    string
+	PullRequestURL string
+	// DependencyBot names the renovate or dependabot bot which made the
+	// commit
+	DependencyBot string
+	// Dependency is set for dependency updates, those of the bots and build
+	// and chore commits scoped deps
+	Dependency bool
+	// FirstContribution is set for the first commit of an author who never
+	// committed before the release
+	FirstContribution bool
+}
+
+func releaseNotes(cmd *cobra.Command, args []string) {
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+	release, _ := cmd.Flags().GetString("release")
+	templateFile, _ := cmd.Flags().GetString("template")
+	publish, _ := cmd.Flags().GetBool("publish")
+	if publish {
+		refuseWrite("--publish")
+	}
+	if !cmd.Flags().Changed("from") {
+		from = previousTag(to)
+	}
+	if templateFile == "" {
+		templateFile = viper.GetString("release_notes_template")
+	}
+
+	text := defaultReleaseNotesTemplate
+	if templateFile != "" {
+		content, err := os.ReadFile(templateFile)
+		if err != nil {
+			pterm.Error.Println("Failed to read template:", err)
+			exit(exitcode.Failure)
+		}
+		text = string(content)
+	}
+	funcs := template.FuncMap{"join": strings.Join, "upper": strings.ToUpper, "lower": strings.ToLower}
+	tmpl, err := template.New("release-notes").Funcs(funcs).Parse(text)
+	if err != nil {
+		pterm.Error.Println("Invalid template:", err)
+		exit(exitcode.Failure)
+	}
+
+	data, err := releaseNotesOf(from, to, release)
+	if err != nil {
+		pterm.Error.Println("Failed to read history:", err)
+		exit(exitcode.Failure)
+	}
+	// nothing is printed when the template fails half way
+	var notes strings.Builder
+	if err := tmpl.Execute(&notes, data); err != nil {
+		pterm.Error.Println("Failed to render the template:", err)
+		exit(exitcode.Failure)
+	}
+	if !publish {
+		fmt.Print(notes.String())
+		return
+	}
+
+	tag := releaseTag(to)
+	if tag == "" {
+		pterm.Error.Printfln("%s isn't tagged, --publish needs the tag of the release", to)
+		exit(exitcode.Failure)
+	}
+	name := release
+	if name == "" {
+		name = tag
+	}
+	page, err := publishRelease(tag, name, notes.String())
+	if err != nil {
+		pterm.Error.Println("Failed to publish the release:", err)
+		exit(exitcode.Failure)
+	}
+	pterm.Success.Printfln("Published release %s %s", name, page)
+}
+
+// releaseNotesOf collects the release notes of the commits between from
+// and to
+func releaseNotesOf(from, to, release string) (releaseNotesData, error) {
+	commits, err := commitRange(from, to)
+	if err != nil {
+		return releaseNotesData{}, err
+	}
+	// the history is newest first, notes read oldest first
+	slices.Reverse(commits)
+
+	data := releaseNotesData{
+		Release:       releaseName(to, release),
+		From:          from,
+		To:            to,
+		Types:         map[string][]releaseEntry{},
+		RepositoryURL: repositoryURL(),
+	}
+	if data.Release != "Unreleased" && len(commits) > 0 {
+		data.Date = commits[len(commits)-1].Commit.Committer.When.Format("2006-01-02")
+	}
+	if data.RepositoryURL != "" && from != "" {
+		if hash, err := gitQuiet("rev-parse", to); err == nil {
+			target := to
+			if to == "HEAD" {
+				target = hash
+			}
+			data.CompareURL = webURL(data.RepositoryURL, "compare/"+from+"..."+target)
+		}
+	}
+
+	// authors who committed before from aren't new
+	previous, err := previousAuthors(from)
+	if err != nil {
+		return data, err
+	}
+	authors := make([]string, len(commits))
+	for i, c := range commits {
+		authors[i] = c.Commit.Author.Name + " <" + c.Commit.Author.Email + ">"
+	}
+	authors = mailmapIdentities(authors)
+
+	var dependencies []releaseEntry
+	prs := pullRequestsOf(commits)
+	for i, c := range commits {
+		if !c.Valid && c.DependencyBot == "" {
+			continue
+		}
+		entry := releaseEntryOf(c, data.RepositoryURL, prs)
+		name, email := splitIdentity(authors[i])
+		if c.DependencyBot == "" && !excludedContributor(name, email) && !previous[strings.ToLower(name)] && !previous[strings.ToLower(email)] {
+			entry.FirstContribution = true
+			data.NewContributors = append(data.NewContributors, newContributor{Name: name, First: entry})
+			previous[strings.ToLower(name)], previous[strings.ToLower(email)] = true, true
+		}
+		data.Commits = append(data.Commits, entry)
+		data.Types[entry.Type] = append(data.Types[entry.Type], entry)
+		if entry.Breaking {
+			data.Breaking = append(data.Breaking, entry)
+		}
+		if entry.Dependency {
+			dependencies = append(dependencies, entry)
+			if entry.DependencyBot != "" {
+				continue
+			}
+		}
+		if !slices.Contains(data.Contributors, name) {
+			data.Contributors = append(data.Contributors, name)
+		}
+	}
+
+	for _, s := range releaseNoteSections {
+		// dependency updates get their own section
+		entries := slices.DeleteFunc(slices.Clone(data.Types[s.Type]), func(e releaseEntry) bool {
+			return e.Dependency
+		})
+		if len(entries) == 0 {
+			continue
+		}
+		// unscoped entries first, then grouped by scope
+		slices.SortStableFunc(entries, func(a, b releaseEntry) int {
+			return strings.Compare(a.Scope, b.Scope)
+		})
+		data.Sections = append(data.Sections, releaseSection{Type: s.Type, Title: s.Title, Entries: entries})
+	}
+	if len(dependencies) > 0 {
+		data.Sections = append(data.Sections, releaseSection{Type: "deps", Title: "Dependencies", Entries: dependencies})
+	}
+	return data, nil
+}
+
+func releaseEntryOf(c conventionalCommit, repoURL string, prs pullRequests) releaseEntry {
+	hash := c.Commit.Hash.String()
+	entry := releaseEntry{
+		Type:          c.Data.Type,
+		Scope:         c.Data.Scope,
+		Body:          c.Body,
+		Breaking:      c.Data.BreakingChange,
+		BreakingNotes: c.BreakingNotes(),
+		Hash:          hash,
+		ShortHash:     hash[:7],
+		Author:        c.Commit.Author.Name,
+		DependencyBot: c.DependencyBot,
+		Dependency:    c.DependencyUpdate(),
+	}
+	entry.Description, entry.PullRequest = prs.of(c)
+	entry.PullRequestURL = pullRequestURL(repoURL, entry.PullRequest)
+	if repoURL != "" {
+		entry.URL = webURL(repoURL, "commit/"+hash)
+	}
+	return entry
+}
+
+// releaseName returns release, or the tag on to without its v or
+// Unreleased when it is empty
+func releaseName(to, release string) string {
+	if release != "" {
+		return release
+	}
+	// a tag on to itself names the release
+	if tag := describeTag(to); tag != "" && tag != previousTag(to) {
+		return strings.TrimPrefix(tag, "v")
+	}
+	return "Unreleased"
+}
+
+// scpLikeURL matches the user@host:path remotes of ssh
+var scpLikeURL = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// repositoryURL returns repository_url, or the web page of the origin
+// remote, empty if there is none
+func repositoryURL() string {
+	if configured := viper.GetString("repository_url"); configured != "" {
+		return strings.TrimSuffix(configured, "/")
+	}
+	remote, err := gitQuiet("remote", "get-url", "origin")
+	if err != nil || remote == "" {
+		return ""
+	}
+
+	var host, path string
+	if scheme, rest, ok := strings.Cut(remote, "://"); ok {
+		if scheme != "https" && scheme != "http" && scheme != "ssh" && scheme != "git" {
+			return ""
+		}
+		host, path, _ = strings.Cut(rest, "/")
+		// user and port aren't part of the web address
+		if _, after, found := strings.Cut(host, "@"); found {
+			host = after
+		}
+		if scheme != "https" && scheme != "http" {
+			host, _, _ = strings.Cut(host, ":")
+		}
+	} else if match := scpLikeURL.FindStringSubmatch(remote); match != nil {
+		host, path = match[1], match[2]
+	} else {
+		// a local path
+		return ""
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return ""
+	}
+	return "https://" + host + "/" + path
+}
+
+// webURL returns the address of a page of the repository at repoURL, in
+// the layout of GitLab or else GitHub
+func webURL(repoURL, page string) string {
+	if strings.Contains(repoURL, "gitlab") {
+		// GitLab calls pull requests merge requests
+		page = strings.Replace(page, "pull/", "merge_requests/", 1)
+		return fmt.Sprintf("%s/-/%s", repoURL, page)
+	}
+	return fmt.Sprintf("%s/%s", repoURL, page)
+}
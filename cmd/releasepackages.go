@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	goModulePattern = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+	// replace example.com/core => ../core, in replace blocks too
+	goReplacePattern = regexp.MustCompile(`^\s*(?:replace\s+)?([^\s()]+)(?:\s+v\S+)?\s+=>`)
+	// the version of package.json itself, indented once at most
+	packageVersionPattern = regexp.MustCompile(`(?m)^[ \t]{0,4}"version"\s*:\s*"([^"]*)"`)
+)
+
+// releasePackage is a package of a monorepo released by its own tags
+type releasePackage struct {
+	// Path is the directory of the package, its tags start with Path/
+	Path string
+	// Names are the Go module path and npm package name of the package
+	Names []string
+	// Dependencies are the names of the packages it depends on
+	Dependencies []string
+}
+
+// readReleasePackage reads the names and dependencies of the package in dir
+// from its go.mod and package.json
+func readReleasePackage(dir string) (releasePackage, error) {
+	pkg := releasePackage{Path: filepath.ToSlash(filepath.Clean(dir))}
+	for _, manifest := range []string{"go.mod", "package.json"} {
+		content, err := os.ReadFile(filepath.Join(gitRoot, dir, manifest))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return pkg, err
+		}
+		name, dependencies, err := manifestDependencies(manifest, string(content))
+		if err != nil {
+			return pkg, fmt.Errorf("%s: %w", filepath.Join(dir, manifest), err)
+		}
+		if name != "" {
+			pkg.Names = append(pkg.Names, name)
+		}
+		pkg.Dependencies = append(pkg.Dependencies, dependencies...)
+	}
+	if len(pkg.Names) == 0 {
+		return pkg, fmt.Errorf("%s has neither a go.mod nor a package.json with a name", dir)
+	}
+	return pkg, nil
+}
+
+// manifestDependencies returns the module or package name a go.mod or
+// package.json declares and the names of its dependencies, required or
+// replaced
+func manifestDependencies(manifest, content string) (string, []string, error) {
+	var name string
+	var dependencies []string
+	if manifest == "package.json" {
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(content), &pkg); err != nil {
+			return "", nil, err
+		}
+		name = pkg.Name
+	} else if match := goModulePattern.FindStringSubmatch(content); match != nil {
+		name = match[1]
+	}
+	for _, line := range strings.Split(content, "\n") {
+		match := dependencyPatterns[manifest].FindStringSubmatch(line)
+		if match == nil && manifest == "go.mod" {
+			match = goReplacePattern.FindStringSubmatch(line)
+		}
+		if match != nil && match[1] != name && match[1] != "version" && !slices.Contains(dependencies, match[1]) {
+			dependencies = append(dependencies, match[1])
+		}
+	}
+	return name, dependencies, nil
+}
+
+// dependsOn reports whether p depends on the package o
+func (p releasePackage) dependsOn(o releasePackage) bool {
+	return slices.ContainsFunc(o.Names, func(name string) bool {
+		return slices.Contains(p.Dependencies, name)
+	})
+}
+
+// releaseOrder sorts packages so every package follows the packages it
+// depends on, keeping the given order otherwise
+func releaseOrder(packages []releasePackage) ([]releasePackage, error) {
+	var ordered []releasePackage
+	remaining := slices.Clone(packages)
+	for len(remaining) > 0 {
+		i := slices.IndexFunc(remaining, func(p releasePackage) bool {
+			return !slices.ContainsFunc(remaining, func(o releasePackage) bool {
+				return o.Path != p.Path && p.dependsOn(o)
+			})
+		})
+		if i < 0 {
+			paths := make([]string, len(remaining))
+			for j, p := range remaining {
+				paths[j] = p.Path
+			}
+			return nil, fmt.Errorf("the packages %s depend on each other", strings.Join(paths, ", "))
+		}
+		ordered = append(ordered, remaining[i])
+		remaining = slices.Delete(remaining, i, i+1)
+	}
+	return ordered, nil
+}
+
+// updateDependencyVersion returns a go.mod or package.json with the version
+// required of the dependency name set to version, keeping the range of
+// package.json, e.g. ^1.2.0 becomes ^1.3.0. Local references such as
+// workspace:* aren't versions and stay.
+func updateDependencyVersion(manifest, content, name, version string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		match := dependencyPatterns[manifest].FindStringSubmatchIndex(line)
+		if match == nil || line[match[2]:match[3]] != name {
+			continue
+		}
+		updated := version
+		if manifest == "go.mod" {
+			updated = "v" + version
+		} else {
+			old := line[match[4]:match[5]]
+			updated = old[:len(old)-len(strings.TrimLeft(old, "~^<>="))] + version
+		}
+		lines[i] = line[:match[4]] + updated + line[match[5]:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// updatePackageVersion returns a package.json with its own version set to
+// version
+func updatePackageVersion(content, version string) string {
+	match := packageVersionPattern.FindStringSubmatchIndex(content)
+	if match == nil {
+		return content
+	}
+	return content[:match[2]] + version + content[match[3]:]
+}
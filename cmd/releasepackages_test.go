@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestManifestDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		content  string
+		want     string
+		deps     []string
+	}{
+		{
+			name:     "go.mod",
+			manifest: "go.mod",
+			content:  "module example.com/api\n\ngo 1.22\n\nrequire (\n\texample.com/core v1.2.0\n\tgithub.com/spf13/cobra v1.8.0\n)\n\nreplace example.com/core => ../core\n",
+			want:     "example.com/api",
+			deps:     []string{"example.com/core", "github.com/spf13/cobra"},
+		},
+		{
+			name:     "go.mod replace only",
+			manifest: "go.mod",
+			content:  "module example.com/api\n\nreplace (\n\texample.com/core v1.2.0 => ../core\n)\n",
+			want:     "example.com/api",
+			deps:     []string{"example.com/core"},
+		},
+		{
+			name:     "package.json",
+			manifest: "package.json",
+			content:  "{\n  \"name\": \"@acme/web\",\n  \"version\": \"1.0.0\",\n  \"dependencies\": {\n    \"@acme/core\": \"^1.2.0\",\n    \"react\": \"~18.2.0\"\n  }\n}\n",
+			want:     "@acme/web",
+			deps:     []string{"@acme/core", "react"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, deps, err := manifestDependencies(tt.manifest, tt.content)
+			if err != nil || name != tt.want || !slices.Equal(deps, tt.deps) {
+				t.Errorf("manifestDependencies() = %q, %q, %v, want %q, %q", name, deps, err, tt.want, tt.deps)
+			}
+		})
+	}
+}
+
+func TestReleaseOrder(t *testing.T) {
+	core := releasePackage{Path: "core", Names: []string{"example.com/core"}}
+	api := releasePackage{Path: "api", Names: []string{"example.com/api"}, Dependencies: []string{"example.com/core"}}
+	web := releasePackage{Path: "web", Names: []string{"@acme/web"}, Dependencies: []string{"example.com/api", "react"}}
+	docs := releasePackage{Path: "docs", Names: []string{"@acme/docs"}}
+
+	tests := []struct {
+		name     string
+		packages []releasePackage
+		want     []string
+	}{
+		{name: "dependencies first", packages: []releasePackage{web, api, core}, want: []string{"core", "api", "web"}},
+		{name: "given order otherwise", packages: []releasePackage{docs, web, core, api}, want: []string{"docs", "core", "api", "web"}},
+		{name: "cycle", packages: []releasePackage{docs, api, {Path: "core", Names: []string{"example.com/core"}, Dependencies: []string{"example.com/api"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := releaseOrder(tt.packages)
+			var got []string
+			for _, p := range ordered {
+				got = append(got, p.Path)
+			}
+			if (err != nil) != (tt.want == nil) || !slices.Equal(got, tt.want) {
+				t.Errorf("releaseOrder() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateDependencyVersion(t *testing.T) {
+	tests := []struct {
+		manifest, content, want string
+	}{
+		{
+			manifest: "go.mod",
+			content:  "require (\n\texample.com/core v1.2.0\n\texample.com/core/v2 v2.0.0\n)\n",
+			want:     "require (\n\texample.com/core v1.3.0\n\texample.com/core/v2 v2.0.0\n)\n",
+		},
+		{
+			manifest: "go.mod",
+			content:  "require example.com/core v1.2.0 // indirect\n",
+			want:     "require example.com/core v1.3.0 // indirect\n",
+		},
+		{
+			manifest: "package.json",
+			content:  "{\n  \"dependencies\": {\n    \"@acme/core\": \"^1.2.0\",\n    \"@acme/core-ui\": \"1.2.0\"\n  }\n}\n",
+			want:     "{\n  \"dependencies\": {\n    \"@acme/core\": \"^1.3.0\",\n    \"@acme/core-ui\": \"1.2.0\"\n  }\n}\n",
+		},
+		{
+			manifest: "package.json",
+			content:  "{\n  \"dependencies\": {\n    \"@acme/core\": \"workspace:*\"\n  }\n}\n",
+			want:     "{\n  \"dependencies\": {\n    \"@acme/core\": \"workspace:*\"\n  }\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		name := "example.com/core"
+		if tt.manifest == "package.json" {
+			name = "@acme/core"
+		}
+		if got := updateDependencyVersion(tt.manifest, tt.content, name, "1.3.0"); got != tt.want {
+			t.Errorf("updateDependencyVersion(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestUpdatePackageVersion(t *testing.T) {
+	content := "{\n  \"name\": \"@acme/web\",\n  \"version\": \"1.0.0\",\n  \"dependencies\": {\n    \"version\": \"2.0.0\"\n  }\n}\n"
+	want := "{\n  \"name\": \"@acme/web\",\n  \"version\": \"1.1.0\",\n  \"dependencies\": {\n    \"version\": \"2.0.0\"\n  }\n}\n"
+	if got := updatePackageVersion(content, "1.1.0"); got != want {
+		t.Errorf("updatePackageVersion() = %q, want %q", got, want)
+	}
+}
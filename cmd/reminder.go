@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// remindUncommittedWork nudges towards a WIP commit when the working tree or
+// the stash holds changes older than uncommitted_reminder. It returns true if
+// the changes were committed as WIP.
+func remindUncommittedWork() bool {
+	threshold := viper.GetDuration("uncommitted_reminder")
+	if threshold <= 0 {
+		return false
+	}
+
+	oldest, stashed := oldestUncommittedChange()
+	if oldest.IsZero() || time.Since(oldest) < threshold {
+		return false
+	}
+
+	// e.g. 3h20m
+	age := strings.TrimSuffix(time.Since(oldest).Round(time.Minute).String(), "0s")
+	if stashed {
+		pterm.Warning.Printfln("Your stash holds changes from %s ago, uncommitted work is easily lost (git stash pop restores them)", age)
+		return false
+	}
+	pterm.Warning.Printfln("You have uncommitted changes from %s ago, uncommitted work is easily lost", age)
+
+	commitWip, _ := ui.Confirm("Commit all changes as WIP now (git cc unwip undoes it)", false)
+	if commitWip {
+		wip(nil, nil)
+		return true
+	}
+	return false
+}
+
+// oldestUncommittedChange returns the modification time of the oldest changed
+// file in the working tree which isn't about to be committed, or of the stash if it is older, and whether the
+// stash is the older one
+func oldestUncommittedChange() (time.Time, bool) {
+	var oldest time.Time
+
+	status, err := worktree.Status()
+	if err != nil {
+		pterm.Debug.Println("Failed to get status:", err)
+		return oldest, false
+	}
+	for path, entry := range status {
+		// staged changes are what is being committed right now, and so are
+		// the changes to tracked files with --all
+		if entry.Worktree == git.Unmodified || stageAll && entry.Worktree != git.Untracked {
+			continue
+		}
+		// deleted files leave no modification time behind
+		info, err := os.Stat(filepath.Join(gitRoot, path))
+		if err != nil {
+			continue
+		}
+		if oldest.IsZero() || info.ModTime().Before(oldest) {
+			oldest = info.ModTime()
+		}
+	}
+
+	stash, err := repo.Reference(plumbing.ReferenceName("refs/stash"), true)
+	if err != nil {
+		return oldest, false
+	}
+	if c, err := repo.CommitObject(stash.Hash()); err == nil && (oldest.IsZero() || c.Committer.When.Before(oldest)) {
+		return c.Committer.When, true
+	}
+	return oldest, false
+}
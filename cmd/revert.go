@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var revertCmd = &cobra.Command{
+	Use:   "revert <commit> | --continue",
+	Short: "Revert a commit with a conventional commit message",
+	Long: `Revert the changes of a commit and commit the result as
+
+  revert: <subject of the reverted commit>
+
+  <reason>
+
+  Refs: <hash of the reverted commit>
+
+prompting only for the optional reason. lint and serve accept the revert
+type without a scope whatever types and scopes are configured, as long as
+the message has the Refs footer.
+
+When the revert conflicts, resolve the conflicts, stage the files and run
+git cc revert --continue, or give up with git revert --abort.`,
+	Example: `  git cc revert 1a2b3c4
+  git cc revert HEAD~2 --reason "breaks the login on Safari"
+  git cc revert 1a2b3c4 --mainline 1`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if resume, _ := cmd.Flags().GetBool("continue"); resume {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: revert,
+}
+
+func init() {
+	revertCmd.Flags().String("reason", "", "Why the commit is reverted, skips the prompt")
+	revertCmd.Flags().Bool("no-edit", false, "Don't prompt for a reason")
+	revertCmd.Flags().Int("mainline", 0, "Parent number of the mainline when reverting a merge")
+	revertCmd.Flags().Bool("continue", false, "Commit a revert after its conflicts have been resolved")
+	rootCmd.AddCommand(revertCmd)
+}
+
+func revert(cmd *cobra.Command, args []string) {
+	var hash string
+	if resume, _ := cmd.Flags().GetBool("continue"); resume {
+		hash = resolvedRevert()
+	} else {
+		mainline, _ := cmd.Flags().GetInt("mainline")
+		hash = startRevert(args[0], mainline)
+	}
+
+	subject, err := gitOutput("log", "-1", "--format=%s", hash)
+	if err != nil {
+		pterm.Error.Println("Failed to read commit:", err)
+		exit(exitcode.Failure)
+	}
+	reason, _ := cmd.Flags().GetString("reason")
+	if noEdit, _ := cmd.Flags().GetBool("no-edit"); !noEdit && !cmd.Flags().Changed("reason") && !nonInteractive {
+		reason, _ = ui.MultilineInput("Reason for the revert (optional)", "")
+	}
+
+	// a message the commit-msg hook rejects would fail the commit
+	message := revertMessage(strings.TrimSpace(subject), hash, reason)
+	if problems := validateCommitMessage(message); len(problems) > 0 {
+		for _, problem := range problems {
+			pterm.Error.Println(strings.Join(append([]string{problemTitle(problem)}, problemHelp(problem)...), "\n"))
+		}
+		pterm.Error.Println("The revert is left staged, commit it with git cc revert --continue once the rules allow it, or give up with git revert --abort")
+		exit(exitcode.Invalid)
+	}
+	file, err := writeMessageFile(message)
+	if err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+	defer atExit(func() { removeMessageFile(file) })()
+
+	commit := exec.Command("git", append([]string{"commit", "-F", file}, signingArgs()...)...)
+	commit.Dir = gitRoot
+	commit.Stdout = os.Stdout
+	commit.Stderr = os.Stderr
+	passTerminal(commit)
+	if err := commit.Run(); err != nil {
+		commitFailed(err)
+	}
+}
+
+// revertMessage builds the message reverting the commit hash with subject.
+// A subject too long for the header is shortened, the body then starts with
+// the full subject.
+func revertMessage(subject, hash, reason string) string {
+	width := viper.GetInt("max_body_line_length")
+	data := CommitPromptData{
+		Type:             "revert",
+		ShortDescription: subject,
+		LongDescription:  wrapText(strings.TrimSpace(reason), width),
+		Footers:          []trailer{{Token: "Refs", Separator: ": ", Value: hash}},
+	}
+	message := buildCommitMessage(data)
+	header, _, _ := strings.Cut(message, "\n")
+	limit := viper.GetInt("max_subject_length")
+	if over := utf8.RuneCountInString(header) - limit; limit > 0 && over > 0 {
+		data.ShortDescription = shortenText(subject, utf8.RuneCountInString(subject)-over)
+		data.LongDescription = wrapText(strings.TrimSpace("Reverts \""+subject+"\".\n\n"+strings.TrimSpace(reason)), width)
+		message = buildCommitMessage(data)
+	}
+	return message
+}
+
+// shortenText cuts text to at most limit characters at a space, marking the
+// cut with an ellipsis
+func shortenText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	if limit < 1 {
+		return ""
+	}
+	cut := string(runes[:limit-1])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:.") + "…"
+}
+
+// startRevert reverts rev in the index and working tree and returns the
+// hash of the reverted commit
+func startRevert(rev string, mainline int) string {
+	hash, err := gitOutput("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		pterm.Error.Printfln("%s is not a commit", rev)
+		exit(exitcode.Failure)
+	}
+	hash = strings.TrimSpace(hash)
+
+	// the revert commit must not take anything else along
+	staged := exec.Command("git", "diff", "--cached", "--quiet")
+	staged.Dir = gitRoot
+	if err := staged.Run(); err != nil {
+		pterm.Error.Println("Changes are staged already, commit or stash them before reverting")
+		exit(exitcode.Failure)
+	}
+
+	args := []string{"revert", "--no-commit"}
+	if mainline > 0 {
+		args = append(args, "--mainline", strconv.Itoa(mainline))
+	}
+	git := exec.Command("git", append(args, hash)...)
+	git.Dir = gitRoot
+	git.Stdout = os.Stdout
+	git.Stderr = os.Stderr
+	if err := git.Run(); err != nil {
+		if _, statErr := os.Stat(revertHeadPath()); statErr == nil {
+			pterm.Error.Println("The revert conflicts, resolve the conflicts, stage the files and run git cc revert --continue")
+		} else {
+			pterm.Error.Println("git revert failed:", err)
+		}
+		exit(exitcode.Failure)
+	}
+	return hash
+}
+
+// resolvedRevert returns the hash of the commit whose conflicting revert
+// has been resolved
+func resolvedRevert() string {
+	hash, err := os.ReadFile(revertHeadPath())
+	if errors.Is(err, os.ErrNotExist) {
+		pterm.Error.Println("No revert in progress")
+		exit(exitcode.Failure)
+	} else if err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+
+	unmerged, err := gitOutput("diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		pterm.Error.Println("Failed to get status:", err)
+		exit(exitcode.Failure)
+	}
+	if files := strings.Fields(unmerged); len(files) > 0 {
+		pterm.Error.Println("Resolve and stage the conflicts first:", strings.Join(files, ", "))
+		exit(exitcode.Failure)
+	}
+	return strings.TrimSpace(string(hash))
+}
+
+// revertHeadPath is where git keeps the commit being reverted
+func revertHeadPath() string {
+	return filepath.Join(gitDir(), "REVERT_HEAD")
+}
@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword <range> | --file <answers.yaml>",
+	Short: "Reword the commits of a range which don't follow the conventions",
+	Long: `Walk the commits of a revision range whose messages lint would reject,
+show each message and prompt for what it lacks, such as the type or scope.
+What can be read from the old message is kept: a header which is almost
+conventional, the body and the trailers.
+
+The new messages are written by an interactive rebase which runs by itself,
+the changes of the commits stay as they are. Commits which were pushed to
+the upstream of the branch are not reworded unless --force is given.
+Histories with merge commits after the first commit to reword are
+refused.
+
+Large cleanups can be prepared offline instead: --file reads a YAML file
+mapping commits to the answers of their new messages, with the fields
+git cc --answers takes (see git cc schema). Every entry is checked against
+the rules before all of them are reworded in one rebase.`,
+	Example: `  git cc reword origin/main..HEAD
+  git cc reword HEAD~5.. --dry-run
+  git cc reword --file rewording.yaml`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  reword,
+}
+
+func init() {
+	rewordCmd.Flags().Bool("dry-run", false, "Print the new messages instead of rewording the commits")
+	rewordCmd.Flags().Bool("force", false, "Reword the commits even if they have already been pushed")
+	rewordCmd.Flags().String("file", "", "YAML `file` mapping commits to the answers of their new messages")
+	rootCmd.AddCommand(rewordCmd)
+}
+
+// rewording is a commit whose message is replaced
+type rewording struct {
+	Commit  *object.Commit
+	Message string
+}
+
+func reword(cmd *cobra.Command, args []string) {
+	file, _ := cmd.Flags().GetString("file")
+	if (file == "") == (len(args) == 0) {
+		pterm.Error.Println("Give either the range to reword or --file")
+		exit(exitcode.Failure)
+	}
+	if _, rebasing := currentRebaseStep(); rebasing {
+		pterm.Error.Println("A rebase is in progress, finish it with git rebase --continue or --abort first")
+		exit(exitcode.Failure)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		pterm.Error.Println("Nothing to reword:", err)
+		exit(exitcode.Failure)
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		pterm.Error.Println("Failed to read commit:", err)
+		exit(exitcode.Failure)
+	}
+
+	force, _ := cmd.Flags().GetBool("force")
+
+	if file != "" {
+		rewordings := loadRewordings(file, headCommit)
+		if len(rewordings) == 0 {
+			pterm.Info.Println("Nothing reworded")
+			return
+		}
+		if !force {
+			refusePushed(head, rewordings[0].Commit)
+		}
+		applyRewordings(cmd, rewordings)
+		return
+	}
+
+	invalid := invalidCommits(args[0], headCommit)
+	if len(invalid) == 0 {
+		pterm.Success.Printfln("All commits in %s follow the conventions", args[0])
+		return
+	}
+	if !force {
+		refusePushed(head, invalid[0])
+	}
+
+	var rewordings []rewording
+	for i, c := range invalid {
+		pterm.Println()
+		pterm.Info.Printfln("%s (%d/%d)", c.Hash.String()[:7], i+1, len(invalid))
+		pterm.Println(indentText(strings.TrimSpace(c.Message), "    "))
+		if ok, _ := ui.Confirm("Reword it", true); !ok {
+			continue
+		}
+		message, err := askRewording(c.Message)
+		if err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Failure)
+		}
+		rewordings = append(rewordings, rewording{Commit: c, Message: message})
+	}
+	if len(rewordings) == 0 {
+		pterm.Info.Println("Nothing reworded")
+		return
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); !dryRun {
+		if ok, _ := ui.Confirm(fmt.Sprintf("Rewrite the history of %s rewording %d commits", head.Name().Short(), len(rewordings)), true); !ok {
+			pterm.Info.Println("Nothing reworded")
+			return
+		}
+	}
+	applyRewordings(cmd, rewordings)
+}
+
+// refusePushed exits when c was pushed to the upstream of head
+func refusePushed(head *plumbing.Reference, c *object.Commit) {
+	if upstream, ok := pushedTo(head, c); ok {
+		pterm.Error.Printfln("%s has already been pushed to %s, rewording it would rewrite published history (use --force to do it anyway)", c.Hash.String()[:7], upstream)
+		exit(exitcode.Failure)
+	}
+}
+
+// applyRewordings rewords the commits, or prints the new messages with
+// --dry-run
+func applyRewordings(cmd *cobra.Command, rewordings []rewording) {
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		for _, r := range rewordings {
+			pterm.Println()
+			pterm.Println(pterm.Bold.Sprint(r.Commit.Hash.String()[:7]))
+			pterm.Println(indentText(r.Message, "    "))
+		}
+		return
+	}
+	if err := rewordCommits(rewordings); err != nil {
+		pterm.Error.Println("The rebase stopped, resolve the problem and run git rebase --continue, or git rebase --abort:", err)
+		exit(exitcode.CommitFailed)
+	}
+	pterm.Success.Printfln("Reworded %d commits", len(rewordings))
+}
+
+// invalidCommits returns the commits of revisions, oldest first, whose
+// messages lint rejects. They must be part of the history of head.
+func invalidCommits(revisions string, head *object.Commit) []*object.Commit {
+	out, err := gitOutput("rev-list", "--reverse", "--no-merges", revisions)
+	if err != nil {
+		pterm.Error.Printfln("Failed to list the commits of %s: %s", revisions, err)
+		exit(exitcode.Failure)
+	}
+	var invalid []*object.Commit
+	for _, hash := range strings.Fields(out) {
+		c, err := repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			pterm.Error.Printfln("Failed to read commit %s: %s", hash, err)
+			exit(exitcode.Failure)
+		}
+		message := strings.TrimSpace(c.Message)
+		if ignoredMessage(message) || dependencyBotOf(c) != nil || len(validateCommitMessage(message)) == 0 {
+			continue
+		}
+		if contained, err := c.IsAncestor(head); err != nil || !contained {
+			pterm.Error.Printfln("%s isn't part of the checked out history, check out the branch to reword", hash[:7])
+			exit(exitcode.Failure)
+		}
+		invalid = append(invalid, c)
+	}
+	if len(invalid) > 0 {
+		refuseMergesAfter(invalid[0])
+	}
+	return invalid
+}
+
+// refuseMergesAfter exits when merges follow oldest, which the rebase would
+// flatten
+func refuseMergesAfter(oldest *object.Commit) {
+	merges, err := gitOutput("rev-list", "--merges", oldest.Hash.String()+"..HEAD")
+	if err != nil {
+		pterm.Error.Println("Failed to list merges:", err)
+		exit(exitcode.Failure)
+	}
+	if merges = strings.TrimSpace(merges); merges != "" {
+		pterm.Error.Printfln("Merge %s follows %s, histories with merges can't be reworded", merges[:7], oldest.Hash.String()[:7])
+		exit(exitcode.Failure)
+	}
+}
+
+// loadRewordings reads a YAML file mapping commits to the answers of their
+// new messages and returns the rewordings, oldest first. All entries are
+// checked, and reported, before anything is reworded.
+func loadRewordings(file string, head *object.Commit) []rewording {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		pterm.Error.Println("Failed to read the rewordings:", err)
+		exit(exitcode.Failure)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		pterm.Error.Printfln("Invalid rewordings in %s: %s", file, err)
+		exit(exitcode.Invalid)
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		pterm.Error.Printfln("%s must map commits to the answers of their messages", file)
+		exit(exitcode.Invalid)
+	}
+
+	var rewordings []rewording
+	failed := false
+	entries := doc.Content[0].Content
+	for i := 0; i+1 < len(entries); i += 2 {
+		rev := entries[i].Value
+		r, err := rewordingOf(rev, entries[i+1], head)
+		if err == nil && slices.ContainsFunc(rewordings, func(o rewording) bool { return o.Commit.Hash == r.Commit.Hash }) {
+			err = fmt.Errorf("the commit is given more than once")
+		}
+		if err != nil {
+			pterm.Error.Printfln("%s (line %d): %s", rev, entries[i].Line, err)
+			failed = true
+			continue
+		}
+		rewordings = append(rewordings, r)
+	}
+	if failed {
+		exit(exitcode.Invalid)
+	}
+	if len(rewordings) == 0 {
+		return nil
+	}
+
+	// without merges the commits are in a line
+	slices.SortFunc(rewordings, func(a, b rewording) int {
+		if before, _ := a.Commit.IsAncestor(b.Commit); before {
+			return -1
+		}
+		return 1
+	})
+	refuseMergesAfter(rewordings[0].Commit)
+	return rewordings
+}
+
+// rewordingOf returns the rewording of the commit rev to the message of
+// the answers in node, which must pass the rules as --answers do
+func rewordingOf(rev string, node *yaml.Node, head *object.Commit) (rewording, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return rewording{}, fmt.Errorf("unknown commit: %w", err)
+	}
+	c, err := repo.CommitObject(*hash)
+	if err != nil {
+		return rewording{}, err
+	}
+	if contained, err := c.IsAncestor(head); err != nil || !contained {
+		return rewording{}, fmt.Errorf("the commit isn't part of the checked out history")
+	}
+	if c.NumParents() > 1 {
+		return rewording{}, fmt.Errorf("merges can't be reworded")
+	}
+
+	// Refs #123 is written as value: 123, which the answers take as text
+	stringScalars(node)
+	var answers any
+	if err := node.Decode(&answers); err != nil {
+		return rewording{}, err
+	}
+	content, err := json.Marshal(answers)
+	if err != nil {
+		return rewording{}, err
+	}
+	var data CommitPromptData
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&data); err != nil {
+		return rewording{}, fmt.Errorf("invalid answers: %w", err)
+	}
+	if err := validateCommitData(data); err != nil {
+		return rewording{}, fmt.Errorf("invalid answers: %w", err)
+	}
+	message, err := renderCommitMessage(data)
+	if err != nil {
+		return rewording{}, err
+	}
+	return rewording{Commit: c, Message: message}, nil
+}
+
+// stringScalars makes the numbers in node strings
+func stringScalars(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && (node.Tag == "!!int" || node.Tag == "!!float") {
+		node.Tag = "!!str"
+	}
+	for _, child := range node.Content {
+		stringScalars(child)
+	}
+}
+
+// askRewording prompts for what the old message lacks and returns the new
+// one. A header that almost follows the conventions is kept, otherwise it
+// becomes the short description.
+func askRewording(old string) (string, error) {
+	old = strings.TrimSpace(old)
+	data, err := parseCommitMessage(old)
+	fields := []promptField{typeField, scopeField}
+	if err == nil {
+		fields = nil
+	} else {
+		header, body, _ := strings.Cut(old, "\n")
+		body, trailers := splitTrailers(body)
+		data = CommitPromptData{ShortDescription: strings.TrimSpace(header), LongDescription: strings.TrimSpace(body), Footers: trailers}
+		// "Fix the login" is likely a fix
+		if word, _, _ := strings.Cut(header, " "); slices.Contains(commitTypes, strings.ToLower(word)) {
+			data.Type = strings.ToLower(word)
+		}
+	}
+	if i := slices.IndexFunc(commitTypes, func(t string) bool { return strings.EqualFold(t, data.Type) }); i >= 0 {
+		data.Type = commitTypes[i]
+	}
+
+	for {
+		promptDefaults = data
+		for _, field := range fields {
+			data = askField(field, data, commitTypes)
+		}
+		message := buildCommitMessage(data)
+		problems := validateCommitMessage(message)
+		if len(problems) == 0 {
+			return renderCommitMessage(data)
+		}
+		for _, problem := range problems {
+			pterm.Error.Println(strings.Join(append([]string{problemTitle(problem)}, problemHelp(problem)...), "\n"))
+		}
+		fields = problemFields(message, problems)
+	}
+}
+
+// rewordCommits replaces the messages of rewordings by an interactive
+// rebase, whose todo list amends each of them after picking it
+func rewordCommits(rewordings []rewording) error {
+	oldest := rewordings[0].Commit
+	args := []string{"rebase", "--interactive", "--autostash"}
+	revisions := "HEAD"
+	if oldest.NumParents() == 0 {
+		args = append(args, "--root")
+	} else {
+		base := oldest.ParentHashes[0].String()
+		args = append(args, base)
+		revisions = base + "..HEAD"
+	}
+	out, err := gitOutput("rev-list", "--reverse", "--topo-order", revisions)
+	if err != nil {
+		return err
+	}
+
+	amend := "exec git commit --amend --allow-empty --no-verify --quiet"
+	if viper.GetBool("sign") {
+		amend += " --gpg-sign"
+	}
+	var files []string
+	defer func() {
+		for _, file := range files {
+			removeMessageFile(file)
+		}
+	}()
+	var todo strings.Builder
+	for _, hash := range strings.Fields(out) {
+		fmt.Fprintf(&todo, "pick %s\n", hash)
+		i := slices.IndexFunc(rewordings, func(r rewording) bool { return r.Commit.Hash.String() == hash })
+		if i < 0 {
+			continue
+		}
+		file, err := writeMessageFile(rewordings[i].Message)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		fmt.Fprintf(&todo, "%s -F %s\n", amend, shellQuote(file))
+	}
+	todoFile, err := writeTempFile("git-rebase-todo", todo.String())
+	if err != nil {
+		return err
+	}
+	files = append(files, todoFile)
+
+	rebase := exec.Command("git", args...)
+	rebase.Dir = gitRoot
+	// the todo list is replaced by ours instead of opening it
+	rebase.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoFile))
+	rebase.Stdout = os.Stdout
+	rebase.Stderr = os.Stderr
+	if err := rebase.Run(); err != nil {
+		// the steps left need their message files
+		files = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright © 2024 Austin Sabel austin.sabel@gmail.com
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// version of the running binary, set by Execute
+var version = "dev"
+
+// Global Vars
+var (
+	commitTypes []string
+	scopes      []string
+	gitRoot     string
+	repo        *git.Repository
+	worktree    *git.Worktree
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "git-cc",
+	Short: "Craft commit messages that adhere to the Conventional Commits standard",
+	Long: `git-cc is interactive git sub-command that will help you craft beautify and
+informative commit message that adhere to the Conventional Commits standard.
+
+Run without a command to prompt for a commit message, same as git cc commit.
+Arguments after -- are passed on to git commit.`,
+	Args:              gitCommitArgsOnly,
+	PersistentPreRunE: startup,
+	PreRunE:           commitFlagsPreRun,
+	Run:               commit,
+	CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+	SilenceErrors:     true,
+	SilenceUsage:      true,
+}
+
+func init() {
+	rootCmd.PersistentFlags().String("ui", "", "Prompt frontend, pterm, plain or tui (default ui or pterm)")
+	rootCmd.PersistentFlags().Bool("plain", false, "Line based prompts without colors, same as --ui plain (default when not run in a terminal)")
+	rootCmd.PersistentFlags().Bool("no-color", false, "Print without colors, same as theme.preset no-color or setting NO_COLOR")
+	rootCmd.PersistentFlags().Bool("read-only", false, "Refuse commands which write to the repository or filesystem (default read_only or false)")
+	rootCmd.PersistentFlags().Bool("git-exit-codes", false, "Mirror the exit codes and error messages of git commit (default git_exit_codes or false)")
+	rootCmd.PersistentFlags().BoolVar(&quiet, "quiet", false, "Print a single line with the exit code, its name and the error instead of the messages when failing")
+	rootCmd.SetFlagErrorFunc(flagError)
+	// bound right away, errors parsing the other flags are reported as git would
+	viper.BindPFlag("git_exit_codes", rootCmd.PersistentFlags().Lookup("git-exit-codes"))
+	addCommitFlags(rootCmd)
+	addAmendFlag(rootCmd)
+}
+
+// Execute runs the command given on the command line
+func Execute(buildVersion, commit, date string) {
+	version = buildVersion
+	rootCmd.Version = version
+	rootCmd.SetVersionTemplate(fmt.Sprintf("version: %s, commit: %s, built at %s\n", version, commit, date))
+
+	// temporary files and the terminal are restored however the process ends
+	defer exitOnPanic()
+	exitOnSignal()
+
+	if err := rootCmd.Execute(); err != nil {
+		// flags failing to parse fail before startup
+		if quiet {
+			useQuietMode()
+		}
+		exitFatal(err)
+	}
+}
+
+// outsideRepositoryAnnotation marks commands which also work outside of a
+// git repository, e.g. on the global git config
+const outsideRepositoryAnnotation = "git-cc/outside-repository"
+
+var outsideRepositoryCommand = map[string]string{outsideRepositoryAnnotation: "true"}
+
+// startup runs before every command, it opens the git repository the
+// working directory is in and loads the config. Importing the package has
+// no side effects, everything happens here in order.
+func startup(cmd *cobra.Command, args []string) error {
+	if strings.ToLower(os.Getenv("DEBUG")) == "true" {
+		// Enable debug messages in PTerm.
+		pterm.EnableDebugMessages()
+	}
+	if quiet {
+		useQuietMode()
+	}
+
+	// help and completion scripts are available outside of a git
+	// repository too, completions load the config themselves
+	if cmd.Name() == "help" || cmd.Name() == "completion" || cmd.Name() == cobra.ShellCompRequestCmd {
+		return nil
+	}
+
+	// flags override the config
+	viper.BindPFlag("ui", cmd.Flags().Lookup("ui"))
+	viper.BindPFlag("read_only", cmd.Flags().Lookup("read-only"))
+
+	// Validate we are running in a git repo
+	if err := openRepository(); err != nil {
+		if cmd.Annotations[outsideRepositoryAnnotation] != "true" {
+			return err
+		}
+		// there is no repository config to load, nor are there prompts
+		pterm.Debug.Println(err)
+		return checkReadOnly(cmd)
+	}
+
+	// load optional config file
+	loadConfig()
+	if gitExitCodes() && !quiet {
+		useGitMessages()
+	}
+	if err := checkReadOnly(cmd); err != nil {
+		return err
+	}
+
+	if err := checkUIMode(); err != nil {
+		return exitcode.New(exitcode.Config, err)
+	}
+	noColor, _ := cmd.Flags().GetBool("no-color")
+	if err := applyTheme(noColor); err != nil {
+		return exitcode.New(exitcode.Config, err)
+	}
+	name := viper.GetString("ui")
+	if plain, _ := cmd.Flags().GetBool("plain"); plain {
+		name = "plain"
+	} else if name != "plain" && !cmd.Flags().Changed("ui") && !interactiveTerminal() {
+		// pterm's widgets and the tui need a terminal to draw in
+		pterm.Debug.Printfln("Not running in a terminal, using plain prompts instead of %s", name)
+		name = "plain"
+	}
+	if name == "plain" {
+		pterm.DisableStyling()
+	}
+	var err error
+	if ui, err = newPromptUI(name); err != nil {
+		return exitcode.New(exitcode.Config, err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// jsonSchema is the subset of JSON Schema needed to describe the prompts
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	Examples             []string               `json:"examples,omitempty"`
+	MinLength            int                    `json:"minLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+}
+
+// footerTokenPattern matches the tokens allowed for footers
+var footerTokenPattern = regexp.MustCompile(`^[\w-]+$`)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print a JSON Schema describing the commit prompts",
+	Long: `Print a JSON Schema describing the prompt fields, the allowed commit types and
+scopes, and their validation rules, so external frontends can render their
+own form and pass the answers back via --answers.`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         schema,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+// schema prints a JSON Schema of the prompt answers for the current repo so
+// external frontends can render their own form and pass the answers back
+// via --answers.
+func schema(cmd *cobra.Command, args []string) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(promptSchema()); err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+}
+
+func promptSchema() *jsonSchema {
+	additional := false
+	s := &jsonSchema{
+		Schema:      "https://json-schema.org/draft/2020-12/schema",
+		Title:       "git-cc commit answers",
+		Description: "Answers to the git-cc commit prompts, pass them back with git cc --answers <file>",
+		Type:        "object",
+		Properties: map[string]*jsonSchema{
+			"type": {
+				Title: "Commit Type",
+				Type:  "string",
+				Enum:  commitTypes,
+			},
+			"scope": {
+				Title:       "Scope",
+				Description: "Optional scope of the change",
+				Type:        "string",
+			},
+			"short_description": {
+				Title:     "Short Description",
+				Type:      "string",
+				MinLength: 1,
+				Pattern:   `^[^\r\n]*$`,
+			},
+			"long_description": {
+				Title: "Long Description",
+				Type:  "string",
+			},
+			"breaking_change": {
+				Title:   "Breaking Change",
+				Type:    "boolean",
+				Default: false,
+			},
+			"breaking_change_note": {
+				Title:       "Breaking Change Note",
+				Description: "Only used when breaking_change is true",
+				Type:        "string",
+			},
+			"breaking_change_notes": {
+				Title:       "Breaking Change Notes",
+				Description: "One note per thing the commit breaks, after breaking_change_note; only used when breaking_change is true",
+				Type:        "array",
+				Items:       &jsonSchema{Type: "string", MinLength: 1},
+			},
+			"footers": {
+				Title:       "Footers",
+				Description: "Git trailers such as Refs or Reviewed-by, breaking changes use breaking_change instead",
+				Type:        "array",
+				Items: &jsonSchema{
+					Type: "object",
+					Properties: map[string]*jsonSchema{
+						"token":     {Type: "string", Pattern: footerTokenPattern.String(), Examples: viper.GetStringSlice("footer_keys")},
+						"separator": {Type: "string", Enum: []string{": ", " #"}, Default: ": "},
+						"value":     {Type: "string", MinLength: 1},
+					},
+					Required:             []string{"token", "value"},
+					AdditionalProperties: &additional,
+				},
+			},
+		},
+		Required:             []string{"type", "short_description"},
+		AdditionalProperties: &additional,
+	}
+
+	if len(scopes) > 0 {
+		s.Properties["scope"].Enum = scopes
+		if !slices.Contains(scopes, "none") {
+			// without the "none" option the scope select can't be skipped
+			s.Properties["scope"].Description = "Scope of the change"
+			s.Required = append(s.Required, "scope")
+		}
+	}
+
+	return s
+}
+
+// loadAnswers reads prompt answers as JSON from file, or stdin when file is -
+func loadAnswers(file string) (CommitPromptData, error) {
+	var data CommitPromptData
+
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return data, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&data); err != nil {
+		return data, fmt.Errorf("invalid answers: %w", err)
+	}
+
+	if err := validateCommitData(data); err != nil {
+		return data, exitcode.New(exitcode.Invalid, fmt.Errorf("invalid answers: %w", err))
+	}
+	return data, nil
+}
+
+// validateCommitData applies the rules described by the prompt schema
+func validateCommitData(data CommitPromptData) error {
+	revert := isRevert(data.Type, data.Footers)
+	if !slices.Contains(commitTypes, data.Type) && !revert {
+		return fmt.Errorf("type %q is not one of %s", data.Type, strings.Join(commitTypes, ", "))
+	}
+
+	if len(scopes) > 0 {
+		if data.Scope == "" && !slices.Contains(scopes, "none") && !revert {
+			return fmt.Errorf("scope is required")
+		} else if data.Scope != "" && !slices.Contains(scopes, data.Scope) {
+			return fmt.Errorf("scope %q is not one of %s", data.Scope, strings.Join(scopes, ", "))
+		}
+	}
+
+	if strings.TrimSpace(data.ShortDescription) == "" {
+		return fmt.Errorf("short description is required")
+	} else if strings.ContainsAny(data.ShortDescription, "\r\n") {
+		return fmt.Errorf("short description must be a single line")
+	}
+
+	for _, t := range data.Footers {
+		if !footerTokenPattern.MatchString(t.Token) {
+			return fmt.Errorf("footer token %q must be a single word, e.g. Refs", t.Token)
+		} else if strings.TrimSpace(t.Value) == "" {
+			return fmt.Errorf("footer %s needs a value", t.Token)
+		} else if t.Separator != "" && t.Separator != ": " && t.Separator != " #" {
+			return fmt.Errorf("footer separator %q must be \": \" or \" #\"", t.Separator)
+		}
+	}
+
+	if err := checkCustomFooters(data.Footers); err != nil {
+		return err
+	}
+
+	// the message must pass lint as well
+	if problems := validateCommitMessage(buildCommitMessage(data)); len(problems) > 0 {
+		return fmt.Errorf("%s", problems[0].Message)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// option for entering a scope which isn't suggested
+const otherScope = "other..."
+
+// files marking a package of a monorepo, the scope is named after its directory
+var packageMarkers = []string{"go.mod", "package.json", "Cargo.toml", "pyproject.toml"}
+
+// suggestedScopes derives scopes from the paths of the staged files, the
+// most affected one first, leaving out generated files. A file belongs to the nearest package directory
+// below the repository root, or else to its top-level directory.
+func suggestedScopes() []string {
+	if !viper.GetBool("suggest_scope") {
+		return nil
+	}
+	out, err := gitOutput("diff", "--cached", "--name-only", "--no-renames")
+	if err != nil {
+		pterm.Debug.Println("Failed to list staged files:", err)
+		return nil
+	}
+
+	counts := map[string]int{}
+	var found []string
+	isPackage := map[string]bool{}
+	for _, file := range withoutGenerated(strings.Split(strings.TrimSpace(out), "\n")) {
+		scope := pathScope(file, isPackage)
+		if scope == "" {
+			continue
+		}
+		if counts[scope] == 0 {
+			found = append(found, scope)
+		}
+		counts[scope]++
+	}
+
+	// stable, so ties keep the order of the files
+	slices.SortStableFunc(found, func(a, b string) int { return counts[b] - counts[a] })
+	return found
+}
+
+// pathScope returns the scope of file, isPackage caches the directories
+// already checked for package markers
+func pathScope(file string, isPackage map[string]bool) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return ""
+	}
+	for d := dir; d != "."; d = path.Dir(d) {
+		marked, ok := isPackage[d]
+		if !ok {
+			marked = slices.ContainsFunc(packageMarkers, func(marker string) bool {
+				_, err := os.Stat(filepath.Join(gitRoot, filepath.FromSlash(d), marker))
+				return err == nil
+			})
+			isPackage[d] = marked
+		}
+		if marked {
+			return path.Base(d)
+		}
+	}
+	top, _, _ := strings.Cut(dir, "/")
+	return top
+}
+
+// orderScopes moves the suggested scopes found in options right behind
+// "none" and returns the one to preselect, options stays unchanged
+func orderScopes(options, suggested []string) ([]string, string) {
+	var first, rest []string
+	for _, s := range suggested {
+		if slices.Contains(options, s) && s != "none" {
+			first = append(first, s)
+		}
+	}
+	if len(first) == 0 {
+		return options, ""
+	}
+	for _, s := range options {
+		if !slices.Contains(first, s) {
+			rest = append(rest, s)
+		}
+	}
+	if len(rest) > 0 && rest[0] == "none" {
+		return slices.Concat(rest[:1], first, rest[1:]), first[0]
+	}
+	return slices.Concat(first, rest), first[0]
+}
+
+// historyScopes returns the scopes used by the last scope_history commits,
+// the most frequent first and ties by recency
+func historyScopes() []string {
+	limit := viper.GetInt("scope_history")
+	if limit <= 0 {
+		return nil
+	}
+	head, err := repo.Head()
+	if err != nil {
+		// no commits yet
+		return nil
+	}
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		pterm.Debug.Println("Failed to read history:", err)
+		return nil
+	}
+
+	counts := map[string]int{}
+	var found []string
+	scanned := 0
+	err = commits.ForEach(func(c *object.Commit) error {
+		if scanned++; scanned > limit {
+			return storer.ErrStop
+		}
+		header, _, _ := strings.Cut(c.Message, "\n")
+		match := headerPattern.FindStringSubmatch(strings.TrimSpace(header))
+		if match == nil || match[2] == "" {
+			return nil
+		}
+		if counts[match[2]] == 0 {
+			found = append(found, match[2])
+		}
+		counts[match[2]]++
+		return nil
+	})
+	if err != nil {
+		pterm.Debug.Println("Failed to read history:", err)
+	}
+
+	slices.SortStableFunc(found, func(a, b string) int { return counts[b] - counts[a] })
+	return found
+}
+
+// scopeGroup returns the group scope_groups puts scope in, if any
+func scopeGroup(scope string) string {
+	groups := viper.GetStringMapStringSlice("scope_groups")
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if slices.Contains(groups[name], scope) {
+			return name
+		}
+	}
+	return ""
+}
+
+// groupScopes orders scopes by their group, groups sorted by name, keeping
+// the order within a group, "none" first and the ungrouped scopes last
+func groupScopes(scopes []string) []string {
+	grouped := slices.Clone(scopes)
+	slices.SortStableFunc(grouped, func(a, b string) int {
+		ga, gb := scopeGroup(a), scopeGroup(b)
+		switch {
+		case a == "none":
+			return -1
+		case b == "none":
+			return 1
+		case ga == gb:
+			return 0
+		case ga == "":
+			return 1
+		case gb == "":
+			return -1
+		}
+		return strings.Compare(ga, gb)
+	})
+	return grouped
+}
+
+// scopeOptions returns the scopes as shown in the scope select, labelled
+// with their group and description when configured, and a lookup from
+// option back to scope
+func scopeOptions(scopes []string) ([]string, map[string]string) {
+	descriptions := viper.GetStringMapString("scope_descriptions")
+	options := make([]string, len(scopes))
+	lookup := map[string]string{}
+	for i, s := range scopes {
+		options[i] = s
+		if group := scopeGroup(s); group != "" {
+			options[i] = "[" + group + "] " + options[i]
+		}
+		// viper lowercases map keys
+		if description := descriptions[strings.ToLower(s)]; description != "" {
+			options[i] += " - " + description
+		}
+		lookup[options[i]] = s
+	}
+	return options, lookup
+}
+
+// selectScope shows options in the scope select, defaultScope preselected
+func selectScope(options []string, defaultScope string) string {
+	labels, lookup := scopeOptions(options)
+	defaultOption := ""
+	if i := slices.Index(options, defaultScope); i >= 0 {
+		defaultOption = labels[i]
+	}
+	selected, _ := ui.Select(promptLabel("Scope"), labels, defaultOption)
+	return lookup[selected]
+}
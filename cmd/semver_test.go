@@ -0,0 +1,146 @@
+package cmd
+
+import "testing"
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want semVersion
+		ok   bool
+	}{
+		{tag: "v1.2.3", want: semVersion{Prefix: "v", Major: 1, Minor: 2, Patch: 3}, ok: true},
+		{tag: "1.2.3", want: semVersion{Major: 1, Minor: 2, Patch: 3}, ok: true},
+		{tag: "v1.3.0-rc.1", want: semVersion{Prefix: "v", Major: 1, Minor: 3, Prerelease: "rc.1"}, ok: true},
+		{tag: "v1.3.0-rc.1+build.7", want: semVersion{Prefix: "v", Major: 1, Minor: 3, Prerelease: "rc.1", Build: "build.7"}, ok: true},
+		{tag: "v01.2.3"},
+		{tag: "v1.2"},
+		{tag: "release-1.2.3"},
+		{tag: "pkgA/v1.2.3"},
+	}
+	for _, tt := range tests {
+		got, ok := parseSemver(tt.tag)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseSemver(%q) = %+v, %v, want %+v, %v", tt.tag, got, ok, tt.want, tt.ok)
+		}
+		if ok && got.String() != tt.tag {
+			t.Errorf("parseSemver(%q).String() = %q", tt.tag, got.String())
+		}
+	}
+}
+
+func TestParseTagVersion(t *testing.T) {
+	tests := []struct {
+		tag, prefix string
+		want        string
+		ok          bool
+	}{
+		{tag: "v1.2.3", want: "v1.2.3", ok: true},
+		{tag: "pkgA/v1.2.3", prefix: "pkgA/", want: "pkgA/v1.2.3", ok: true},
+		{tag: "packages/ui-v2.0.0-rc.1", prefix: "packages/ui-", want: "packages/ui-v2.0.0-rc.1", ok: true},
+		// tags of other packages and the repository itself are left out
+		{tag: "pkgB/v1.2.3", prefix: "pkgA/"},
+		{tag: "v1.2.3", prefix: "pkgA/"},
+		{tag: "pkgA/v1.2.3"},
+		{tag: "pkgA/nightly", prefix: "pkgA/"},
+	}
+	for _, tt := range tests {
+		v, ok := parseTagVersion(tt.tag, tt.prefix)
+		if ok != tt.ok || ok && v.String() != tt.want {
+			t.Errorf("parseTagVersion(%q, %q) = %s, %v, want %s, %v", tt.tag, tt.prefix, v, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	// ascending precedence, as in the example of the semver spec
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "1.10.0", "2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := parseSemver(ordered[i])
+			b, _ := parseSemver(ordered[j])
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := a.Compare(b); got != want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+
+	a, _ := parseSemver("v1.0.0+build.1")
+	b, _ := parseSemver("1.0.0+build.2")
+	if a.Compare(b) != 0 {
+		t.Errorf("build metadata and prefixes must not count: %s.Compare(%s) = %d", a, b, a.Compare(b))
+	}
+}
+
+func TestBumpVersion(t *testing.T) {
+	commit := func(commitType string, breaking bool) conventionalCommit {
+		return conventionalCommit{Valid: true, Data: CommitPromptData{Type: commitType, BreakingChange: breaking}}
+	}
+	invalid := conventionalCommit{Data: CommitPromptData{Type: "feat", BreakingChange: true}}
+
+	tests := []struct {
+		name    string
+		version string
+		prefix  string
+		commits []conventionalCommit
+		want    string
+		bumped  bool
+	}{
+		{name: "fix", version: "v1.2.3", commits: []conventionalCommit{commit("fix", false)}, want: "v1.2.4", bumped: true},
+		{name: "feat", version: "v1.2.3", commits: []conventionalCommit{commit("fix", false), commit("feat", false)}, want: "v1.3.0", bumped: true},
+		{name: "breaking", version: "v1.2.3", commits: []conventionalCommit{commit("feat", false), commit("refactor", true)}, want: "v2.0.0", bumped: true},
+		{name: "breaking fix", version: "v1.2.3", commits: []conventionalCommit{commit("fix", true)}, want: "v2.0.0", bumped: true},
+		{name: "nothing releasable", version: "v1.2.3", commits: []conventionalCommit{commit("docs", false), commit("chore", false)}, want: "v1.2.3"},
+		{name: "invalid messages don't count", version: "v1.2.3", commits: []conventionalCommit{invalid}, want: "v1.2.3"},
+		{name: "no commits", version: "v1.2.3", want: "v1.2.3"},
+		{name: "0.x feat", version: "v0.3.1", commits: []conventionalCommit{commit("feat", false)}, want: "v0.4.0", bumped: true},
+		{name: "0.x fix", version: "v0.3.1", commits: []conventionalCommit{commit("fix", false)}, want: "v0.3.2", bumped: true},
+		{name: "0.x breaking", version: "v0.3.1", commits: []conventionalCommit{commit("feat", true)}, want: "v1.0.0", bumped: true},
+		{name: "first release", version: "v0.0.0", commits: []conventionalCommit{commit("feat", false)}, want: "v0.1.0", bumped: true},
+		{name: "prerelease and build are dropped", version: "v1.2.3-rc.1+7", commits: []conventionalCommit{commit("fix", false)}, want: "v1.2.4", bumped: true},
+		{name: "tag prefix is kept", version: "pkgA/v1.2.3", prefix: "pkgA/", commits: []conventionalCommit{commit("feat", false)}, want: "pkgA/v1.3.0", bumped: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ok := parseTagVersion(tt.version, tt.prefix)
+			if !ok {
+				t.Fatalf("invalid version %q", tt.version)
+			}
+			got, bumped := bumpVersion(v, tt.commits)
+			if got.String() != tt.want || bumped != tt.bumped {
+				t.Errorf("bumpVersion(%s) = %s, %v, want %s, %v", tt.version, got, bumped, tt.want, tt.bumped)
+			}
+		})
+	}
+}
+
+func TestNextPrerelease(t *testing.T) {
+	tags := map[string]semVersion{}
+	for _, name := range []string{"v1.2.0", "v1.3.0-rc.1", "v1.3.0-rc.2", "v1.3.0-beta.5", "v1.4.0-rc.7", "v1.3.0-rc.x"} {
+		v, _ := parseSemver(name)
+		tags[name] = v
+	}
+	tests := []struct {
+		next, id string
+		want     string
+	}{
+		{next: "v1.3.0", id: "rc", want: "v1.3.0-rc.3"},
+		{next: "v1.3.0", id: "beta", want: "v1.3.0-beta.6"},
+		{next: "v1.3.0", id: "alpha", want: "v1.3.0-alpha.1"},
+		{next: "v2.0.0", id: "rc", want: "v2.0.0-rc.1"},
+	}
+	for _, tt := range tests {
+		next, _ := parseSemver(tt.next)
+		if got := nextPrerelease(next, tt.id, tags); got.String() != tt.want {
+			t.Errorf("nextPrerelease(%s, %s) = %s, want %s", tt.next, tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+// JSON-RPC error codes
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// LSP completion item kinds
+const (
+	completionKindValue   = 12
+	completionKindKeyword = 14
+)
+
+var (
+	typePrefixPattern  = regexp.MustCompile(`^[\w-]*$`)
+	scopePrefixPattern = regexp.MustCompile(`^[\w-]+\([^()]*$`)
+)
+
+type rpcMessage struct {
+	ID     *json.RawMessage `json:"id,omitempty"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Code     string   `json:"code,omitempty"`
+	// CodeDescription links the rule in the team's conventions
+	CodeDescription *lspCodeDescription `json:"codeDescription,omitempty"`
+	Message         string              `json:"message"`
+}
+
+type lspCodeDescription struct {
+	Href string `json:"href"`
+}
+
+type lspCompletionItem struct {
+	Label string `json:"label"`
+	Kind  int    `json:"kind"`
+}
+
+type lspDocumentParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+	Position lspPosition `json:"position"`
+}
+
+// rpcServer speaks JSON-RPC 2.0 with LSP framing, so editors can use it as a
+// language server for COMMIT_EDITMSG while other tools call the git-cc
+// specific parse, validate and build methods directly.
+type rpcServer struct {
+	out       io.Writer
+	mu        sync.Mutex
+	documents map[string]string
+	shutdown  bool
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a JSON-RPC language server on stdin/stdout",
+	Long: `Run a JSON-RPC 2.0 server on stdin/stdout using Language Server Protocol
+framing. It publishes diagnostics and completes types and scopes for commit
+message documents, and offers the gitcc/parse, gitcc/validate, gitcc/build
+and gitcc/schema methods.
+
+With --lint it instead reads one JSON request per line, {"message": "..."} or
+{"commit": "<rev>"} with an optional "id", and answers each with one line
+{"id", "valid", "problems"}, for merge queues and bots validating many
+messages without starting a process for each.
+
+With --http it serves the same rules to bots and dashboards over HTTP
+without ever changing the repository:
+
+  POST /parse      {"message"} to prompt answers
+  POST /validate   {"message"} or {"commit"} to {"valid", "problems"}
+  POST /format     prompt answers to {"message"}
+  GET  /changelog  ?from=&to=&release= to a markdown release section
+  GET  /schema     JSON Schema of the prompt answers`,
+	Example: `  git cc serve --lint < requests.ndjson
+  git cc serve --http :8080`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         serve,
+}
+
+func init() {
+	serveCmd.Flags().Bool("lint", false, "Serve newline delimited JSON lint requests instead of JSON-RPC")
+	serveCmd.Flags().String("http", "", "Serve the HTTP API on this address, e.g. :8080, instead of JSON-RPC")
+	serveCmd.MarkFlagsMutuallyExclusive("lint", "http")
+	rootCmd.AddCommand(serveCmd)
+}
+
+// serve runs the JSON-RPC server on stdin and stdout until the client exits
+func serve(cmd *cobra.Command, args []string) {
+	if lint, _ := cmd.Flags().GetBool("lint"); lint {
+		serveLintStdio()
+		return
+	}
+	if addr, _ := cmd.Flags().GetString("http"); addr != "" {
+		serveHTTP(addr)
+		return
+	}
+
+	server := &rpcServer{out: os.Stdout, documents: map[string]string{}}
+	if err := server.run(os.Stdin); err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+}
+
+func (s *rpcServer) run(in io.Reader) error {
+	reader := textproto.NewReader(bufio.NewReader(in))
+	for {
+		header, err := reader.ReadMIMEHeader()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			return fmt.Errorf("invalid Content-Length header: %w", err)
+		}
+
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader.R, body); err != nil {
+			return err
+		}
+
+		var msg rpcMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			s.reply(nil, nil, &rpcError{Code: rpcParseError, Message: err.Error()})
+			continue
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				exit(exitcode.Failure)
+			}
+			return nil
+		}
+
+		result, rpcErr := s.handle(msg)
+		if msg.ID != nil {
+			s.reply(msg.ID, result, rpcErr)
+		}
+	}
+}
+
+func (s *rpcServer) handle(msg rpcMessage) (any, *rpcError) {
+	var params lspDocumentParams
+
+	switch msg.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync": 1, // full document sync
+				"completionProvider": map[string]any{
+					"triggerCharacters": []string{"("},
+				},
+			},
+			"serverInfo": map[string]string{"name": "git-cc", "version": version},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen", "textDocument/didChange", "textDocument/didClose":
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		uri := params.TextDocument.URI
+		switch {
+		case msg.Method == "textDocument/didClose":
+			delete(s.documents, uri)
+		case len(params.ContentChanges) > 0:
+			s.documents[uri] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		default:
+			s.documents[uri] = params.TextDocument.Text
+		}
+		s.publishDiagnostics(uri)
+		return nil, nil
+	case "textDocument/completion":
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		return s.complete(params.TextDocument.URI, params.Position), nil
+	case "gitcc/parse", "gitcc/validate":
+		var args struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(msg.Params, &args); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		message := stripComments(args.Message)
+		if msg.Method == "gitcc/validate" {
+			problems := validateCommitMessage(message)
+			return map[string]any{"valid": len(problems) == 0, "problems": problems}, nil
+		}
+		data, err := parseCommitMessage(message)
+		if err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		return data, nil
+	case "gitcc/build":
+		var data CommitPromptData
+		if err := json.Unmarshal(msg.Params, &data); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		if err := validateCommitData(data); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		message, err := renderCommitMessage(data)
+		if err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		return map[string]string{"message": message}, nil
+	case "gitcc/schema":
+		return promptSchema(), nil
+	}
+
+	// unknown notifications are ignored as the spec requires
+	if msg.ID == nil {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + msg.Method}
+}
+
+// publishDiagnostics validates the document and reports the problems found
+func (s *rpcServer) publishDiagnostics(uri string) {
+	diagnostics := []lspDiagnostic{}
+
+	if text, ok := s.documents[uri]; ok {
+		// comment lines are blanked so positions still match the document
+		lines := commentLines(text)
+		for _, problem := range validateCommitMessage(strings.Join(lines, "\n")) {
+			message := problem.Message
+			if problem.Hint != "" {
+				message += "\n" + problem.Hint
+			}
+			var description *lspCodeDescription
+			if url := ruleURL(problem.Rule); url != "" {
+				description = &lspCodeDescription{Href: url}
+			}
+			diagnostics = append(diagnostics, lspDiagnostic{
+				Range: lspRange{
+					Start: lspPosition{Line: problem.Line, Character: utf16Offset(lines, problem.Line, problem.Column)},
+					End:   lspPosition{Line: problem.Line, Character: utf16Offset(lines, problem.Line, problem.EndColumn)},
+				},
+				Severity:        1,
+				Source:          "git-cc",
+				Code:            problem.Rule,
+				CodeDescription: description,
+				Message:         message,
+			})
+		}
+	}
+
+	s.notify("textDocument/publishDiagnostics", map[string]any{"uri": uri, "diagnostics": diagnostics})
+}
+
+// complete offers the configured types at the start of the header and the
+// configured scopes inside its parentheses.
+func (s *rpcServer) complete(uri string, position lspPosition) []lspCompletionItem {
+	items := []lspCompletionItem{}
+	if position.Line != 0 {
+		return items
+	}
+
+	header, _, _ := strings.Cut(s.documents[uri], "\n")
+	prefix := header[:byteOffset(header, position.Character)]
+
+	switch {
+	case typePrefixPattern.MatchString(prefix):
+		for _, commitType := range commitTypes {
+			items = append(items, lspCompletionItem{Label: commitType, Kind: completionKindKeyword})
+		}
+	case scopePrefixPattern.MatchString(prefix):
+		for _, scope := range scopes {
+			if scope != "none" {
+				items = append(items, lspCompletionItem{Label: scope, Kind: completionKindValue})
+			}
+		}
+	}
+
+	return items
+}
+
+// utf16Offset converts the byte offset of problems in line of lines to the
+// UTF-16 code units LSP counts characters in
+func utf16Offset(lines []string, line, offset int) int {
+	if line >= len(lines) {
+		return offset
+	}
+	text := lines[line][:min(offset, len(lines[line]))]
+	return len(utf16.Encode([]rune(text)))
+}
+
+// byteOffset converts a position in UTF-16 code units to the byte offset in
+// line, the end of the line when it is beyond it
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		// runes outside the basic plane take a surrogate pair
+		units++
+		if r > 0xFFFF {
+			units++
+		}
+	}
+	return len(line)
+}
+
+func (s *rpcServer) reply(id *json.RawMessage, result any, rpcErr *rpcError) {
+	msg := map[string]any{"jsonrpc": "2.0", "id": id}
+	if rpcErr != nil {
+		msg["error"] = rpcErr
+	} else {
+		msg["result"] = result
+	}
+	s.write(msg)
+}
+
+func (s *rpcServer) notify(method string, params any) {
+	s.write(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *rpcServer) write(msg map[string]any) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		pterm.Debug.Println("Failed to encode JSON-RPC message:", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// withRules sets the configured types and scopes for a test, without
+// conditional rules, which need the branch of a repository
+func withRules(t *testing.T, types, configured []string) {
+	conditionsOnce.Do(func() {})
+	oldTypes, oldScopes := commitTypes, scopes
+	commitTypes, scopes = types, configured
+	t.Cleanup(func() { commitTypes, scopes = oldTypes, oldScopes })
+}
+
+func TestPublishDiagnosticsCountsUTF16(t *testing.T) {
+	withRules(t, []string{"feat", "fix"}, []string{"api", "ui"})
+
+	var out bytes.Buffer
+	s := &rpcServer{out: &out, documents: map[string]string{"file:///msg": "🐛 feet(äpi): add x"}}
+	s.publishDiagnostics("file:///msg")
+
+	_, body, _ := strings.Cut(out.String(), "\r\n\r\n")
+	var notification struct {
+		Params struct {
+			Diagnostics []lspDiagnostic `json:"diagnostics"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(body), &notification); err != nil {
+		t.Fatal(err)
+	}
+	// the bug is two UTF-16 code units, ä one
+	want := map[string]lspRange{
+		"type-enum":  {Start: lspPosition{Character: 3}, End: lspPosition{Character: 7}},
+		"scope-enum": {Start: lspPosition{Character: 8}, End: lspPosition{Character: 11}},
+	}
+	got := map[string]lspRange{}
+	for _, d := range notification.Params.Diagnostics {
+		got[d.Code] = d.Range
+	}
+	for rule, r := range want {
+		if got[rule] != r {
+			t.Errorf("%s range = %+v, want %+v", rule, got[rule], r)
+		}
+	}
+}
+
+func TestCompleteCountsUTF16(t *testing.T) {
+	withRules(t, []string{"feat", "fix"}, []string{"api", "ui"})
+
+	s := &rpcServer{documents: map[string]string{"file:///msg": "feat(🐛): add x"}}
+	tests := []struct {
+		character int
+		want      []string
+	}{
+		{0, []string{"feat", "fix"}},
+		{2, []string{"feat", "fix"}},
+		{5, []string{"api", "ui"}},
+		// after the bug, which counts twice
+		{7, []string{"api", "ui"}},
+		{8, nil},
+	}
+	for _, tt := range tests {
+		var labels []string
+		for _, item := range s.complete("file:///msg", lspPosition{Character: tt.character}) {
+			labels = append(labels, item.Label)
+		}
+		if !slices.Equal(labels, tt.want) {
+			t.Errorf("complete at %d = %v, want %v", tt.character, labels, tt.want)
+		}
+	}
+}
+
+func TestByteOffset(t *testing.T) {
+	line := "a🐛ä b"
+	for character, want := range map[int]int{0: 0, 1: 1, 3: 5, 4: 7, 6: 9, 10: 9} {
+		if got := byteOffset(line, character); got != want {
+			t.Errorf("byteOffset(%q, %d) = %d, want %d", line, character, got, want)
+		}
+	}
+}
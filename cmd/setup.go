@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+const (
+	setupNow   = "Set it up now"
+	setupLater = "Not now"
+	setupNever = "Never ask in this repository"
+)
+
+// setupDeclinedPath marks a repository whose setup was declined for good
+func setupDeclinedPath() string {
+	return filepath.Join(gitDir(), "git-cc", "setup-declined")
+}
+
+// offerSetup offers the setup wizard the first time the prompts run in a
+// repository without any config, instead of silently using the defaults
+func offerSetup() {
+	if !viper.GetBool("setup_wizard") || findConfig(gitRoot, ".git-cc") != "" || hasCommitlintConfig() || hasCommitizenConfig() {
+		return
+	}
+	if _, err := os.Stat(setupDeclinedPath()); err == nil {
+		return
+	}
+
+	pterm.Info.Println("This repository has no git-cc config yet, the defaults are used")
+	choice, _ := ui.Select("Set up git-cc for this repository (takes about 30 seconds)", []string{setupNow, setupLater, setupNever}, setupNow)
+	switch choice {
+	case setupNow:
+		setupWizard()
+	case setupNever:
+		if err := writeFileAtomic(setupDeclinedPath(), nil, 0o644); err != nil {
+			pterm.Warning.Println("Failed to remember the answer:", err)
+		}
+		pterm.Info.Println("Run git cc init to set it up later")
+	}
+}
+
+// setupWizard writes a .git-cc.yaml from a few questions, optionally
+// installs the commit-msg hook and applies the config to the running commit
+func setupWizard() {
+	config := currentRepoConfig()
+	if useDefaults, _ := ui.Confirm(fmt.Sprintf("Use the default commit types (%s)", strings.Join(defaultCommitTypes, ", ")), true); useDefaults {
+		config.UseDefaults = true
+	} else {
+		config.UseDefaults = false
+		config.askTypes()
+	}
+
+	if dirs := topLevelDirs(); len(dirs) > 0 {
+		label := fmt.Sprintf("Use the top-level directories as scopes (%s)", strings.Join(dirs, ", "))
+		if useDirs, _ := ui.Confirm(label, true); useDirs {
+			config.Scopes = dirs
+		}
+	}
+
+	path := filepath.Join(gitRoot, ".git-cc.yaml")
+	if err := os.WriteFile(path, []byte(config.yaml()), 0o644); err != nil {
+		pterm.Error.Println("Failed to write config:", err)
+		return
+	}
+	pterm.Success.Printfln("Wrote %s, commit it to share the settings or refine it with git cc config edit", path)
+
+	if install, _ := ui.Confirm("Install the commit-msg hook validating messages written without git cc", false); install {
+		if dir, err := hooksDir(); err != nil {
+			pterm.Error.Println("Failed to locate hooks directory:", err)
+		} else if manager := hookManager(dir); manager != "" {
+			pterm.Warning.Printfln("hooks are managed by %s, add git cc lint \"$1\" as its commit-msg hook", manager)
+		} else if err := installHook(dir, "commit-msg", false); err != nil {
+			pterm.Warning.Println(err)
+		}
+	}
+
+	// the commit being prompted for uses the new config already
+	for key, value := range config.settings() {
+		viper.Set(key, value)
+	}
+	resolveTypesAndScopes()
+}
+
+// topLevelDirs returns the directories tracked in the repository root,
+// leaving out hidden and vendored ones
+func topLevelDirs() []string {
+	// nothing is tracked on an unborn branch
+	if _, err := repo.Head(); err != nil {
+		return nil
+	}
+	out, err := gitOutput("ls-tree", "-d", "--name-only", "HEAD")
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, dir := range strings.Split(strings.TrimSpace(out), "\n") {
+		if dir == "" || strings.HasPrefix(dir, ".") || slices.Contains([]string{"vendor", "node_modules", "third_party"}, dir) {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// hasCommitlintConfig reports whether the repository shares commitlint
+// rules, which configure git-cc as well
+func hasCommitlintConfig() bool {
+	if viper.GetString("commitlint") == "off" {
+		return false
+	}
+	for _, name := range commitlintFiles {
+		if _, err := os.Stat(filepath.Join(gitRoot, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCommitizenConfig reports whether the repository has a commitizen config
+// git-cc takes its rules from
+func hasCommitizenConfig() bool {
+	return viper.GetString("commitizen") != "off" && loadCommitizen() != nil
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var snippetsCmd = &cobra.Command{
+	Use:   "snippets",
+	Short: "Generate editor snippets from the configured types and scopes",
+	Long: `Generate snippets for editing commit messages outside of git-cc, e.g. in
+COMMIT_EDITMSG opened by git commit: one snippet per commit type, completing
+the header with a choice of the configured scopes, and one per footer key and
+custom prompt. The scope can be deleted as a whole unless the repository
+requires one.
+
+--format vscode prints a snippets file for the git-commit language of Visual
+Studio Code, ultisnips one for the gitcommit filetype of UltiSnips.`,
+	Example: `  git cc snippets > .vscode/git-commit.code-snippets
+  git cc snippets --format ultisnips > ~/.vim/UltiSnips/gitcommit.snippets`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         snippets,
+}
+
+func init() {
+	snippetsCmd.Flags().String("format", "vscode", "Snippet format, vscode or ultisnips")
+	rootCmd.AddCommand(snippetsCmd)
+}
+
+// snippet is an editor independent snippet, its body uses the placeholder
+// syntax VS Code and UltiSnips share
+type snippet struct {
+	Name        string
+	Prefix      string
+	Description string
+	Body        []string
+}
+
+// vscodeSnippet is an entry of a VS Code snippets file
+type vscodeSnippet struct {
+	Scope       string   `json:"scope"`
+	Prefix      string   `json:"prefix"`
+	Body        []string `json:"body"`
+	Description string   `json:"description"`
+}
+
+func snippets(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	if format != "vscode" && format != "ultisnips" {
+		pterm.Error.Printfln("unknown format %q, expected vscode or ultisnips", format)
+		exit(exitcode.Failure)
+	}
+
+	list := commitSnippets(format)
+	if format == "ultisnips" {
+		fmt.Println("# generated by git cc snippets")
+		for _, s := range list {
+			fmt.Printf("\nsnippet %s %q\n%s\nendsnippet\n", s.Prefix, s.Description, strings.Join(s.Body, "\n"))
+		}
+		return
+	}
+
+	file := map[string]vscodeSnippet{}
+	for _, s := range list {
+		file[s.Name] = vscodeSnippet{Scope: "git-commit", Prefix: s.Prefix, Body: s.Body, Description: s.Description}
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	// keep < and > of the bodies readable
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(file); err != nil {
+		pterm.Error.Println(err)
+		exit(exitcode.Failure)
+	}
+}
+
+// commitSnippets returns the snippets of the commit types and footer keys,
+// UltiSnips offers no choices so the scopes are listed in the placeholder
+func commitSnippets(format string) []snippet {
+	choices := slices.DeleteFunc(slices.Clone(scopes), func(s string) bool { return s == "none" })
+	scope := "${2:scope}"
+	if len(choices) > 0 {
+		if format == "vscode" {
+			scope = "${2|" + strings.Join(choices, ",") + "|}"
+		} else {
+			scope = "${2:" + strings.Join(choices, "|") + "}"
+		}
+	}
+	// an optional scope is deleted together with its parentheses
+	header := "(" + scope + ")"
+	if len(choices) == 0 || slices.Contains(scopes, "none") {
+		header = "${1:" + header + "}"
+	}
+
+	var list []snippet
+	for _, t := range commitTypes {
+		description := t + " commit"
+		if explanation, ok := typeExplanations[t]; ok {
+			description = t + ": " + explanation
+		}
+		list = append(list, snippet{
+			Name:        "Commit type " + t,
+			Prefix:      t,
+			Description: description,
+			Body:        []string{t + header + ": ${3:description}", "", "$0"},
+		})
+	}
+	list = append(list, snippet{
+		Name:        "Breaking change",
+		Prefix:      "breaking",
+		Description: "BREAKING CHANGE footer",
+		Body:        []string{"BREAKING CHANGE: ${1:what users need to change}"},
+	})
+	for _, key := range viper.GetStringSlice("footer_keys") {
+		list = append(list, snippet{
+			Name:        "Footer " + key,
+			Prefix:      strings.ToLower(key),
+			Description: key + " footer",
+			Body:        []string{key + ": ${1}"},
+		})
+	}
+	// the answers of custom_prompts are footers too
+	for _, p := range customPrompts() {
+		value := "${1}"
+		if p.Kind == selectPrompt && format == "vscode" {
+			value = "${1|" + strings.Join(p.Options, ",") + "|}"
+		} else if p.Kind == selectPrompt {
+			value = "${1:" + strings.Join(p.Options, "|") + "}"
+		} else if p.Kind == confirmPrompt {
+			value = "${1:yes}"
+		}
+		list = append(list, snippet{
+			Name:        "Footer " + p.Footer,
+			Prefix:      strings.ToLower(p.Footer),
+			Description: p.Label,
+			Body:        []string{p.Footer + ": " + value},
+		})
+	}
+	return list
+}
@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// commonMisspellings are frequent typos of English words with their fix,
+// found without a word list
+var commonMisspellings = map[string]string{
+	"accomodate":       "accommodate",
+	"accross":          "across",
+	"acess":            "access",
+	"acheive":          "achieve",
+	"adress":           "address",
+	"agressive":        "aggressive",
+	"allready":         "already",
+	"alot":             "a lot",
+	"alwasy":           "always",
+	"ammount":          "amount",
+	"anual":            "annual",
+	"apparantly":       "apparently",
+	"appearence":       "appearance",
+	"arent":            "aren't",
+	"arguement":        "argument",
+	"asynchonous":      "asynchronous",
+	"asyncronous":      "asynchronous",
+	"atleast":          "at least",
+	"attribtue":        "attribute",
+	"authenication":    "authentication",
+	"authentification": "authentication",
+	"availabe":         "available",
+	"availible":        "available",
+	"avaliable":        "available",
+	"backwords":        "backwards",
+	"becasue":          "because",
+	"becuase":          "because",
+	"beggining":        "beginning",
+	"begining":         "beginning",
+	"beleive":          "believe",
+	"buisness":         "business",
+	"cancelation":      "cancellation",
+	"catagory":         "category",
+	"charachter":       "character",
+	"charater":         "character",
+	"choosen":          "chosen",
+	"comand":           "command",
+	"commited":         "committed",
+	"commiting":        "committing",
+	"comparision":      "comparison",
+	"compatability":    "compatibility",
+	"compatable":       "compatible",
+	"completly":        "completely",
+	"concurent":        "concurrent",
+	"configuraiton":    "configuration",
+	"connnection":      "connection",
+	"consistant":       "consistent",
+	"continous":        "continuous",
+	"convertion":       "conversion",
+	"corect":           "correct",
+	"correclty":        "correctly",
+	"couldnt":          "couldn't",
+	"curent":           "current",
+	"defailt":          "default",
+	"definately":       "definitely",
+	"definitly":        "definitely",
+	"defualt":          "default",
+	"dependancies":     "dependencies",
+	"dependancy":       "dependency",
+	"dependecy":        "dependency",
+	"deprected":        "deprecated",
+	"desciption":       "description",
+	"descripton":       "description",
+	"didnt":            "didn't",
+	"diffrent":         "different",
+	"directroy":        "directory",
+	"documenation":     "documentation",
+	"documentaion":     "documentation",
+	"doesnt":           "doesn't",
+	"dont":             "don't",
+	"duplciate":        "duplicate",
+	"embeded":          "embedded",
+	"enviornment":      "environment",
+	"enviroment":       "environment",
+	"environement":     "environment",
+	"equivalant":       "equivalent",
+	"exeption":         "exception",
+	"existance":        "existence",
+	"existant":         "existent",
+	"expecially":       "especially",
+	"explicitely":      "explicitly",
+	"exsisting":        "existing",
+	"feild":            "field",
+	"finaly":           "finally",
+	"folowing":         "following",
+	"fucntion":         "function",
+	"fuction":          "function",
+	"funciton":         "function",
+	"funtion":          "function",
+	"gaurantee":        "guarantee",
+	"genral":           "general",
+	"goverment":        "government",
+	"grammer":          "grammar",
+	"handeling":        "handling",
+	"heigth":           "height",
+	"heirarchy":        "hierarchy",
+	"identifer":        "identifier",
+	"immediatly":       "immediately",
+	"implemention":     "implementation",
+	"implmentation":    "implementation",
+	"incomming":        "incoming",
+	"incorect":         "incorrect",
+	"independant":      "independent",
+	"infomation":       "information",
+	"initalize":        "initialize",
+	"initialze":        "initialize",
+	"instaed":          "instead",
+	"intead":           "instead",
+	"intial":           "initial",
+	"invaild":          "invalid",
+	"isnt":             "isn't",
+	"lenght":           "length",
+	"libary":           "library",
+	"maintainance":     "maintenance",
+	"maintenence":      "maintenance",
+	"managment":        "management",
+	"mesage":           "message",
+	"messsage":         "message",
+	"minumum":          "minimum",
+	"mispell":          "misspell",
+	"mispelled":        "misspelled",
+	"neccessary":       "necessary",
+	"necesary":         "necessary",
+	"nubmer":           "number",
+	"occured":          "occurred",
+	"occurence":        "occurrence",
+	"occurrance":       "occurrence",
+	"ommit":            "omit",
+	"optinal":          "optional",
+	"optionnal":        "optional",
+	"paramter":         "parameter",
+	"paramters":        "parameters",
+	"parmeter":         "parameter",
+	"performace":       "performance",
+	"permision":        "permission",
+	"persistant":       "persistent",
+	"posible":          "possible",
+	"preceeding":       "preceding",
+	"prefered":         "preferred",
+	"presense":         "presence",
+	"previos":          "previous",
+	"priviledge":       "privilege",
+	"probaly":          "probably",
+	"proccess":         "process",
+	"programatically":  "programmatically",
+	"propery":          "property",
+	"publically":       "publicly",
+	"recieve":          "receive",
+	"recieved":         "received",
+	"recomend":         "recommend",
+	"recommand":        "recommend",
+	"refered":          "referred",
+	"refrence":         "reference",
+	"relevent":         "relevant",
+	"remvoe":           "remove",
+	"reponse":          "response",
+	"repositry":        "repository",
+	"requried":         "required",
+	"resouce":          "resource",
+	"responsability":   "responsibility",
+	"retreive":         "retrieve",
+	"seperate":         "separate",
+	"seperator":        "separator",
+	"shouldnt":         "shouldn't",
+	"succesful":        "successful",
+	"successfull":      "successful",
+	"sucess":           "success",
+	"suport":           "support",
+	"supress":          "suppress",
+	"sytem":            "system",
+	"teh":              "the",
+	"threshhold":       "threshold",
+	"tranlsation":      "translation",
+	"transfered":       "transferred",
+	"truely":           "truly",
+	"udpate":           "update",
+	"unecessary":       "unnecessary",
+	"unneccessary":     "unnecessary",
+	"untill":           "until",
+	"upate":            "update",
+	"usefull":          "useful",
+	"usualy":           "usually",
+	"valiadtion":       "validation",
+	"varaible":         "variable",
+	"verion":           "version",
+	"visable":          "visible",
+	"wasnt":            "wasn't",
+	"wether":           "whether",
+	"wich":             "which",
+	"wierd":            "weird",
+	"withing":          "within",
+	"wouldnt":          "wouldn't",
+	"writting":         "writing",
+}
+
+// codeSpan matches inline code and URLs, which aren't spell checked
+var codeSpan = regexp.MustCompile("`[^`]*`|\\S+://\\S+")
+
+var (
+	dictionary     map[string]bool
+	dictionaryOnce sync.Once
+)
+
+// dictionaryWords returns the lower case words of spell_dictionary, a word
+// list like /usr/share/dict/words or a hunspell .dic file, read once per
+// run; nil without one
+func dictionaryWords() map[string]bool {
+	dictionaryOnce.Do(func() {
+		path := viper.GetString("spell_dictionary")
+		if path == "" {
+			return
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			pterm.Warning.Println("Failed to read the spell_dictionary:", err)
+			return
+		}
+		defer file.Close()
+		dictionary = map[string]bool{}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			// hunspell appends the affix flags as word/FLAGS
+			word, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), "/")
+			dictionary[strings.ToLower(word)] = true
+		}
+	})
+	return dictionary
+}
+
+// misspelling is a word likely misspelled and its suggested fixes
+type misspelling struct {
+	Word        string
+	Suggestions []string
+}
+
+// spellingMistakes returns the words of text which are common misspellings
+// or, with a spell_dictionary, aren't in it. Identifiers, paths, acronyms,
+// inline code, URLs, the commit types, scopes and footer keys and the
+// spell_words are left alone.
+func spellingMistakes(text string) []misspelling {
+	known := map[string]bool{}
+	for _, word := range slices.Concat(viper.GetStringSlice("spell_words"), commitTypes, scopes, viper.GetStringSlice("footer_keys")) {
+		known[strings.ToLower(word)] = true
+	}
+	words := dictionaryWords()
+
+	var mistakes []misspelling
+	seen := map[string]bool{}
+	for _, field := range strings.Fields(codeSpan.ReplaceAllString(text, " ")) {
+		field = strings.TrimFunc(field, func(r rune) bool { return !unicode.IsLetter(r) })
+		for _, word := range strings.Split(field, "-") {
+			word = strings.TrimSuffix(strings.TrimSuffix(word, "'s"), "’s")
+			lower := strings.ToLower(word)
+			if seen[word] || known[lower] || !checkableWord(word) {
+				continue
+			}
+			seen[word] = true
+			if fix, ok := commonMisspellings[lower]; ok {
+				mistakes = append(mistakes, misspelling{Word: word, Suggestions: []string{matchCase(fix, word)}})
+			} else if words != nil && !words[lower] {
+				mistakes = append(mistakes, misspelling{Word: word, Suggestions: spellingSuggestions(lower, word, words)})
+			}
+		}
+	}
+	return mistakes
+}
+
+// checkableWord reports whether word consists of letters only and isn't an
+// acronym or a camelCase identifier
+func checkableWord(word string) bool {
+	if utf8.RuneCountInString(word) < 2 {
+		return false
+	}
+	for i, r := range []rune(word) {
+		if !unicode.IsLetter(r) && r != '\'' && r != '’' {
+			return false
+		}
+		// API, HTTPs and fooBar
+		if i > 0 && unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// spellingSuggestions returns up to three words of the dictionary one edit
+// away from the lower case word, swapped letters first; words beyond ASCII
+// get none
+func spellingSuggestions(lower, word string, words map[string]bool) []string {
+	if len(lower) != utf8.RuneCountInString(lower) {
+		return nil
+	}
+	letters := "abcdefghijklmnopqrstuvwxyz"
+	var edits []string
+	for i := 0; i+1 < len(lower); i++ {
+		edits = append(edits, lower[:i]+string(lower[i+1])+string(lower[i])+lower[i+2:])
+	}
+	for i := range lower {
+		edits = append(edits, lower[:i]+lower[i+1:])
+	}
+	for i := range lower {
+		for _, c := range letters {
+			edits = append(edits, lower[:i]+string(c)+lower[i+1:])
+		}
+	}
+	for i := 0; i <= len(lower); i++ {
+		for _, c := range letters {
+			edits = append(edits, lower[:i]+string(c)+lower[i:])
+		}
+	}
+
+	var suggestions []string
+	for _, edit := range edits {
+		if edit != lower && words[edit] && !slices.Contains(suggestions, matchCase(edit, word)) {
+			suggestions = append(suggestions, matchCase(edit, word))
+			if len(suggestions) == 3 {
+				break
+			}
+		}
+	}
+	return suggestions
+}
+
+// matchCase capitalizes fix like word
+func matchCase(fix, word string) string {
+	first, _ := utf8.DecodeRuneInString(word)
+	if !unicode.IsUpper(first) {
+		return fix
+	}
+	r, size := utf8.DecodeRuneInString(fix)
+	return string(unicode.ToUpper(r)) + fix[size:]
+}
+
+// checkSpelling highlights the likely typos of text, the answer of the
+// prompt named by label such as "short description", and asks for each to keep it or to fix it. The text
+// with the fixes is returned.
+func checkSpelling(label, text string) string {
+	if !viper.GetBool("spell_check") {
+		return text
+	}
+	for _, m := range spellingMistakes(text) {
+		pterm.Warning.Printfln("%q in the %s may be misspelled: %s", m.Word, label, highlightWord(text, m.Word))
+		keep, other := fmt.Sprintf("Keep %q", m.Word), "Type the fix"
+		options := append(slices.Clone(m.Suggestions), keep, other)
+		choice, err := ui.Select(fmt.Sprintf("Fix %q", m.Word), options, options[0])
+		if err != nil || choice == keep {
+			continue
+		}
+		fix := choice
+		if choice == other {
+			if fix, err = ui.Input(fmt.Sprintf("Replace %q with", m.Word), m.Word); err != nil {
+				continue
+			}
+		}
+		text = replaceWord(text, m.Word, strings.TrimSpace(fix))
+	}
+	return text
+}
+
+// wordPattern matches word as a whole word
+func wordPattern(word string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[^\p{L}])` + regexp.QuoteMeta(word) + `($|[^\p{L}])`)
+}
+
+// highlightWord returns the line of text containing word with the word
+// marked
+func highlightWord(text, word string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if loc := wordPattern(word).FindStringSubmatchIndex(line); loc != nil {
+			start, end := loc[3], loc[4]
+			return strings.TrimSpace(line[:start] + pterm.NewStyle(pterm.FgRed, pterm.Underscore).Sprint(word) + line[end:])
+		}
+	}
+	return word
+}
+
+// replaceWord replaces the whole word occurrences of word in text by fix
+func replaceWord(text, word, fix string) string {
+	if fix == "" || fix == word {
+		return text
+	}
+	pattern := wordPattern(word)
+	replacement := "${1}" + strings.ReplaceAll(fix, "$", "$$") + "${2}"
+	// occurrences separated by a single character share it
+	for pattern.MatchString(text) {
+		text = pattern.ReplaceAllString(text, replacement)
+		if strings.Contains(fix, word) {
+			break
+		}
+	}
+	return text
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5"
+	"github.com/pterm/pterm"
+)
+
+// statusLabels describes the worktree status codes of unstaged files
+var statusLabels = map[git.StatusCode]string{
+	git.Untracked: "new file",
+	git.Modified:  "modified",
+	git.Deleted:   "deleted",
+	git.Renamed:   "renamed",
+	git.Copied:    "copied",
+}
+
+// unstagedFiles returns the changed and untracked files which aren't staged,
+// labeled with their status
+func unstagedFiles() ([]string, map[string]string, error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var paths []string
+	labels := map[string]string{}
+	for path, entry := range status {
+		if label, ok := statusLabels[entry.Worktree]; ok {
+			paths = append(paths, path)
+			labels[path] = label
+		}
+	}
+	sort.Strings(paths)
+	return paths, labels, nil
+}
+
+// promptForStaging lets the user pick the files to stage when nothing has
+// been staged yet, it returns false if nothing was picked
+func promptForStaging() bool {
+	paths, labels, err := unstagedFiles()
+	if err != nil {
+		pterm.Error.Println(tr("Failed to get status:"), err)
+		exit(exitcode.Failure)
+	}
+	if len(paths) == 0 {
+		return false
+	}
+
+	options := make([]string, len(paths))
+	byOption := map[string]string{}
+	for i, path := range paths {
+		options[i] = fmt.Sprintf("%-9s %s", labels[path]+":", path)
+		byOption[options[i]] = path
+	}
+
+	selected, _ := ui.MultiSelect(tr("Nothing staged yet, select the files to commit"), options)
+	if len(selected) == 0 {
+		return false
+	}
+
+	args := []string{"add", "--all", "--"}
+	for _, option := range selected {
+		args = append(args, byOption[option])
+	}
+	if err := stageFiles(args...); err != nil {
+		pterm.Error.Println(tr("Failed to stage changes:"), err)
+		exit(exitcode.Failure)
+	}
+	return true
+}
+
+// stageFiles runs git with the given add arguments in the repository root
+func stageFiles(args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = gitRoot
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
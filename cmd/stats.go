@@ -0,0 +1,385 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report statistics about the conventional commits of the history",
+	Long: `Report statistics about the conventional commits of the history: the
+number of commits by type, scope and author and how many are breaking
+changes, e.g. to tell how much of a release went into features, fixes and
+chores. --since takes a ref, such as the tag of the previous release, or a
+date; merges aren't counted.
+
+With --me the personal usage stats recorded with usage_stats are shown
+instead: the commits made through the prompts, the time spent on them and
+the types used. They are kept in usage.jsonl next to the global config and
+never transmitted.`,
+	Example: `  git cc stats --since v1.2.0
+  git cc stats --since "3 months ago" --format json
+  git cc stats scopes
+  git cc stats --me`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         stats,
+}
+
+var statsScopesCmd = &cobra.Command{
+	Use:   "scopes",
+	Short: "Show the churn of each scope by month",
+	Long: `Show a matrix of the scopes and the months they were changed in, with the
+number of commits and the lines inserted and deleted, busiest scopes first.
+Commits without a scope are counted as (none), merges and commits which
+aren't conventional are skipped.`,
+	Example: `  git cc stats scopes --since v1.0.0
+  git cc stats scopes --format csv > churn.csv`,
+	Annotations: readOnlyCommand,
+	Args:        cobra.NoArgs,
+	Run:         statsScopes,
+}
+
+func init() {
+	statsCmd.Flags().String("since", "", "Count commits after this ref or date (default: whole history)")
+	statsCmd.Flags().String("until", "HEAD", "Count commits up to this ref")
+	statsCmd.Flags().String("format", "terminal", "Output format, terminal or json")
+	statsCmd.Flags().Bool("me", false, "Show your usage stats recorded locally")
+	statsCmd.Flags().Bool("collapse-dependencies", false, "Count dependency updates apart from the types and scopes (default collapse_dependencies)")
+	statsScopesCmd.Flags().String("since", "", "Count commits after this ref (default: whole history)")
+	statsScopesCmd.Flags().String("until", "HEAD", "Count commits up to this ref")
+	statsScopesCmd.Flags().String("format", "terminal", "Output format, terminal, markdown or csv")
+	statsCmd.AddCommand(statsScopesCmd)
+	rootCmd.AddCommand(statsCmd)
+}
+
+func stats(cmd *cobra.Command, args []string) {
+	if me, _ := cmd.Flags().GetBool("me"); !me {
+		viper.BindPFlag("collapse_dependencies", cmd.Flags().Lookup("collapse-dependencies"))
+		since, _ := cmd.Flags().GetString("since")
+		until, _ := cmd.Flags().GetString("until")
+		format, _ := cmd.Flags().GetString("format")
+		statsHistory(since, until, format)
+		return
+	}
+
+	records, err := readUsage()
+	if err != nil {
+		pterm.Error.Println("Failed to read usage stats:", err)
+		exit(exitcode.Failure)
+	}
+	if len(records) == 0 {
+		if usageStats {
+			pterm.Info.Println("No commits recorded yet")
+		} else {
+			pterm.Info.Println("Usage stats are off, set usage_stats: true in the global config to record them locally")
+		}
+		return
+	}
+
+	types := map[string]int{}
+	recent := 0
+	durations := make([]float64, len(records))
+	for i, r := range records {
+		types[r.Type]++
+		if time.Since(r.Time) < 30*24*time.Hour {
+			recent++
+		}
+		durations[i] = r.Seconds
+	}
+	slices.Sort(durations)
+	total := 0.0
+	for _, d := range durations {
+		total += d
+	}
+	seconds := func(s float64) string { return (time.Duration(s) * time.Second).String() }
+
+	pterm.DefaultSection.Println("Your commits")
+	pterm.Printfln("Commits made through the prompts: %s (%s in the last 30 days, since %s)", formatNumber(len(records)), formatNumber(recent), formatDate(records[0].Time))
+	pterm.Printfln("Time per commit: %s on average, %s median", seconds(total/float64(len(records))), seconds(durations[len(durations)/2]))
+	pterm.Println()
+	renderCounts("Type", types, len(records))
+}
+
+// historyStats are the counts reported by git cc stats
+type historyStats struct {
+	Commits      int `json:"commits"`
+	Conventional int `json:"conventional"`
+	Breaking     int `json:"breaking"`
+	// Dependencies are the commits of dependency bots and the build and
+	// chore commits scoped deps, DependencyGroups counts them by type and
+	// scope. Collapsed they aren't counted by type and scope otherwise.
+	Dependencies     int            `json:"dependency_updates"`
+	DependencyGroups map[string]int `json:"dependency_groups"`
+	Types            map[string]int `json:"types"`
+	Scopes           map[string]int `json:"scopes"`
+	Authors          map[string]int `json:"authors"`
+}
+
+func statsHistory(since, until, format string) {
+	if format != "terminal" && format != "json" {
+		pterm.Error.Printfln("unknown format %q, expected terminal or json", format)
+		exit(exitcode.Failure)
+	}
+
+	logArgs := []string{"log", "--no-merges", "--format=%H"}
+	if since == "" {
+		logArgs = append(logArgs, until)
+	} else if _, err := gitQuiet("rev-parse", "--verify", "--quiet", since+"^{commit}"); err == nil {
+		logArgs = append(logArgs, since+".."+until)
+	} else {
+		// not a ref, git parses it as a date
+		logArgs = append(logArgs, "--since="+since, until)
+	}
+	out, err := gitOutput(logArgs...)
+	if err != nil {
+		pterm.Error.Println("Failed to read history:", err)
+		exit(exitcode.Failure)
+	}
+
+	report := historyStats{DependencyGroups: map[string]int{}, Types: map[string]int{}, Scopes: map[string]int{}, Authors: map[string]int{}}
+	collapse := viper.GetBool("collapse_dependencies")
+	for _, hash := range strings.Fields(out) {
+		commit, err := repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			pterm.Error.Println("Failed to read commit:", err)
+			exit(exitcode.Failure)
+		}
+		report.Commits++
+		report.Authors[commit.Author.Name]++
+		c := parseHistoryCommit(commit)
+		if !c.Valid && c.DependencyBot == "" {
+			report.Types["(not conventional)"]++
+			continue
+		}
+		if c.Valid {
+			report.Conventional++
+		}
+		if c.Data.BreakingChange {
+			report.Breaking++
+		}
+		if c.DependencyUpdate() {
+			report.Dependencies++
+			group := c.Data.Type
+			if c.Data.Scope != "" {
+				group += "(" + c.Data.Scope + ")"
+			}
+			report.DependencyGroups[group]++
+			if collapse {
+				continue
+			}
+		}
+		report.Types[c.Data.Type]++
+		scope := c.Data.Scope
+		if scope == "" {
+			scope = "(none)"
+		}
+		report.Scopes[scope]++
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Failure)
+		}
+		return
+	}
+	if report.Commits == 0 {
+		pterm.Info.Println("No commits found")
+		return
+	}
+	pterm.Info.Printfln("%s commits, %s of them conventional and %s breaking changes", formatNumber(report.Commits), formatNumber(report.Conventional), formatNumber(report.Breaking))
+	if report.Dependencies > 0 {
+		pterm.Info.Println(dependencyUpdates(report.Dependencies))
+	}
+	counted := report.Commits
+	if collapse {
+		counted -= report.Dependencies
+	}
+	if counted > 0 {
+		renderCounts("Type", report.Types, counted)
+	}
+	// commits of dependency bots have a scope too
+	scoped := 0
+	for _, n := range report.Scopes {
+		scoped += n
+	}
+	if scoped > 0 {
+		fmt.Println()
+		renderCounts("Scope", report.Scopes, scoped)
+	}
+	if len(report.DependencyGroups) > 1 && !collapse {
+		fmt.Println()
+		renderCounts("Dependencies", report.DependencyGroups, report.Dependencies)
+	}
+	fmt.Println()
+	renderCounts("Author", report.Authors, report.Commits)
+}
+
+// churn counts the commits and changed lines of a scope in a month
+type churn struct {
+	Commits    int
+	Insertions int
+	Deletions  int
+}
+
+func (c churn) String() string {
+	if c.Commits == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s (+%s/-%s)", formatNumber(c.Commits), formatNumber(c.Insertions), formatNumber(c.Deletions))
+}
+
+func statsScopes(cmd *cobra.Command, args []string) {
+	since, _ := cmd.Flags().GetString("since")
+	until, _ := cmd.Flags().GetString("until")
+	format, _ := cmd.Flags().GetString("format")
+	if format != "terminal" && format != "markdown" && format != "csv" {
+		pterm.Error.Printfln("unknown format %q, expected terminal, markdown or csv", format)
+		exit(exitcode.Failure)
+	}
+
+	revisions := until
+	if since != "" {
+		revisions = since + ".." + until
+	}
+	// one record per commit: month and subject, then the changed lines
+	out, err := gitOutput("log", "--no-merges", "--numstat", "--format=%x1e%cd%x00%s", "--date=format:%Y-%m", revisions)
+	if err != nil {
+		pterm.Error.Println("Failed to read history:", err)
+		exit(exitcode.Failure)
+	}
+
+	matrix := map[string]map[string]churn{}
+	totals := map[string]int{}
+	// lines changed in the busiest cell, the scale of the heat
+	busiest := 0
+	var months []string
+	for _, record := range strings.Split(out, "\x1e")[1:] {
+		header, numstat, _ := strings.Cut(record, "\n")
+		month, subject, _ := strings.Cut(header, "\x00")
+		match := headerPattern.FindStringSubmatch(subject)
+		if match == nil {
+			continue
+		}
+		scope := match[2]
+		if scope == "" {
+			scope = "(none)"
+		}
+
+		changed := churn{Commits: 1}
+		for _, line := range strings.Split(strings.TrimSpace(numstat), "\n") {
+			// binary files have - instead of line counts
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				continue
+			}
+			added, _ := strconv.Atoi(fields[0])
+			deleted, _ := strconv.Atoi(fields[1])
+			changed.Insertions += added
+			changed.Deletions += deleted
+		}
+
+		if matrix[scope] == nil {
+			matrix[scope] = map[string]churn{}
+		}
+		c := matrix[scope][month]
+		c.Commits += changed.Commits
+		c.Insertions += changed.Insertions
+		c.Deletions += changed.Deletions
+		matrix[scope][month] = c
+		totals[scope] += changed.Insertions + changed.Deletions
+		busiest = max(busiest, c.Insertions+c.Deletions)
+		if !slices.Contains(months, month) {
+			months = append(months, month)
+		}
+	}
+	if len(matrix) == 0 {
+		pterm.Info.Println("No conventional commits found")
+		return
+	}
+
+	slices.Sort(months)
+	scopeNames := make([]string, 0, len(matrix))
+	for scope := range matrix {
+		scopeNames = append(scopeNames, scope)
+	}
+	slices.SortFunc(scopeNames, func(a, b string) int {
+		if totals[a] != totals[b] {
+			return totals[b] - totals[a]
+		}
+		return strings.Compare(a, b)
+	})
+
+	switch format {
+	case "csv":
+		// one row per scope and month, easier to pivot than the matrix
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"scope", "month", "commits", "insertions", "deletions"})
+		for _, scope := range scopeNames {
+			for _, month := range months {
+				if c, ok := matrix[scope][month]; ok {
+					w.Write([]string{scope, month, strconv.Itoa(c.Commits), strconv.Itoa(c.Insertions), strconv.Itoa(c.Deletions)})
+				}
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			pterm.Error.Println(err)
+			exit(exitcode.Failure)
+		}
+	case "markdown":
+		fmt.Println("| Scope | " + strings.Join(months, " | ") + " |")
+		fmt.Println("| --- |" + strings.Repeat(" ---: |", len(months)))
+		for _, scope := range scopeNames {
+			row := []string{scope}
+			for _, month := range months {
+				row = append(row, matrix[scope][month].String())
+			}
+			fmt.Println("| " + strings.Join(row, " | ") + " |")
+		}
+	default:
+		table := pterm.TableData{append([]string{"Scope"}, months...)}
+		for _, scope := range scopeNames {
+			row := []string{scope}
+			for _, month := range months {
+				row = append(row, heat(matrix[scope][month], busiest))
+			}
+			table = append(table, row)
+		}
+		pterm.DefaultTable.WithHasHeader().WithData(table).Render()
+	}
+}
+
+// heat colors the churn of a cell by its share of the busiest cell's
+func heat(c churn, busiest int) string {
+	if c.Commits == 0 {
+		return ""
+	}
+	share := 0.0
+	if busiest > 0 {
+		share = float64(c.Insertions+c.Deletions) / float64(busiest)
+	}
+	switch {
+	case share >= 0.5:
+		return pterm.Red(c.String())
+	case share >= 0.2:
+		return pterm.Yellow(c.String())
+	}
+	return c.String()
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/45413/git-cc/pkg/conventional"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+var (
+	// suggest asks the suggest_endpoint for a message pre-filling the
+	// prompts, set by --suggest. Nothing is sent without it.
+	suggest bool
+	// suggestEndpoint is the suggest_endpoint of the global config
+	suggestEndpoint string
+)
+
+// suggestClient is patient, models take a while to answer
+var suggestClient = &http.Client{Timeout: 60 * time.Second}
+
+// suggestInstructions tells the model what to answer
+const suggestInstructions = `You write git commit messages following the Conventional Commits spec.
+Answer with the commit message only, no explanations and no code fences: a header
+"type(scope): description" in the imperative mood, optionally followed by a blank
+line and a body explaining what changed and why.`
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// suggestMessage sends the staged diff to the OpenAI compatible chat
+// completions API at suggest_endpoint and returns the suggested answers
+func suggestMessage() (CommitPromptData, error) {
+	endpoint := os.Getenv("GIT_CC_SUGGEST_ENDPOINT")
+	if endpoint == "" {
+		endpoint = suggestEndpoint
+	}
+	model := viper.GetString("suggest_model")
+	if endpoint == "" || model == "" {
+		return CommitPromptData{}, fmt.Errorf("set suggest_endpoint in the global config and suggest_model, e.g. http://localhost:11434/v1 and llama3.2 for Ollama")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return CommitPromptData{}, fmt.Errorf("suggest_endpoint must be an http(s) URL, not %q", endpoint)
+	}
+
+	diff, err := gitOutput(append([]string{"diff", "--cached", "--no-color", "--no-ext-diff"}, excludeGeneratedStaged()...)...)
+	if err != nil {
+		return CommitPromptData{}, err
+	}
+	if limit := viper.GetInt("suggest_max_diff"); limit > 0 && len(diff) > limit {
+		diff = diff[:limit] + "\n[diff truncated]"
+	}
+
+	context := "Allowed types: " + strings.Join(commitTypes, ", ")
+	if len(scopes) > 0 {
+		context += "\nAllowed scopes: " + strings.Join(scopes, ", ")
+	}
+	if limit := viper.GetInt("max_subject_length"); limit > 0 {
+		context += fmt.Sprintf("\nThe header must not exceed %d characters.", limit)
+	}
+	request, err := json.Marshal(chatRequest{
+		Model: model,
+		Messages: []chatMessage{
+			{Role: "system", Content: suggestInstructions + "\n" + context},
+			{Role: "user", Content: diff},
+		},
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return CommitPromptData{}, err
+	}
+
+	// say where the diff goes before it leaves the machine
+	pterm.Info.Printfln("Sending the staged diff to %s for a suggestion", u.Host)
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/chat/completions", bytes.NewReader(request))
+	if err != nil {
+		return CommitPromptData{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// the key is never read from a config, which a repository could ship
+	if key := os.Getenv("GIT_CC_SUGGEST_API_KEY"); key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+
+	resp, err := suggestClient.Do(req)
+	if err != nil {
+		return CommitPromptData{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return CommitPromptData{}, err
+	}
+	if resp.StatusCode >= 300 {
+		return CommitPromptData{}, fmt.Errorf("POST %s: %s", req.URL.Redacted(), resp.Status)
+	}
+
+	var chat chatResponse
+	if err := json.Unmarshal(body, &chat); err != nil {
+		return CommitPromptData{}, fmt.Errorf("unexpected response: %w", err)
+	}
+	if len(chat.Choices) == 0 {
+		return CommitPromptData{}, fmt.Errorf("the response contains no suggestion")
+	}
+
+	// models like to wrap their answer in a code fence anyway
+	answer := strings.TrimSpace(chat.Choices[0].Message.Content)
+	if strings.HasPrefix(answer, "```") {
+		_, answer, _ = strings.Cut(answer, "\n")
+		answer = strings.TrimSuffix(strings.TrimSpace(answer), "```")
+	}
+	c, err := conventional.Parse(answer)
+	if err != nil {
+		return CommitPromptData{}, fmt.Errorf("the suggestion is no conventional commit: %w", err)
+	}
+	return CommitPromptData{
+		Type:             c.Type,
+		Scope:            c.Scope,
+		ShortDescription: c.Description,
+		LongDescription:  c.Body,
+	}, nil
+}
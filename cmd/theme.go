@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// themeColors are the color names of theme properties
+var themeColors = map[string]pterm.Color{
+	"default":       pterm.FgDefault,
+	"black":         pterm.FgBlack,
+	"red":           pterm.FgRed,
+	"green":         pterm.FgGreen,
+	"yellow":        pterm.FgYellow,
+	"blue":          pterm.FgBlue,
+	"magenta":       pterm.FgMagenta,
+	"cyan":          pterm.FgCyan,
+	"white":         pterm.FgWhite,
+	"gray":          pterm.FgGray,
+	"light-red":     pterm.FgLightRed,
+	"light-green":   pterm.FgLightGreen,
+	"light-yellow":  pterm.FgLightYellow,
+	"light-blue":    pterm.FgLightBlue,
+	"light-magenta": pterm.FgLightMagenta,
+	"light-cyan":    pterm.FgLightCyan,
+	"light-white":   pterm.FgLightWhite,
+}
+
+// themeAttributes are the text attributes of theme properties
+var themeAttributes = map[string]pterm.Color{
+	"bold":      pterm.Bold,
+	"italic":    pterm.Italic,
+	"underline": pterm.Underscore,
+	"reverse":   pterm.Reverse,
+}
+
+// themeElement is what a theme property styles: the messages of a level
+// with their prefix label, or a part of the prompts
+type themeElement struct {
+	message *pterm.Style
+	prefix  *pterm.Style
+}
+
+// themeElements are the theme properties, pterm's printers and prompts
+// refer to the styles of its default theme
+var themeElements = map[string]themeElement{
+	"info":        {&pterm.ThemeDefault.InfoMessageStyle, &pterm.ThemeDefault.InfoPrefixStyle},
+	"success":     {&pterm.ThemeDefault.SuccessMessageStyle, &pterm.ThemeDefault.SuccessPrefixStyle},
+	"warning":     {&pterm.ThemeDefault.WarningMessageStyle, &pterm.ThemeDefault.WarningPrefixStyle},
+	"error":       {&pterm.ThemeDefault.ErrorMessageStyle, &pterm.ThemeDefault.ErrorPrefixStyle},
+	"debug":       {&pterm.ThemeDefault.DebugMessageStyle, &pterm.ThemeDefault.DebugPrefixStyle},
+	"description": {&pterm.ThemeDefault.DescriptionMessageStyle, &pterm.ThemeDefault.DescriptionPrefixStyle},
+	"prompt":      {message: &pterm.ThemeDefault.PrimaryStyle},
+	"selector":    {message: &pterm.ThemeDefault.SecondaryStyle},
+	"highlight":   {message: &pterm.ThemeDefault.HighlightStyle},
+}
+
+// themePresets are the styles of the presets besides default, which keeps
+// pterm's. light suits light backgrounds, high-contrast doesn't rely on
+// colors: the prefix labels tell the levels apart.
+var themePresets = map[string]map[string]string{
+	"light": {
+		"info":        "blue",
+		"success":     "green",
+		"warning":     "magenta",
+		"error":       "red",
+		"debug":       "gray",
+		"description": "default",
+		"prompt":      "blue bold",
+		"selector":    "magenta bold",
+		"highlight":   "default bold",
+	},
+	"high-contrast": {
+		"info":        "default",
+		"success":     "default",
+		"warning":     "default bold",
+		"error":       "default bold",
+		"debug":       "default",
+		"description": "default",
+		"prompt":      "default bold",
+		"selector":    "default bold underline",
+		"highlight":   "default bold underline",
+	},
+}
+
+// applyTheme styles pterm's output as the theme config section says. The
+// no-color preset, --no-color and NO_COLOR turn colors off altogether.
+func applyTheme(noColor bool) error {
+	preset := viper.GetString("theme.preset")
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		preset = "no-color"
+	}
+	switch preset {
+	case "", "default":
+	case "no-color":
+		pterm.DisableColor()
+		return nil
+	default:
+		styles, ok := themePresets[preset]
+		if !ok {
+			return fmt.Errorf("unknown theme.preset %q, expected default, light, high-contrast or no-color", preset)
+		}
+		for name, value := range styles {
+			setThemeStyle(themeElements[name], value)
+		}
+	}
+
+	for name, element := range themeElements {
+		value := viper.GetString("theme." + name)
+		if value == "" {
+			continue
+		}
+		if err := setThemeStyle(element, value); err != nil {
+			return fmt.Errorf("invalid theme.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setThemeStyle sets the style of element to value, a color and text
+// attributes such as "blue bold". The prefix label gets the color as its
+// background, or reverse video for the default color.
+func setThemeStyle(element themeElement, value string) error {
+	color := pterm.FgDefault
+	var attributes []pterm.Color
+	for _, word := range strings.Fields(strings.ToLower(value)) {
+		if c, ok := themeColors[word]; ok {
+			color = c
+		} else if a, ok := themeAttributes[word]; ok {
+			attributes = append(attributes, a)
+		} else {
+			return fmt.Errorf("unknown color or attribute %q", word)
+		}
+	}
+	*element.message = append(pterm.Style{color}, attributes...)
+	if element.prefix == nil {
+		return nil
+	}
+	switch {
+	case color == pterm.FgDefault:
+		*element.prefix = pterm.Style{pterm.Reverse, pterm.Bold}
+	case color == pterm.FgGreen || color == pterm.FgYellow || color == pterm.FgCyan || color == pterm.FgWhite || color >= pterm.FgLightRed:
+		// bright backgrounds need dark text
+		*element.prefix = pterm.Style{pterm.FgBlack, color + 10}
+	default:
+		*element.prefix = pterm.Style{pterm.FgLightWhite, color + 10}
+	}
+	return nil
+}
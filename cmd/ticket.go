@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// branchTicket returns the ticket ID found in the current branch name by
+// ticket_pattern, e.g. PROJ-123 for feature/PROJ-123-login. With Jira set
+// up, Jira keys are found without a pattern.
+func branchTicket() string {
+	pattern := viper.GetString("ticket_pattern")
+	re := jiraKeyPattern
+	if pattern == "" && !jiraEnabled() {
+		return ""
+	} else if pattern != "" {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			pterm.Warning.Printfln("ignoring invalid ticket_pattern %q: %s", pattern, err)
+			return ""
+		}
+	}
+
+	head, err := repo.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return ""
+	}
+	return re.FindString(head.Name().Short())
+}
+
+// withBranchTicket adds the ticket of the current branch to data as footer
+// and, with ticket_as_scope, as scope unless one is given already
+func withBranchTicket(data CommitPromptData) CommitPromptData {
+	ticket := branchTicket()
+	if ticket == "" {
+		return data
+	}
+
+	if viper.GetBool("ticket_as_scope") && (data.Scope == "" || data.Scope == "none") {
+		data.Scope = ticket
+	}
+
+	// Jira tickets are added as jira_footer once checked
+	if token := viper.GetString("ticket_footer"); token != "" && !jiraEnabled() {
+		// GitHub style references read "Refs #123"
+		t := trailer{Token: token, Separator: ": ", Value: ticket}
+		if value, ok := strings.CutPrefix(ticket, "#"); ok {
+			t = trailer{Token: token, Separator: " #", Value: value}
+		}
+		data.Footers = setTrailer(append([]trailer{}, data.Footers...), t, false)
+	}
+	return data
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// timeoutGrace is how long a timed out git commit may take to exit after
+// SIGTERM before it is killed
+const timeoutGrace = 10 * time.Second
+
+// tailBuffer keeps the last bytes written to it
+type tailBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	n, err := t.Buffer.Write(p)
+	if over := t.Len() - t.limit; over > 0 {
+		t.Next(over)
+	}
+	return n, err
+}
+
+// lastLines returns the last n lines written
+func (t *tailBuffer) lastLines(n int) string {
+	lines := strings.Split(strings.TrimRight(t.String(), "\n"), "\n")
+	return strings.Join(lines[max(len(lines)-n, 0):], "\n")
+}
+
+// runGitCommit runs git commit with args, stopping it with its hooks when
+// it runs longer than commit_timeout or git-cc is terminated
+func runGitCommit(args []string, stdout io.Writer) error {
+	ctx := context.Background()
+	timeout := viper.GetDuration("commit_timeout")
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// the end of the output tells which hook or prompt got stuck
+	tail := &tailBuffer{limit: 4096}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = io.MultiWriter(stdout, tail)
+	cmd.Stderr = io.MultiWriter(os.Stderr, tail)
+	passTerminal(cmd)
+	ownProcessGroup(cmd)
+	cmd.Cancel = func() error { return signalProcess(cmd, syscall.SIGTERM) }
+	cmd.WaitDelay = timeoutGrace
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// signals ending git-cc end git commit and its hooks too
+	stopped := atExit(func() {
+		if cmd.ProcessState == nil {
+			signalProcess(cmd, exitSignal)
+		}
+	})
+	err := cmd.Wait()
+	stopped()
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// hooks ignoring SIGTERM are killed
+		signalProcess(cmd, syscall.SIGKILL)
+		err = fmt.Errorf("git commit timed out after %s and was stopped", timeout)
+		if output := strings.TrimSpace(tail.lastLines(10)); output != "" {
+			err = fmt.Errorf("%w, its last output was:\n%s", err, output)
+		}
+	}
+	return err
+}
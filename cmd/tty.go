@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// passTerminal lets git processes which may ask for credentials or, when
+// signing, a GPG passphrase prompt on the terminal: they read the stdin of
+// git-cc, the cursor hidden by the prompts is shown again and pinentry is
+// told the terminal by GPG_TTY
+func passTerminal(cmd *exec.Cmd) {
+	cmd.Stdin = os.Stdin
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return
+	}
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Print("\033[?25h")
+	}
+	if os.Getenv("GPG_TTY") != "" {
+		return
+	}
+	if tty := terminalName(); tty != "" {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, "GPG_TTY="+tty)
+	}
+}
+
+var terminalNameCache *string
+
+// terminalName returns the device of the terminal on stdin, as tty prints
+// it, or an empty string where there is no tty command
+func terminalName() string {
+	if terminalNameCache == nil {
+		tty := exec.Command("tty")
+		tty.Stdin = os.Stdin
+		out, err := tty.Output()
+		name := strings.TrimSpace(string(out))
+		if err != nil {
+			name = ""
+		}
+		terminalNameCache = &name
+	}
+	return *terminalNameCache
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pterm/pterm"
+)
+
+// messagePreviewer is implemented by frontends showing the message while it
+// is being written
+type messagePreviewer interface {
+	Preview(message string)
+}
+
+// answersChanged saves the answers so far as draft, keeping the defaults of
+// the prompts still to come, and passes the message built from them to the
+// frontend if it shows a preview
+func answersChanged(data CommitPromptData) {
+	saved := withPendingDefaults(data)
+	answersMu.Lock()
+	answersSoFar = &saved
+	answersMu.Unlock()
+	saveDraft(saved)
+	if previewer, ok := ui.(messagePreviewer); ok {
+		message, err := renderCommitMessage(data)
+		if err != nil {
+			message = buildCommitMessage(data)
+		}
+		previewer.Preview(message)
+	}
+}
+
+// tuiUI is a full-screen frontend redrawing the staged files next to a
+// preview of the message before every prompt
+type tuiUI struct {
+	ptermUI
+	preview string
+}
+
+func (t *tuiUI) Preview(message string) {
+	t.preview = message
+}
+
+// draw clears the screen and renders the staged files and message preview
+func (t *tuiUI) draw() {
+	fmt.Print("\033[H\033[2J")
+	pterm.Println(promptBanner())
+
+	staged, err := gitOutput("diff", "--cached", "--name-status")
+	if err != nil || strings.TrimSpace(staged) == "" {
+		staged = "nothing staged"
+	} else {
+		staged = collapseGenerated(staged)
+	}
+	preview := t.preview
+	if preview == "" {
+		preview = pterm.Gray("answer the prompts below")
+	}
+
+	files := pterm.DefaultBox.WithTitle("Staged Files").Sprint(strings.ReplaceAll(strings.TrimSpace(staged), "\t", "  "))
+	message := pterm.DefaultBox.WithTitle("Commit Message").Sprint(preview)
+	pterm.DefaultPanel.WithPanels(pterm.Panels{{{Data: files}, {Data: message}}}).Render()
+}
+
+func (t *tuiUI) Select(label string, options []string, defaultOption string) (string, error) {
+	t.draw()
+	return t.ptermUI.Select(label, options, defaultOption)
+}
+
+func (t *tuiUI) MultiSelect(label string, options []string) ([]string, error) {
+	t.draw()
+	return t.ptermUI.MultiSelect(label, options)
+}
+
+func (t *tuiUI) Input(label, defaultValue string) (string, error) {
+	t.draw()
+	return t.ptermUI.Input(label, defaultValue)
+}
+
+func (t *tuiUI) MultilineInput(label, defaultValue string) (string, error) {
+	t.draw()
+	return t.ptermUI.MultilineInput(label, defaultValue)
+}
+
+func (t *tuiUI) Confirm(label string, defaultValue bool) (bool, error) {
+	t.draw()
+	return t.ptermUI.Confirm(label, defaultValue)
+}
+
+// collapseGenerated replaces the generated files in the --name-status
+// listing staged with a count, so they don't push the others off screen
+func collapseGenerated(staged string) string {
+	lines := strings.Split(strings.TrimSpace(staged), "\n")
+	paths := make([]string, len(lines))
+	for i, line := range lines {
+		fields := strings.Split(line, "\t")
+		paths[i] = fields[len(fields)-1]
+	}
+	generated := generatedFiles(paths)
+	if len(generated) == 0 {
+		return staged
+	}
+
+	var kept []string
+	for i, line := range lines {
+		if !generated[paths[i]] {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n") + "\n" + pterm.Gray(fmt.Sprintf("%d generated", len(generated)))
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"errors"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// kinds of staged changes which suggest a type, mapped to it by type_suggestions
+const (
+	renameChange     = "rename"
+	formattingChange = "formatting"
+)
+
+// defaultTypeSuggestions are the types suggested unless type_suggestions
+// configures others
+var defaultTypeSuggestions = map[string]string{renameChange: "refactor", formattingChange: "style"}
+
+// suggestedType returns the type to preselect for staged changes which only
+// rename files or only change whitespace, if it is one of commitTypes
+func suggestedType(commitTypes []string) string {
+	kind := stagedChangeKind()
+	if kind == "" {
+		return ""
+	}
+	// configuring one kind keeps the default of the other, viper lowercases
+	// map keys
+	suggestion, ok := viper.GetStringMapString("type_suggestions")[kind]
+	if !ok {
+		suggestion = defaultTypeSuggestions[kind]
+	}
+	if !slices.Contains(commitTypes, suggestion) {
+		return ""
+	}
+	pterm.Debug.Printfln("Staged changes are %s only, suggesting %s", kind, suggestion)
+	return suggestion
+}
+
+// stagedChangeKind reports whether all staged changes are renames of files
+// at least rename_similarity percent alike, or changes of whitespace and
+// blank lines only
+func stagedChangeKind() string {
+	similarity := min(max(viper.GetInt("rename_similarity"), 1), 100)
+	// regenerated files follow the change of their source
+	exclude := excludeGeneratedStaged()
+	out, err := gitOutput(append([]string{"diff", "--cached", "--name-status", "-M" + strconv.Itoa(similarity) + "%"}, exclude...)...)
+	if err != nil {
+		pterm.Debug.Println("Failed to list staged files:", err)
+		return ""
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if lines[0] == "" {
+		return ""
+	}
+	renamesOnly := true
+	for _, line := range lines {
+		status, _, _ := strings.Cut(line, "\t")
+		if !strings.HasPrefix(status, "R") && !strings.HasPrefix(status, "M") {
+			// added or removed files change more than the formatting
+			return ""
+		}
+		renamesOnly = renamesOnly && strings.HasPrefix(status, "R")
+	}
+	if renamesOnly {
+		return renameChange
+	}
+
+	cmd := exec.Command("git", append([]string{"diff", "--cached", "--quiet", "--ignore-all-space", "--ignore-blank-lines", "-M" + strconv.Itoa(similarity) + "%"}, exclude...)...)
+	cmd.Dir = gitRoot
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err == nil {
+		return formattingChange
+	} else if !errors.As(err, &exitErr) {
+		pterm.Debug.Println("Failed to diff staged files:", err)
+	}
+	return ""
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+)
+
+// promptUI asks the user questions, implementations are selected with the
+// ui config key or --ui flag
+type promptUI interface {
+	// Select returns one of options, defaultOption is preselected if not empty
+	Select(label string, options []string, defaultOption string) (string, error)
+	// MultiSelect returns the options picked by the user
+	MultiSelect(label string, options []string) ([]string, error)
+	// Input returns a single line of text, or defaultValue when nothing is entered
+	Input(label, defaultValue string) (string, error)
+	// MultilineInput returns several lines of text, or defaultValue when nothing is entered
+	MultilineInput(label, defaultValue string) (string, error)
+	Confirm(label string, defaultValue bool) (bool, error)
+}
+
+// promptUIs are the available frontends by name
+var promptUIs = map[string]func() promptUI{
+	"pterm": func() promptUI { return ptermUI{} },
+	"plain": func() promptUI { return &plainUI{in: bufio.NewReader(os.Stdin), out: os.Stdout} },
+	"tui":   func() promptUI { return &tuiUI{} },
+}
+
+// ui is the frontend used by all prompts, set by startup
+var ui promptUI = ptermUI{}
+
+// newPromptUI returns the frontend called name
+func newPromptUI(name string) (promptUI, error) {
+	create, ok := promptUIs[name]
+	if !ok {
+		names := make([]string, 0, len(promptUIs))
+		for n := range promptUIs {
+			names = append(names, n)
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("unknown ui %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return create(), nil
+}
+
+// interactiveTerminal reports whether the prompts can be drawn: stdin and
+// the output are terminals and TERM isn't dumb. The output is stderr when
+// stdout is reserved for the message or JSON.
+func interactiveTerminal() bool {
+	if os.Getenv("TERM") == "dumb" || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+	out := os.Stdout
+	if dryRun || printJSON || outputFormat == "json" {
+		out = os.Stderr
+	}
+	return term.IsTerminal(int(out.Fd()))
+}
+
+// ptermUI renders the prompts with pterm's interactive printers
+type ptermUI struct{}
+
+// interrupted handles ctrl+c in pterm's prompts, which read the keyboard
+// in raw mode and therefore receive no SIGINT
+func interrupted() {
+	exit(exitcode.Interrupted)
+}
+
+func (ptermUI) Select(label string, options []string, defaultOption string) (string, error) {
+	defer awaitAnswer()()
+	p := pterm.DefaultInteractiveSelect.WithOptions(options).WithDefaultText(label).WithMaxHeight(20).WithOnInterruptFunc(interrupted)
+	if defaultOption != "" {
+		p = p.WithDefaultOption(defaultOption)
+	}
+	return p.Show()
+}
+
+func (ptermUI) MultiSelect(label string, options []string) ([]string, error) {
+	defer awaitAnswer()()
+	return pterm.DefaultInteractiveMultiselect.WithOptions(options).WithDefaultText(label).WithMaxHeight(15).WithFilter(false).WithOnInterruptFunc(interrupted).Show()
+}
+
+func (ptermUI) Input(label, defaultValue string) (string, error) {
+	defer awaitAnswer()()
+	return pterm.DefaultInteractiveTextInput.WithDefaultText(label).WithDefaultValue(defaultValue).WithOnInterruptFunc(interrupted).Show()
+}
+
+func (ptermUI) MultilineInput(label, defaultValue string) (string, error) {
+	defer awaitAnswer()()
+	return pterm.DefaultInteractiveTextInput.WithMultiLine().WithDefaultText(label).WithDefaultValue(defaultValue).WithOnInterruptFunc(interrupted).Show()
+}
+
+func (ptermUI) Confirm(label string, defaultValue bool) (bool, error) {
+	defer awaitAnswer()()
+	// the keys are the first letters of the answers
+	return pterm.DefaultInteractiveConfirm.WithDefaultText(label).WithConfirmText(tr("Yes")).WithRejectText(tr("No")).WithDefaultValue(defaultValue).WithOnInterruptFunc(interrupted).Show()
+}
+
+// plainUI reads plain lines from stdin without any cursor movement or
+// colors, for terminals pterm misrenders in and for screen readers
+type plainUI struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (p *plainUI) readLine() (string, error) {
+	defer awaitAnswer()()
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (p *plainUI) Select(label string, options []string, defaultOption string) (string, error) {
+	if defaultOption == "" && len(options) > 0 {
+		defaultOption = options[0]
+	}
+	for i, option := range options {
+		fmt.Fprintf(p.out, "%3d) %s\n", i+1, option)
+	}
+	for {
+		fmt.Fprintf(p.out, "%s [%s]: ", label, defaultOption)
+		answer, err := p.readLine()
+		if err != nil {
+			return defaultOption, err
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			return defaultOption, nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		if slices.Contains(options, answer) {
+			return answer, nil
+		}
+
+		// anything else filters the options fuzzily, like typing in pterm's
+		// select does
+		matches := fuzzy.RankFindFold(answer, options)
+		sort.Sort(matches)
+		if len(matches) == 1 {
+			return matches[0].Target, nil
+		}
+		if len(matches) == 0 {
+			fmt.Fprintln(p.out, tr("no option matches %q, enter a number between 1 and %d or part of an option", answer, len(options)))
+			continue
+		}
+		for _, match := range matches {
+			fmt.Fprintf(p.out, "%3d) %s\n", match.OriginalIndex+1, match.Target)
+		}
+	}
+}
+
+func (p *plainUI) MultiSelect(label string, options []string) ([]string, error) {
+	for i, option := range options {
+		fmt.Fprintf(p.out, "%3d) %s\n", i+1, option)
+	}
+	for {
+		fmt.Fprintf(p.out, "%s %s: ", label, tr("(numbers separated by commas, all or nothing)"))
+		answer, err := p.readLine()
+		if err != nil {
+			return nil, err
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "all" {
+			return options, nil
+		}
+
+		var selected []string
+		valid := true
+		for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+			n, err := strconv.Atoi(field)
+			if err != nil || n < 1 || n > len(options) {
+				valid = false
+				break
+			}
+			selected = append(selected, options[n-1])
+		}
+		if valid {
+			return removeDuplicateStr(selected), nil
+		}
+		fmt.Fprintln(p.out, tr("enter numbers between 1 and %d", len(options)))
+	}
+}
+
+func (p *plainUI) Input(label, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", label, defaultValue)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", label)
+	}
+	answer, err := p.readLine()
+	if answer == "" {
+		return defaultValue, err
+	}
+	return answer, err
+}
+
+func (p *plainUI) MultilineInput(label, defaultValue string) (string, error) {
+	fmt.Fprintf(p.out, "%s %s:\n", label, tr("(finish with a line containing only \".\")"))
+	if defaultValue != "" {
+		fmt.Fprintf(p.out, "[%s]\n%s\n", tr("an empty first line keeps:"), defaultValue)
+	}
+
+	var lines []string
+	for {
+		line, err := p.readLine()
+		if err != nil || line == "." {
+			break
+		}
+		if len(lines) == 0 && line == "" {
+			return defaultValue, nil
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (p *plainUI) Confirm(label string, defaultValue bool) (bool, error) {
+	// English answers are understood in every language
+	yes, no := strings.ToLower(tr("Yes")), strings.ToLower(tr("No"))
+	y, n := []rune(yes)[:1], []rune(no)[:1]
+	hint := string(y) + "/" + strings.ToUpper(string(n))
+	if defaultValue {
+		hint = strings.ToUpper(string(y)) + "/" + string(n)
+	}
+	for {
+		fmt.Fprintf(p.out, "%s [%s]: ", label, hint)
+		answer, err := p.readLine()
+		if err != nil {
+			return defaultValue, err
+		}
+		switch answer := strings.ToLower(strings.TrimSpace(answer)); {
+		case answer == "":
+			return defaultValue, nil
+		case answer == "y" || answer == "yes" || answer == yes || answer == string(y):
+			return true, nil
+		case answer == "n" || answer == "no" || answer == no || answer == string(n):
+			return false, nil
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/conventional"
+	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
+)
+
+// modes of the prompts, set by ui_mode
+const (
+	standardMode = "standard"
+	// guidedMode explains each prompt with examples and the rules it is
+	// validated with
+	guidedMode = "guided"
+	// compactMode asks terse single line prompts
+	compactMode = "compact"
+)
+
+// checkUIMode validates ui_mode
+func checkUIMode() error {
+	if mode := viper.GetString("ui_mode"); !slices.Contains([]string{standardMode, guidedMode, compactMode}, mode) {
+		return fmt.Errorf("unknown ui_mode %q, expected %s, %s or %s", mode, standardMode, guidedMode, compactMode)
+	}
+	return nil
+}
+
+func isUIMode(mode string) bool {
+	return viper.GetString("ui_mode") == mode
+}
+
+// compactLabels are the labels of the compact mode by the standard ones
+var compactLabels = map[string]string{
+	"Commit Type":                          "type",
+	"Scope":                                "scope",
+	"Scope (optional)":                     "scope",
+	"Long Description (optional)":          "body",
+	"Long Description":                     "body",
+	"Breaking Change":                      "breaking",
+	"Breaking Change Note":                 "breaking note",
+	"Add Footers (Refs, Reviewed-by, ...)": "footers",
+}
+
+// promptLabel returns the label of a commit prompt in the ui_mode
+func promptLabel(label string) string {
+	if compact, ok := compactLabels[label]; ok && isUIMode(compactMode) {
+		return tr(compact)
+	}
+	return tr(label)
+}
+
+// typeExplanations describe the default types and the common additions to
+// them in guided mode
+var typeExplanations = map[string]string{
+	"feat":     "a new feature, released as a minor version",
+	"fix":      "a bug fix, released as a patch version",
+	"build":    "the build system or dependencies",
+	"chore":    "maintenance not changing the code or tests",
+	"ci":       "the CI configuration and scripts",
+	"docs":     "documentation only",
+	"refactor": "a code change neither fixing a bug nor adding a feature",
+	"test":     "adding or correcting tests",
+	"style":    "formatting, whitespace, no change of meaning",
+	"perf":     "a performance improvement",
+	"revert":   "reverting an earlier commit",
+}
+
+// guideField explains the prompts of field in guided mode, with the rules
+// the answer is validated with
+func guideField(field promptField, commitTypes []string) {
+	if !isUIMode(guidedMode) {
+		return
+	}
+	rules := commitRules()
+	var lines []string
+	switch field {
+	case typeField:
+		lines = append(lines, tr("The type tells what kind of change this is, changelogs and version bumps are derived from it:"))
+		for _, t := range commitTypes {
+			if explanation, ok := typeExplanations[t]; ok {
+				lines = append(lines, fmt.Sprintf("  %-9s %s", t, tr(explanation)))
+			}
+		}
+		lines = append(lines, tr("Example: feat(api): add login endpoint"))
+	case scopeField:
+		lines = append(lines, tr("The scope names the part of the project the change is in, such as a package or component."))
+		if len(rules.Scopes) > 0 && !slices.Contains(rules.Scopes, "none") {
+			lines = append(lines, tr("This repository requires one of its scopes."))
+		} else {
+			lines = append(lines, tr("Leave it out for changes across the project."))
+		}
+	case shortDescriptionField:
+		lines = append(lines, tr(`Summarize the change as a command, e.g. "add login endpoint" rather than "added login endpoint".`))
+		if rules.MaxHeaderLength > 0 {
+			lines = append(lines, tr("The header, type and scope included, may have at most %d characters.", rules.MaxHeaderLength))
+		}
+		switch rules.SubjectCase {
+		case conventional.LowerCase:
+			lines = append(lines, tr("Start with a lower case letter."))
+		case conventional.SentenceCase:
+			lines = append(lines, tr("Start with an upper case letter."))
+		}
+		if rules.NoTrailingPeriod {
+			lines = append(lines, tr("Don't end it with a period."))
+		}
+	case longDescriptionField:
+		lines = append(lines, tr("Optionally explain what changed and why, the diff already shows how."))
+		if rules.MaxBodyLineLength > 0 {
+			lines = append(lines, tr("Lines are wrapped at %d characters.", rules.MaxBodyLineLength))
+		}
+	case breakingChangeField:
+		lines = append(lines, tr("A breaking change makes users change their code or configuration and is released as a major version."),
+			tr("The note tells them what to do."))
+	case footersField:
+		lines = append(lines, tr("Footers reference tickets (Refs: PROJ-123), credit co-authors (Co-authored-by: Name <email>) or record reviews (Reviewed-by: ...)."))
+	case customField:
+		if len(customPrompts()) == 0 {
+			return
+		}
+		lines = append(lines, tr("This repository asks a few more questions, the answers are added as footers."))
+	}
+	pterm.Description.Println(strings.Join(lines, "\n"))
+}
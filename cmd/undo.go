@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Undo the last commit, keeping its changes staged",
+	Long: `Undo the last commit with a soft reset: its changes stay staged and its
+message is saved as the draft of the branch, so the next git cc offers to
+resume it and the commit can be made again after fixing a mistake.
+
+A commit which has already been pushed to the upstream of the branch is
+not undone, unless --force is given.`,
+	Example: `  git cc undo
+  git cc undo --force`,
+	Args: cobra.NoArgs,
+	Run:  undo,
+}
+
+func init() {
+	undoCmd.Flags().Bool("force", false, "Undo the commit even if it has already been pushed")
+	rootCmd.AddCommand(undoCmd)
+}
+
+func undo(cmd *cobra.Command, args []string) {
+	head, err := repo.Head()
+	if err != nil {
+		pterm.Error.Println("Nothing to undo:", err)
+		exit(exitcode.Failure)
+	}
+	last, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		pterm.Error.Println("Failed to read commit:", err)
+		exit(exitcode.Failure)
+	}
+	switch last.NumParents() {
+	case 0:
+		pterm.Error.Println("The first commit of the repository can't be undone")
+		exit(exitcode.Failure)
+	case 1:
+	default:
+		pterm.Error.Println("The last commit is a merge, undo it with git reset --merge HEAD^")
+		exit(exitcode.Failure)
+	}
+
+	if force, _ := cmd.Flags().GetBool("force"); !force {
+		if upstream, ok := pushedTo(head, last); ok {
+			pterm.Error.Printfln("%s has already been pushed to %s, undoing it would rewrite published history (use --force to do it anyway)", head.Hash().String()[:7], upstream)
+			exit(exitcode.Failure)
+		}
+	}
+
+	data, err := parseCommitMessage(last.Message)
+	if err != nil {
+		// keep the message of a non conventional commit as description
+		header, rest, _ := strings.Cut(strings.TrimSpace(last.Message), "\n")
+		data = CommitPromptData{ShortDescription: header, LongDescription: strings.TrimSpace(rest)}
+	}
+
+	reset := exec.Command("git", "reset", "--soft", "--quiet", "HEAD^")
+	reset.Dir = gitRoot
+	reset.Stdout = os.Stdout
+	reset.Stderr = os.Stderr
+	if err := reset.Run(); err != nil {
+		pterm.Error.Println("git reset failed:", err)
+		exit(exitcode.Failure)
+	}
+
+	header, _, _ := strings.Cut(strings.TrimSpace(last.Message), "\n")
+	pterm.Success.Printfln("Undid %s, its changes are staged", header)
+	if !draftsEnabled() {
+		pterm.Info.Println("Drafts are disabled, the message isn't kept")
+		return
+	}
+	if err := writeDraft(draftPath(), data); err != nil {
+		pterm.Warning.Println("Failed to keep the message as draft:", err)
+		return
+	}
+	pterm.Info.Println("Run git cc to resume its message")
+}
+
+// pushedTo returns the upstream of the checked out branch when it contains
+// the commit at head
+func pushedTo(head *plumbing.Reference, commit *object.Commit) (string, bool) {
+	if !head.Name().IsBranch() {
+		return "", false
+	}
+	config, err := repo.Config()
+	if err != nil {
+		return "", false
+	}
+	branch, ok := config.Branches[head.Name().Short()]
+	// a remote of "." tracks a local branch
+	if !ok || branch.Remote == "" || branch.Remote == "." || branch.Merge == "" {
+		return "", false
+	}
+	name := plumbing.NewRemoteReferenceName(branch.Remote, branch.Merge.Short())
+	ref, err := repo.Reference(name, true)
+	if err != nil {
+		return "", false
+	}
+	upstream, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return "", false
+	}
+	if upstream.Hash == commit.Hash {
+		return name.Short(), true
+	}
+	if contained, err := commit.IsAncestor(upstream); err == nil && contained {
+		return name.Short(), true
+	}
+	return "", false
+}
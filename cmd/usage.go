@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// usageRecord is a commit made through the prompts, kept for git cc stats
+// --me only and never sent anywhere
+type usageRecord struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Seconds float64   `json:"seconds"`
+}
+
+// usageStats is usage_stats of the global config, repositories can't turn
+// recording on
+var usageStats bool
+
+// usagePath is the file the usage_stats are appended to, next to the global
+// config
+func usagePath() string {
+	dir := globalConfigDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "usage.jsonl")
+}
+
+// recordUsage appends the commit of message, composed in took, to the
+// local usage stats
+func recordUsage(message string, took time.Duration) {
+	path := usagePath()
+	if !usageStats || path == "" {
+		return
+	}
+	record := usageRecord{Time: time.Now(), Type: "other", Seconds: took.Round(time.Second).Seconds()}
+	header, _, _ := strings.Cut(message, "\n")
+	if match := headerPattern.FindStringSubmatch(header); match != nil {
+		record.Type = match[1]
+	}
+
+	line, err := json.Marshal(record)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0o700)
+	}
+	var f *os.File
+	if err == nil {
+		f, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	}
+	if err == nil {
+		_, err = f.Write(append(line, '\n'))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		pterm.Debug.Println("Failed to record usage:", err)
+	}
+}
+
+// readUsage returns the recorded commits, skipping unreadable lines
+func readUsage() ([]usageRecord, error) {
+	path := usagePath()
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []usageRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record usageRecord
+		if json.Unmarshal(scanner.Bytes(), &record) == nil {
+			records = append(records, record)
+		}
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Prompt for a commit whenever changes in the working tree have settled",
+	Long: `Monitor the working tree and prompt for a commit once changes have settled for
+the debounce period. Changes are staged automatically when nothing has been
+staged by hand.`,
+	Args: cobra.NoArgs,
+	Run:  watch,
+}
+
+func init() {
+	watchCmd.Flags().Duration("debounce", 0, "Time the working tree must be unchanged before prompting (default watch_debounce or 3s)")
+	watchCmd.Flags().Duration("interval", 500*time.Millisecond, "How often the working tree is checked for changes")
+	rootCmd.AddCommand(watchCmd)
+}
+
+// watch monitors the working tree and once changes have settled for the
+// debounce period pops the staging and commit prompts.
+func watch(cmd *cobra.Command, args []string) {
+	debounce := viper.GetDuration("watch_debounce")
+	if cmd.Flags().Changed("debounce") {
+		debounce, _ = cmd.Flags().GetDuration("debounce")
+	}
+	interval, _ := cmd.Flags().GetDuration("interval")
+
+	pterm.Info.Printfln("Watching %s for changes (press Ctrl+C to stop)", gitRoot)
+
+	var last string
+	var changedAt time.Time
+	pending := false
+	for {
+		snapshot, clean, err := worktreeSnapshot()
+		if err != nil {
+			pterm.Error.Println("Failed to get status:", err)
+			exit(exitcode.Failure)
+		}
+
+		if snapshot != last {
+			// something changed, restart the debounce timer
+			last = snapshot
+			changedAt = time.Now()
+			pending = !clean
+		} else if pending && time.Since(changedAt) >= debounce {
+			pending = false
+			watchCommit()
+			pterm.Info.Println("Watching for changes")
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// watchCommit asks to commit the settled changes, staging everything when
+// nothing has been staged by hand, and then runs the commit prompts.
+func watchCommit() {
+	confirm, _ := ui.Confirm("Changes settled, commit now", true)
+	if !confirm {
+		return
+	}
+
+	hasStagedChanges, _, err := stagedChanges()
+	if err != nil {
+		pterm.Error.Println("Failed to get status:", err)
+		return
+	}
+
+	if !hasStagedChanges {
+		cmd := exec.Command("git", "add", "--all")
+		cmd.Dir = gitRoot
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			pterm.Error.Println("Failed to stage changes:", err)
+			return
+		}
+	}
+
+	if err := runCommit(); err != nil {
+		pterm.Error.Println(err)
+	}
+}
+
+// worktreeSnapshot returns a fingerprint of the changed files in the working
+// tree. File size and modification time are included so that further edits
+// to an already modified file are noticed too.
+func worktreeSnapshot() (string, bool, error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return "", false, err
+	}
+
+	paths := make([]string, 0, len(status))
+	for path := range status {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var snapshot strings.Builder
+	for _, path := range paths {
+		entry := status[path]
+		fmt.Fprintf(&snapshot, "%c%c %s", entry.Staging, entry.Worktree, path)
+		if info, err := os.Stat(filepath.Join(gitRoot, path)); err == nil {
+			fmt.Fprintf(&snapshot, " %d %d", info.Size(), info.ModTime().UnixNano())
+		}
+		snapshot.WriteByte('\n')
+	}
+
+	return snapshot.String(), status.IsClean(), nil
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/45413/git-cc/pkg/exitcode"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+var wipCmd = &cobra.Command{
+	Use:   "wip",
+	Short: "Stage everything and commit it instantly as a wip: commit",
+	Args:  cobra.NoArgs,
+	Run:   wip,
+}
+
+var unwipCmd = &cobra.Command{
+	Use:   "unwip",
+	Short: "Squash the WIP commits at the tip of the branch back into staged changes",
+	Args:  cobra.NoArgs,
+	Run:   unwip,
+}
+
+func init() {
+	rootCmd.AddCommand(wipCmd)
+	rootCmd.AddCommand(unwipCmd)
+}
+
+// wip stages everything and commits it straight away with an automatic
+// `wip:` message, no prompts involved.
+func wip(cmd *cobra.Command, args []string) {
+	add := exec.Command("git", "add", "--all")
+	add.Dir = gitRoot
+	add.Stderr = os.Stderr
+	if err := add.Run(); err != nil {
+		pterm.Error.Println("Failed to stage changes:", err)
+		exit(exitcode.Failure)
+	}
+
+	summary, err := wipSummary()
+	if err != nil {
+		pterm.Error.Println("Failed to get status:", err)
+		exit(exitcode.Failure)
+	}
+	if summary == "" {
+		pterm.Error.Println("nothing to commit, working tree clean")
+		exit(exitcode.NothingStaged)
+	}
+
+	// wip isn't a commit type, the commit-msg hook running git cc lint would
+	// reject the message and the pre-commit checks are for finished work
+	commit := exec.Command("git", append([]string{"commit", "--no-verify", "-m", "wip: " + summary}, signingArgs()...)...)
+	commit.Stdout = os.Stdout
+	commit.Stderr = os.Stderr
+	passTerminal(commit)
+	if err := commit.Run(); err != nil {
+		commitFailed(err)
+	}
+}
+
+// wipSummary describes the staged changes in a few words, e.g.
+// "update main.go, wip.go and 3 more files".
+func wipSummary() (string, error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return "", err
+	}
+
+	var paths []string
+	verbs := map[string]bool{}
+	for path, entry := range status {
+		switch entry.Staging {
+		case git.Unmodified, git.Untracked:
+			continue
+		case git.Added:
+			verbs["add"] = true
+		case git.Deleted:
+			verbs["remove"] = true
+		default:
+			verbs["update"] = true
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return "", nil
+	}
+	// generated files are only named when nothing else changed
+	sort.Strings(paths)
+	generated := generatedFiles(paths)
+	sort.SliceStable(paths, func(i, j int) bool { return !generated[paths[i]] && generated[paths[j]] })
+
+	verb := "update"
+	if len(verbs) == 1 {
+		for v := range verbs {
+			verb = v
+		}
+	}
+
+	names := make([]string, 0, 2)
+	for _, path := range paths {
+		if len(names) == 2 {
+			break
+		}
+		names = append(names, filepath.Base(path))
+	}
+
+	summary := verb + " " + strings.Join(names, ", ")
+	if rest := len(paths) - len(names); rest > 0 {
+		summary += fmt.Sprintf(" and %d more file", rest)
+		if rest > 1 {
+			summary += "s"
+		}
+	}
+	return summary, nil
+}
+
+// unwip squashes the consecutive WIP commits at the tip of the branch back
+// into staged changes, ready to be committed with a proper message.
+func unwip(cmd *cobra.Command, args []string) {
+	head, err := repo.Head()
+	if err != nil {
+		pterm.Error.Println("Failed to resolve HEAD:", err)
+		exit(exitcode.Failure)
+	}
+
+	c, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		pterm.Error.Println("Failed to read HEAD commit:", err)
+		exit(exitcode.Failure)
+	}
+
+	// walk back until the first commit which is not a WIP commit
+	count := 0
+	var target plumbing.Hash
+	for isWipCommit(c) {
+		count++
+		if c.NumParents() == 0 {
+			pterm.Error.Println("all commits down to the root commit are WIP commits, nothing to reset to")
+			exit(exitcode.Failure)
+		}
+		if c, err = c.Parent(0); err != nil {
+			pterm.Error.Println("Failed to read parent commit:", err)
+			exit(exitcode.Failure)
+		}
+		target = c.Hash
+	}
+
+	if count == 0 {
+		pterm.Error.Println("HEAD is not a WIP commit")
+		exit(exitcode.Failure)
+	}
+
+	if err := worktree.Reset(&git.ResetOptions{Commit: target, Mode: git.SoftReset}); err != nil {
+		pterm.Error.Println("Failed to reset:", err)
+		exit(exitcode.Failure)
+	}
+
+	pterm.Success.Printfln("Squashed %d WIP commit(s) back into staged changes, run git cc to commit them", count)
+}
+
+func isWipCommit(c *object.Commit) bool {
+	subject := strings.ToLower(strings.SplitN(c.Message, "\n", 2)[0])
+	return strings.HasPrefix(subject, "wip:") || strings.HasPrefix(subject, "wip(") || strings.HasPrefix(subject, "wip!")
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// discoveredScopes returns the scopes scope_discovery derives from the
+// repository, named after the directories of its packages and sorted
+func discoveredScopes() []string {
+	var dirs []string
+	switch mode := viper.GetString("scope_discovery"); mode {
+	case "", "off":
+		return nil
+	case "workspaces":
+		dirs = slices.Concat(goWorkModules(), npmWorkspaces(), cargoMembers())
+	case "glob":
+		dirs = globDirs(viper.GetStringSlice("scope_discovery_glob"), nil)
+	default:
+		pterm.Warning.Printfln("Ignoring scope_discovery %q, use off, workspaces or glob", mode)
+		return nil
+	}
+
+	var found []string
+	for _, dir := range dirs {
+		name := filepath.Base(dir)
+		if dir == "." || name == "." {
+			continue
+		}
+		if !validName.MatchString(name) {
+			pterm.Debug.Printfln("Not using %s as scope, use letters, digits, _ and -", dir)
+			continue
+		}
+		found = append(found, name)
+	}
+	slices.Sort(found)
+	return slices.Compact(found)
+}
+
+// goWorkModules returns the directories of the use directives of go.work
+func goWorkModules() []string {
+	file, err := os.Open(filepath.Join(gitRoot, "go.work"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var dirs []string
+	inBlock := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			dirs = append(dirs, strings.Trim(fields[0], `"`))
+		case fields[0] == "use" && len(fields) > 1 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "use" && len(fields) > 1:
+			dirs = append(dirs, strings.Trim(fields[1], `"`))
+		}
+	}
+	return dirs
+}
+
+// npmWorkspaces returns the package directories of the workspaces of
+// package.json or pnpm-workspace.yaml
+func npmWorkspaces() []string {
+	var patterns []string
+	if content, err := os.ReadFile(filepath.Join(gitRoot, "package.json")); err == nil {
+		var manifest struct {
+			Workspaces any `json:"workspaces"`
+		}
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			pterm.Debug.Println("Ignoring package.json:", err)
+		}
+		// a list or yarn's {"packages": [...]}
+		switch workspaces := manifest.Workspaces.(type) {
+		case []any:
+			patterns = cast.ToStringSlice(workspaces)
+		case map[string]any:
+			patterns = cast.ToStringSlice(workspaces["packages"])
+		}
+	}
+	if path := filepath.Join(gitRoot, "pnpm-workspace.yaml"); isFile(path) {
+		v := viper.New()
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			pterm.Debug.Println("Ignoring pnpm-workspace.yaml:", err)
+		}
+		patterns = append(patterns, v.GetStringSlice("packages")...)
+	}
+
+	var include, exclude []string
+	for _, pattern := range patterns {
+		if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+			exclude = append(exclude, negated)
+		} else {
+			include = append(include, pattern)
+		}
+	}
+	return slices.DeleteFunc(globDirs(include, exclude), func(dir string) bool {
+		return !isFile(filepath.Join(gitRoot, dir, "package.json"))
+	})
+}
+
+// cargoMembers returns the member directories of the Cargo workspace
+func cargoMembers() []string {
+	path := filepath.Join(gitRoot, "Cargo.toml")
+	if !isFile(path) {
+		return nil
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		pterm.Debug.Println("Ignoring Cargo.toml:", err)
+		return nil
+	}
+	return globDirs(v.GetStringSlice("workspace.members"), v.GetStringSlice("workspace.exclude"))
+}
+
+// globDirs returns the directories below the repository root matching one
+// of the patterns but none of exclude, relative to the root
+func globDirs(patterns, exclude []string) []string {
+	var dirs []string
+	for _, pattern := range patterns {
+		// workspaces often end in /** for nested packages, one level is enough
+		pattern = strings.TrimPrefix(pattern, "./")
+		if parent, ok := strings.CutSuffix(pattern, "/**"); ok {
+			pattern = parent + "/*"
+		}
+		matches, err := filepath.Glob(filepath.Join(gitRoot, filepath.FromSlash(pattern)))
+		if err != nil {
+			pterm.Warning.Printfln("Ignoring the invalid pattern %q: %s", pattern, err)
+			continue
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || !info.IsDir() {
+				continue
+			}
+			dir, _ := filepath.Rel(gitRoot, match)
+			dir = filepath.ToSlash(dir)
+			if !slices.ContainsFunc(exclude, func(e string) bool {
+				matched, _ := filepath.Match(strings.TrimPrefix(e, "./"), dir)
+				return matched
+			}) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return dirs
+}
+
+// isFile reports whether path exists and isn't a directory
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
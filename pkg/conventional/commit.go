@@ -0,0 +1,155 @@
+package conventional
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Commit is a conventional commit message split into its parts
+type Commit struct {
+	Type  string
+	Scope string
+	// Breaking is set by a ! in the header or a BREAKING CHANGE footer
+	Breaking    bool
+	Description string
+	Body        string
+	// BreakingNote is the value of the first BREAKING CHANGE footer
+	BreakingNote string
+	// BreakingNotes are the values of all BREAKING CHANGE footers, as a
+	// commit may break several things. String renders BreakingNote first
+	// unless it is one of them.
+	BreakingNotes []string
+	// Footers are the other footers, such as Refs or Reviewed-by
+	Footers []Footer
+}
+
+// Footer is a git trailer, which Conventional Commits calls a footer
+type Footer struct {
+	Token string `json:"token"`
+	// Separator is ": " or " #", ": " when empty
+	Separator string `json:"separator,omitempty"`
+	Value     string `json:"value"`
+}
+
+// String renders the footer, the lines of a multi-line value are indented
+// as continuation lines. Blank lines are left out since they would end the
+// footers.
+func (f Footer) String() string {
+	separator := f.Separator
+	if separator == "" {
+		separator = ": "
+	}
+	first, rest, _ := strings.Cut(strings.TrimSpace(f.Value), "\n")
+	footer := f.Token + separator + first
+	for _, line := range strings.Split(rest, "\n") {
+		if strings.TrimSpace(line) != "" {
+			footer += "\n " + line
+		}
+	}
+	return footer
+}
+
+// Parse splits a conventional commit message into its parts. An emoji in
+// front of the type is dropped, comment lines must have been removed
+// beforehand.
+func Parse(message string) (Commit, error) {
+	var c Commit
+
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	header, rest, _ := strings.Cut(message, "\n")
+
+	match := HeaderPattern.FindStringSubmatch(header)
+	if match == nil {
+		return c, fmt.Errorf("header %q is not of the form type(scope): description", header)
+	}
+	c.Type = match[1]
+	c.Scope = match[2]
+	c.Breaking = match[3] == "!"
+	c.Description = match[4]
+
+	body, footers := SplitFooters(rest)
+	for _, f := range footers {
+		if f.Token == "BREAKING CHANGE" || f.Token == "BREAKING-CHANGE" {
+			c.Breaking = true
+			c.BreakingNotes = append(c.BreakingNotes, f.Value)
+			if c.BreakingNote == "" {
+				c.BreakingNote = f.Value
+			}
+		} else {
+			c.Footers = append(c.Footers, f)
+		}
+	}
+	c.Body = body
+
+	return c, nil
+}
+
+// String renders the commit as message, the breaking change notes as first
+// footers
+func (c Commit) String() string {
+	var message strings.Builder
+
+	message.WriteString(c.Type)
+	if c.Scope != "" {
+		message.WriteString("(" + c.Scope + ")")
+	}
+	if c.Breaking {
+		message.WriteString("!")
+	}
+	message.WriteString(": " + c.Description)
+
+	notes := c.BreakingNotes
+	if c.BreakingNote != "" && !slices.Contains(notes, c.BreakingNote) {
+		notes = append([]string{c.BreakingNote}, notes...)
+	}
+	var footers []Footer
+	for _, note := range notes {
+		footers = append(footers, Footer{Token: "BREAKING CHANGE", Separator: ": ", Value: note})
+	}
+	footers = append(footers, c.Footers...)
+
+	if body := JoinFooters(strings.TrimSpace(c.Body), footers); body != "" {
+		message.WriteString("\n\n" + body)
+	}
+	return message.String()
+}
+
+// SplitFooters splits the footer block off the end of a message body. The
+// last paragraph is the footer block if it starts with a footer, following
+// lines not starting with a token continue the previous footer's value
+// without the space indenting them.
+func SplitFooters(body string) (string, []Footer) {
+	body = strings.TrimSpace(strings.ReplaceAll(body, "\r\n", "\n"))
+
+	paragraphs := strings.Split(body, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	if first, _, _ := strings.Cut(last, "\n"); !FooterPattern.MatchString(first) {
+		return body, nil
+	}
+
+	var footers []Footer
+	for _, line := range strings.Split(last, "\n") {
+		if match := FooterPattern.FindStringSubmatch(line); match != nil {
+			footers = append(footers, Footer{Token: match[1], Separator: match[2], Value: match[3]})
+		} else {
+			footers[len(footers)-1].Value += "\n" + strings.TrimPrefix(line, " ")
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(paragraphs[:len(paragraphs)-1], "\n\n")), footers
+}
+
+// JoinFooters appends the footers to body as the footer block
+func JoinFooters(body string, footers []Footer) string {
+	lines := make([]string, 0, len(footers))
+	for _, f := range footers {
+		lines = append(lines, f.String())
+	}
+
+	block := strings.Join(lines, "\n")
+	if body == "" || block == "" {
+		return body + block
+	}
+	return body + "\n\n" + block
+}
@@ -0,0 +1,225 @@
+package conventional_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/45413/git-cc/pkg/conventional"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    conventional.Commit
+	}{
+		{
+			name:    "header only",
+			message: "feat: add login",
+			want:    conventional.Commit{Type: "feat", Description: "add login"},
+		},
+		{
+			name:    "scope",
+			message: "fix(api): handle timeouts",
+			want:    conventional.Commit{Type: "fix", Scope: "api", Description: "handle timeouts"},
+		},
+		{
+			name:    "body without footers",
+			message: "docs: explain setup\n\nFirst paragraph.\n\nSecond paragraph\nover two lines.",
+			want:    conventional.Commit{Type: "docs", Description: "explain setup", Body: "First paragraph.\n\nSecond paragraph\nover two lines."},
+		},
+		{
+			name:    "bang and BREAKING CHANGE footer",
+			message: "feat(api)!: drop v1\n\nBREAKING CHANGE: v1 is gone",
+			want: conventional.Commit{
+				Type: "feat", Scope: "api", Breaking: true, Description: "drop v1",
+				BreakingNote: "v1 is gone", BreakingNotes: []string{"v1 is gone"},
+			},
+		},
+		{
+			name:    "BREAKING-CHANGE footer without bang",
+			message: "refactor: rename config\n\nBREAKING-CHANGE: rename the keys",
+			want: conventional.Commit{
+				Type: "refactor", Breaking: true, Description: "rename config",
+				BreakingNote: "rename the keys", BreakingNotes: []string{"rename the keys"},
+			},
+		},
+		{
+			name:    "several breaking notes and other footers",
+			message: "feat!: new storage\n\nBody.\n\nBREAKING CHANGE: drop sqlite\nBREAKING-CHANGE: drop mysql\nRefs: PROJ-1",
+			want: conventional.Commit{
+				Type: "feat", Breaking: true, Description: "new storage", Body: "Body.",
+				BreakingNote: "drop sqlite", BreakingNotes: []string{"drop sqlite", "drop mysql"},
+				Footers: []conventional.Footer{{Token: "Refs", Separator: ": ", Value: "PROJ-1"}},
+			},
+		},
+		{
+			name:    "multi-line footer value",
+			message: "fix: retry\n\nBREAKING CHANGE: the retry count\n  moved to the config\nReviewed-by: Jane",
+			want: conventional.Commit{
+				Type: "fix", Breaking: true, Description: "retry",
+				BreakingNote: "the retry count\n moved to the config", BreakingNotes: []string{"the retry count\n moved to the config"},
+				Footers: []conventional.Footer{{Token: "Reviewed-by", Separator: ": ", Value: "Jane"}},
+			},
+		},
+		{
+			name:    "hash separator",
+			message: "fix: close the socket\n\nCloses #123",
+			want: conventional.Commit{
+				Type: "fix", Description: "close the socket",
+				Footers: []conventional.Footer{{Token: "Closes", Separator: " #", Value: "123"}},
+			},
+		},
+		{
+			name:    "emoji before the type",
+			message: ":sparkles: feat: add search",
+			want:    conventional.Commit{Type: "feat", Description: "add search"},
+		},
+		{
+			name:    "CRLF line endings",
+			message: "chore: bump\r\n\r\nRefs: PROJ-2\r\n",
+			want: conventional.Commit{
+				Type: "chore", Description: "bump",
+				Footers: []conventional.Footer{{Token: "Refs", Separator: ": ", Value: "PROJ-2"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := conventional.Parse(tt.message)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.message, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %#v, want %#v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsMalformedHeaders(t *testing.T) {
+	for _, message := range []string{
+		"",
+		"add login",
+		"feat add login",
+		"feat:add login",
+		"feat(api: add login",
+		"feat(api)): add login",
+		"(api): add login",
+		"feat() : add login",
+	} {
+		if c, err := conventional.Parse(message); err == nil {
+			t.Errorf("Parse(%q) = %#v, want an error", message, c)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, message := range []string{
+		"feat: add login",
+		"fix(api): handle timeouts",
+		"feat(api)!: drop v1",
+		"docs: explain setup\n\nFirst paragraph.\n\nSecond paragraph.",
+		"feat!: new storage\n\nBody.\n\nBREAKING CHANGE: drop sqlite\nBREAKING CHANGE: drop mysql\nRefs: PROJ-1",
+		"fix!: retry\n\nBREAKING CHANGE: the retry count\n moved to the config\nReviewed-by: Jane",
+		"fix: close the socket\n\nCloses #123\nCo-authored-by: Jane <jane@example.com>",
+	} {
+		c, err := conventional.Parse(message)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", message, err)
+		}
+		if got := c.String(); got != message {
+			t.Errorf("Parse(%q).String() = %q", message, got)
+		}
+		again, err := conventional.Parse(c.String())
+		if err != nil || !reflect.DeepEqual(again, c) {
+			t.Errorf("parsing %q again = %#v, %v, want %#v", c.String(), again, err, c)
+		}
+	}
+}
+
+func TestStringNormalizes(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		// a breaking change footer implies the bang
+		{"feat: drop v1\n\nBREAKING CHANGE: v1 is gone", "feat!: drop v1\n\nBREAKING CHANGE: v1 is gone"},
+		{"feat(api): drop v1\n\nBREAKING-CHANGE: v1 is gone", "feat(api)!: drop v1\n\nBREAKING CHANGE: v1 is gone"},
+		// breaking notes come first
+		{"feat!: x\n\nRefs: PROJ-1\nBREAKING CHANGE: y", "feat!: x\n\nBREAKING CHANGE: y\nRefs: PROJ-1"},
+		{"feat: x\n\n\nBody.\n\n", "feat: x\n\nBody."},
+	}
+	for _, tt := range tests {
+		c, err := conventional.Parse(tt.message)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.message, err)
+		}
+		if got := c.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestStringBreakingNoteFirst(t *testing.T) {
+	c := conventional.Commit{
+		Type: "feat", Breaking: true, Description: "new storage",
+		BreakingNote: "drop sqlite", BreakingNotes: []string{"drop mysql"},
+	}
+	want := "feat!: new storage\n\nBREAKING CHANGE: drop sqlite\nBREAKING CHANGE: drop mysql"
+	if got := c.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestFooterString(t *testing.T) {
+	tests := []struct {
+		footer conventional.Footer
+		want   string
+	}{
+		{conventional.Footer{Token: "Refs", Value: "PROJ-1"}, "Refs: PROJ-1"},
+		{conventional.Footer{Token: "Closes", Separator: " #", Value: "12"}, "Closes #12"},
+		{conventional.Footer{Token: "Note", Separator: ": ", Value: "first\n\nsecond\nthird"}, "Note: first\n second\n third"},
+	}
+	for _, tt := range tests {
+		if got := tt.footer.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.footer, got, tt.want)
+		}
+	}
+}
+
+func TestSplitFooters(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantBody string
+		want     []conventional.Footer
+	}{
+		{name: "empty", body: "", wantBody: ""},
+		{name: "no footers", body: "Explain why.\n\nAnd how.", wantBody: "Explain why.\n\nAnd how."},
+		{
+			name: "only footers", body: "Refs: PROJ-1\nCloses #2",
+			want: []conventional.Footer{{Token: "Refs", Separator: ": ", Value: "PROJ-1"}, {Token: "Closes", Separator: " #", Value: "2"}},
+		},
+		{
+			name: "continuation lines", body: "Why.\n\nNote: spans\n two lines",
+			wantBody: "Why.",
+			want:     []conventional.Footer{{Token: "Note", Separator: ": ", Value: "spans\ntwo lines"}},
+		},
+		{
+			name: "footer-like line inside the body", body: "Refs: PROJ-1 is mentioned here\n\nbut the last paragraph is prose",
+			wantBody: "Refs: PROJ-1 is mentioned here\n\nbut the last paragraph is prose",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, footers := conventional.SplitFooters(tt.body)
+			if body != tt.wantBody || !reflect.DeepEqual(footers, tt.want) {
+				t.Errorf("SplitFooters(%q) = %q, %#v, want %q, %#v", tt.body, body, footers, tt.wantBody, tt.want)
+			}
+			if joined := conventional.JoinFooters(body, footers); joined != tt.body {
+				t.Errorf("JoinFooters(SplitFooters(%q)) = %q", tt.body, joined)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package conventional
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Condition is a rule for the messages of some types or scopes only, such
+// as a body for every feat or a Refs footer for the billing scope
+type Condition struct {
+	// Types and Scopes select the messages the condition applies to, "none"
+	// selecting those without a scope. A message has to match both, an
+	// empty list matches any.
+	Types  []string
+	Scopes []string
+	// Forbid rejects the selected messages
+	Forbid bool
+	// RequireBody requires the selected messages to have a body
+	RequireBody bool
+	// RequireFooters lists footer tokens the selected messages must have,
+	// compared case-insensitively
+	RequireFooters []string
+	// Reason is added to the problems found, e.g. "on branch main"
+	Reason string
+}
+
+// Applies reports whether the condition selects the messages of commitType
+// and scope, empty for none
+func (c Condition) Applies(commitType, scope string) bool {
+	if scope == "" {
+		scope = "none"
+	}
+	return (len(c.Types) == 0 || slices.Contains(c.Types, commitType)) &&
+		(len(c.Scopes) == 0 || slices.Contains(c.Scopes, scope))
+}
+
+// conditionProblems checks lines, whose header has the type and scope at
+// the given columns, against the conditions which apply to it. The problems
+// point at the type or scope the condition selected the message by.
+func conditionProblems(lines []string, typeStart, typeEnd, scopeStart, scopeEnd int, conditions []Condition) []Problem {
+	header := lines[0]
+	commitType, scope := header[typeStart:typeEnd], ""
+	if scopeStart >= 0 {
+		scope = header[scopeStart:scopeEnd]
+	}
+
+	body := ""
+	var tokens []string
+	if len(lines) > 2 {
+		end := footerStart(lines)
+		body = strings.TrimSpace(strings.Join(lines[2:end], "\n"))
+		for _, line := range lines[end:] {
+			if match := FooterPattern.FindStringSubmatch(line); match != nil {
+				tokens = append(tokens, strings.ToLower(match[1]))
+			}
+		}
+	}
+
+	var problems []Problem
+	for _, c := range conditions {
+		if !c.Applies(commitType, scope) {
+			continue
+		}
+		reason := ""
+		if c.Reason != "" {
+			reason = " " + c.Reason
+		}
+		// point at what selected the message
+		at := Problem{Column: typeStart, EndColumn: typeEnd, Rule: "type-enum"}
+		selection := fmt.Sprintf("type %q", commitType)
+		switch {
+		case len(c.Scopes) > 0 && len(c.Types) > 0:
+			selection = fmt.Sprintf("type %q with scope %q", commitType, scope)
+		case len(c.Scopes) > 0:
+			at = Problem{Column: typeEnd, EndColumn: typeEnd, Rule: "scope-enum"}
+			if scopeStart >= 0 {
+				at.Column, at.EndColumn = scopeStart, scopeEnd
+			}
+			selection = fmt.Sprintf("scope %q", scope)
+		}
+
+		if c.Forbid {
+			problem := at
+			problem.Message = fmt.Sprintf("%s is not allowed%s", selection, reason)
+			problems = append(problems, problem)
+		}
+		if c.RequireBody && body == "" {
+			problem := at
+			problem.Message = fmt.Sprintf("a body is required for %s%s", selection, reason)
+			problem.Rule = "body-empty"
+			problems = append(problems, problem)
+		}
+		for _, token := range c.RequireFooters {
+			if slices.Contains(tokens, strings.ToLower(token)) {
+				continue
+			}
+			problem := at
+			problem.Message = fmt.Sprintf("footer %q is required for %s%s", token, selection, reason)
+			problem.Rule = "trailer-exists"
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,25 @@
+package conventional
+
+// remediations say how to fix the problems of each rule
+var remediations = map[string]string{
+	"message-empty":        "write a message starting with a header such as feat: add login",
+	"header-format":        "start the header with a type, an optional scope in parentheses and a colon, e.g. feat(api): add login",
+	"header-max-length":    "shorten the description, details belong in the body",
+	"type-enum":            "use one of the allowed types",
+	"scope-empty":          "add a scope in parentheses after the type, e.g. feat(api): add login",
+	"scope-enum":           "use one of the allowed scopes",
+	"subject-empty":        "describe the change after the colon",
+	"subject-case":         "change the case of the first letter of the description",
+	"subject-full-stop":    "remove the period at the end of the description",
+	"subject-imperative":   "start the description with a verb in the imperative mood, as in \"add\" rather than \"added\" or \"adds\"",
+	"body-leading-blank":   "leave the line after the header empty",
+	"body-max-line-length": "wrap the lines of the body",
+	"body-empty":           "explain what changed and why in the body",
+	"trailer-exists":       "add the footer at the end of the message, after a blank line, e.g. Refs: PROJ-123",
+}
+
+// Remediation returns a one line fix for the problems of rule, empty for
+// rules it doesn't know
+func Remediation(rule string) string {
+	return remediations[rule]
+}
@@ -0,0 +1,145 @@
+package conventional
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Subject cases for Rules.SubjectCase
+const (
+	// LowerCase requires the description to start with a lower case letter
+	LowerCase = "lower"
+	// SentenceCase requires the description to start with an upper case letter
+	SentenceCase = "sentence"
+)
+
+// emojiPattern matches an emoji or :emoji-code:
+var emojiPattern = regexp.MustCompile(`^(?::[\w+-]+:|[^\x00-\x7F]+)$`)
+
+// imperatives maps common past tense and third person verbs to their
+// imperative form
+var imperatives = map[string]string{
+	"added": "add", "adds": "add", "adding": "add",
+	"allowed": "allow", "allows": "allow", "allowing": "allow",
+	"bumped": "bump", "bumps": "bump", "bumping": "bump",
+	"changed": "change", "changes": "change", "changing": "change",
+	"cleaned": "clean", "cleans": "clean", "cleaning": "clean",
+	"created": "create", "creates": "create", "creating": "create",
+	"deleted": "delete", "deletes": "delete", "deleting": "delete",
+	"deprecated": "deprecate", "deprecates": "deprecate", "deprecating": "deprecate",
+	"disabled": "disable", "disables": "disable", "disabling": "disable",
+	"dropped": "drop", "drops": "drop", "dropping": "drop",
+	"enabled": "enable", "enables": "enable", "enabling": "enable",
+	"fixed": "fix", "fixes": "fix", "fixing": "fix",
+	"implemented": "implement", "implements": "implement", "implementing": "implement",
+	"improved": "improve", "improves": "improve", "improving": "improve",
+	"made": "make", "makes": "make", "making": "make",
+	"merged": "merge", "merges": "merge", "merging": "merge",
+	"moved": "move", "moves": "move", "moving": "move",
+	"refactored": "refactor", "refactors": "refactor", "refactoring": "refactor",
+	"removed": "remove", "removes": "remove", "removing": "remove",
+	"renamed": "rename", "renames": "rename", "renaming": "rename",
+	"replaced": "replace", "replaces": "replace", "replacing": "replace",
+	"reverted": "revert", "reverts": "revert", "reverting": "revert",
+	"supported": "support", "supports": "support", "supporting": "support",
+	"updated": "update", "updates": "update", "updating": "update",
+	"upgraded": "upgrade", "upgrades": "upgrade", "upgrading": "upgrade",
+	"used": "use", "uses": "use", "using": "use",
+}
+
+// words ending in -ed or -ing which are imperative anyway
+var imperativeExceptions = map[string]bool{
+	"bring": true, "embed": true, "feed": true, "need": true, "ping": true,
+	"proceed": true, "seed": true, "shed": true, "shred": true, "sing": true,
+	"speed": true, "spring": true, "string": true, "succeed": true, "swing": true,
+}
+
+// styleProblems checks the description of a header against the style rules,
+// columns are offset by start, the position of the description in the header
+func styleProblems(description string, start int, rules Rules) []Problem {
+	var problems []Problem
+
+	// a gitmoji in front of the description isn't part of the sentence
+	if word, rest, found := strings.Cut(description, " "); found && emojiPattern.MatchString(word) {
+		description, start = rest, start+len(word)+1
+	}
+	if description == "" {
+		return nil
+	}
+
+	first, _ := utf8.DecodeRuneInString(description)
+	switch {
+	case rules.SubjectCase == LowerCase && unicode.IsUpper(first) && !isAcronym(description):
+		problems = append(problems, Problem{Column: start, EndColumn: start + utf8.RuneLen(first), Message: "description must start with a lower case letter", Rule: "subject-case"})
+	case rules.SubjectCase == SentenceCase && unicode.IsLower(first):
+		problems = append(problems, Problem{Column: start, EndColumn: start + utf8.RuneLen(first), Message: "description must start with an upper case letter", Rule: "subject-case"})
+	}
+
+	if rules.NoTrailingPeriod && strings.HasSuffix(description, ".") {
+		problems = append(problems, Problem{Column: start + len(description) - 1, EndColumn: start + len(description), Message: "description must not end with a period", Rule: "subject-full-stop"})
+	}
+
+	if rules.Imperative {
+		word, _, _ := strings.Cut(description, " ")
+		if !imperative(word) {
+			message := "description should use the imperative mood"
+			if fix, ok := imperatives[strings.ToLower(word)]; ok {
+				message += ", e.g. " + fix + " instead of " + word
+			}
+			problems = append(problems, Problem{Column: start, EndColumn: start + len(word), Message: message, Rule: "subject-imperative"})
+		}
+	}
+
+	return problems
+}
+
+// imperative guesses whether word is a verb in the imperative mood
+func imperative(word string) bool {
+	word = strings.ToLower(word)
+	if _, ok := imperatives[word]; ok {
+		return false
+	}
+	if imperativeExceptions[word] {
+		return true
+	}
+	return !strings.HasSuffix(word, "ed") && !strings.HasSuffix(word, "ing")
+}
+
+// isAcronym reports whether description starts with a word in capitals,
+// such as API, which lower case descriptions may start with
+func isAcronym(description string) bool {
+	word, _, _ := strings.Cut(description, " ")
+	return utf8.RuneCountInString(word) > 1 && strings.ToUpper(word) == word
+}
+
+// FixDescription applies the fixable style rules to a description: its case,
+// a trailing period and well known non-imperative verbs
+func FixDescription(description string, rules Rules) string {
+	if rules.NoTrailingPeriod {
+		description = strings.TrimRight(description, ".")
+	}
+
+	if rules.Imperative {
+		word, rest, found := strings.Cut(description, " ")
+		if fix, ok := imperatives[strings.ToLower(word)]; ok {
+			if first, _ := utf8.DecodeRuneInString(word); unicode.IsUpper(first) {
+				fix = strings.ToUpper(fix[:1]) + fix[1:]
+			}
+			description = fix
+			if found {
+				description += " " + rest
+			}
+		}
+	}
+
+	first, size := utf8.DecodeRuneInString(description)
+	switch {
+	case rules.SubjectCase == LowerCase && unicode.IsUpper(first) && !isAcronym(description):
+		description = string(unicode.ToLower(first)) + description[size:]
+	case rules.SubjectCase == SentenceCase && unicode.IsLower(first):
+		description = string(unicode.ToUpper(first)) + description[size:]
+	}
+	return description
+}
@@ -0,0 +1,196 @@
+// Package conventional implements the rules git-cc enforces on commit
+// messages, so other tools can apply exactly the same checks.
+package conventional
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// HeaderPattern matches a header of the form type(scope)!: description,
+	// optionally preceded by an emoji or :emoji-code: and a space
+	HeaderPattern = regexp.MustCompile(`^(?:(?::[\w+-]+:|[^\x00-\x7F]+) )?(\w[\w-]*)(?:\(([^()\r\n]*)\))?(!)?: (.*)$`)
+	// FooterPattern matches a footer of the form token: value or token #value,
+	// BREAKING CHANGE being the only token with a space
+	FooterPattern = regexp.MustCompile(`^(BREAKING CHANGE|BREAKING-CHANGE|[\w-]+)(: | #)(.*)$`)
+)
+
+// Rules configures the types and scopes a message may use
+type Rules struct {
+	// Types allowed in the header, any type is allowed when empty
+	Types []string
+	// Scopes allowed in the header, any scope is allowed when empty. Unless
+	// Scopes contains "none" a scope is required.
+	Scopes []string
+	// MaxHeaderLength limits the characters of the header, 0 for no limit
+	MaxHeaderLength int
+	// MaxBodyLineLength limits the characters of each body line, 0 for no
+	// limit. Footers and lines without spaces, such as URLs, are exempt.
+	MaxBodyLineLength int
+	// SubjectCase is LowerCase or SentenceCase to require the description
+	// to start with a lower or upper case letter, any case when empty
+	SubjectCase string
+	// NoTrailingPeriod forbids a period at the end of the description
+	NoTrailingPeriod bool
+	// Imperative requires the description to start with a verb in the
+	// imperative mood, such as add instead of added or adds. This is a
+	// heuristic, it only recognizes well known verbs and -ed/-ing forms.
+	Imperative bool
+	// Conditions are rules for the messages of some types or scopes only
+	Conditions []Condition
+}
+
+// Problem describes a violation found in a commit message, positions are
+// zero based and point into the line the problem was found on.
+type Problem struct {
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndColumn int    `json:"end_column"`
+	Message   string `json:"message"`
+	// Rule names the violated rule, as commitlint does where it has one
+	Rule string `json:"rule"`
+	// Hint says how to fix the problem in one line
+	Hint string `json:"hint,omitempty"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d:%d: %s", p.Line+1, p.Column+1, p.Message)
+}
+
+// Validate checks a commit message against the Conventional Commits spec
+// and rules. Comment lines must have been removed beforehand.
+func Validate(message string, rules Rules) []Problem {
+	problems := validate(message, rules)
+	for i := range problems {
+		problems[i].Hint = Remediation(problems[i].Rule)
+	}
+	return problems
+}
+
+func validate(message string, rules Rules) []Problem {
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	header := lines[0]
+
+	if strings.TrimSpace(message) == "" {
+		return []Problem{{Message: "commit message is empty", Rule: "message-empty"}}
+	}
+
+	var problems []Problem
+
+	match := HeaderPattern.FindStringSubmatchIndex(header)
+	if match == nil {
+		problems = append(problems, Problem{
+			EndColumn: len(header),
+			Message:   "header must be of the form type(scope): description",
+			Rule:      "header-format",
+		})
+	} else {
+		commitType := header[match[2]:match[3]]
+		if len(rules.Types) > 0 && !slices.Contains(rules.Types, commitType) {
+			problems = append(problems, Problem{
+				Column:    match[2],
+				EndColumn: match[3],
+				Message:   fmt.Sprintf("type %q is not one of %s", commitType, strings.Join(rules.Types, ", ")),
+				Rule:      "type-enum",
+			})
+		}
+
+		if len(rules.Scopes) > 0 {
+			if match[4] < 0 && !slices.Contains(rules.Scopes, "none") {
+				problems = append(problems, Problem{
+					Column:    match[3],
+					EndColumn: match[3],
+					Message:   "scope is required",
+					Rule:      "scope-empty",
+				})
+			} else if match[4] >= 0 && !slices.Contains(rules.Scopes, header[match[4]:match[5]]) {
+				problems = append(problems, Problem{
+					Column:    match[4],
+					EndColumn: match[5],
+					Message:   fmt.Sprintf("scope %q is not one of %s", header[match[4]:match[5]], strings.Join(rules.Scopes, ", ")),
+					Rule:      "scope-enum",
+				})
+			}
+		}
+
+		if strings.TrimSpace(header[match[8]:match[9]]) == "" {
+			problems = append(problems, Problem{
+				Column:    match[8],
+				EndColumn: match[9],
+				Message:   "description must not be empty",
+				Rule:      "subject-empty",
+			})
+		}
+		problems = append(problems, styleProblems(header[match[8]:match[9]], match[8], rules)...)
+		problems = append(problems, conditionProblems(lines, match[2], match[3], match[4], match[5], rules.Conditions)...)
+	}
+
+	if rules.MaxHeaderLength > 0 && utf8.RuneCountInString(header) > rules.MaxHeaderLength {
+		problems = append(problems, Problem{
+			Column:    runeOffset(header, rules.MaxHeaderLength),
+			EndColumn: len(header),
+			Message:   fmt.Sprintf("header is %d characters long, at most %d are allowed", utf8.RuneCountInString(header), rules.MaxHeaderLength),
+			Rule:      "header-max-length",
+		})
+	}
+
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		problems = append(problems, Problem{
+			Line:      1,
+			EndColumn: len(lines[1]),
+			Message:   "header must be followed by a blank line",
+			Rule:      "body-leading-blank",
+		})
+	}
+
+	if rules.MaxBodyLineLength > 0 {
+		for i := 2; i < footerStart(lines); i++ {
+			line := lines[i]
+			if utf8.RuneCountInString(line) <= rules.MaxBodyLineLength || !strings.ContainsAny(strings.TrimSpace(line), " \t") {
+				continue
+			}
+			problems = append(problems, Problem{
+				Line:      i,
+				Column:    runeOffset(line, rules.MaxBodyLineLength),
+				EndColumn: len(line),
+				Message:   fmt.Sprintf("body line is %d characters long, at most %d are allowed", utf8.RuneCountInString(line), rules.MaxBodyLineLength),
+				Rule:      "body-max-line-length",
+			})
+		}
+	}
+
+	return problems
+}
+
+// footerStart returns the index of the first footer line, or the number of
+// lines if the message has no footers
+func footerStart(lines []string) int {
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	// footers form the last paragraph, which can't be the header
+	start := end
+	for start > 0 && strings.TrimSpace(lines[start-1]) != "" {
+		start--
+	}
+	if start > 1 && start < end && FooterPattern.MatchString(lines[start]) {
+		return start
+	}
+	return len(lines)
+}
+
+// runeOffset returns the byte offset of the n-th character of s
+func runeOffset(s string, n int) int {
+	for i := range s {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(s)
+}
@@ -0,0 +1,110 @@
+package conventional_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/45413/git-cc/pkg/conventional"
+)
+
+// ruleIDs returns the rules problems violate
+func ruleIDs(problems []conventional.Problem) []string {
+	ids := make([]string, 0, len(problems))
+	for _, p := range problems {
+		ids = append(ids, p.Rule)
+	}
+	return ids
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		rules   conventional.Rules
+		want    []string
+	}{
+		// the spec without rules
+		{name: "valid", message: "feat: add login"},
+		{name: "valid with body and footers", message: "fix(api)!: drop v1\n\nWhy.\n\nBREAKING CHANGE: v1 is gone\nCloses #12"},
+		{name: "empty", message: "  \n", want: []string{"message-empty"}},
+		{name: "malformed header", message: "add login", want: []string{"header-format"}},
+		{name: "empty description", message: "feat: ", want: []string{"subject-empty"}},
+		{name: "missing blank line", message: "feat: add login\nbody", want: []string{"body-leading-blank"}},
+
+		// type-enum
+		{name: "allowed type", message: "fix: x", rules: conventional.Rules{Types: []string{"feat", "fix"}}},
+		{name: "unknown type", message: "perf: x", rules: conventional.Rules{Types: []string{"feat", "fix"}}, want: []string{"type-enum"}},
+
+		// scope-empty and scope-enum
+		{name: "allowed scope", message: "feat(api): x", rules: conventional.Rules{Scopes: []string{"api", "ui"}}},
+		{name: "missing scope", message: "feat: x", rules: conventional.Rules{Scopes: []string{"api", "ui"}}, want: []string{"scope-empty"}},
+		{name: "optional scope", message: "feat: x", rules: conventional.Rules{Scopes: []string{"none", "api"}}},
+		{name: "unknown scope", message: "feat(db): x", rules: conventional.Rules{Scopes: []string{"none", "api"}}, want: []string{"scope-enum"}},
+
+		// header-max-length
+		{name: "header at the limit", message: "feat: 1234567890", rules: conventional.Rules{MaxHeaderLength: 16}},
+		{name: "header over the limit", message: "feat: 12345678901", rules: conventional.Rules{MaxHeaderLength: 16}, want: []string{"header-max-length"}},
+		{name: "header length counts characters", message: "feat: äöü", rules: conventional.Rules{MaxHeaderLength: 9}},
+
+		// body-max-line-length
+		{name: "body line within the limit", message: "feat: x\n\nshort line", rules: conventional.Rules{MaxBodyLineLength: 10}},
+		{name: "body line over the limit", message: "feat: x\n\nmuch too long line", rules: conventional.Rules{MaxBodyLineLength: 10}, want: []string{"body-max-line-length"}},
+		{name: "long URLs are exempt", message: "feat: x\n\nhttps://example.com/a/long/path", rules: conventional.Rules{MaxBodyLineLength: 10}},
+		{name: "footers are exempt", message: "feat: x\n\nReviewed-by: Jane Doe <jane@example.com>", rules: conventional.Rules{MaxBodyLineLength: 10}},
+
+		// subject-case
+		{name: "lower case", message: "feat: add x", rules: conventional.Rules{SubjectCase: conventional.LowerCase}},
+		{name: "upper case where lower is required", message: "feat: Add x", rules: conventional.Rules{SubjectCase: conventional.LowerCase}, want: []string{"subject-case"}},
+		{name: "acronym where lower is required", message: "feat: API keys", rules: conventional.Rules{SubjectCase: conventional.LowerCase}},
+		{name: "sentence case", message: "feat: Add x", rules: conventional.Rules{SubjectCase: conventional.SentenceCase}},
+		{name: "lower case where sentence is required", message: "feat: add x", rules: conventional.Rules{SubjectCase: conventional.SentenceCase}, want: []string{"subject-case"}},
+
+		// subject-full-stop
+		{name: "no trailing period", message: "feat: add x", rules: conventional.Rules{NoTrailingPeriod: true}},
+		{name: "trailing period", message: "feat: add x.", rules: conventional.Rules{NoTrailingPeriod: true}, want: []string{"subject-full-stop"}},
+		{name: "trailing period allowed", message: "feat: add x."},
+
+		// subject-imperative
+		{name: "imperative", message: "feat: add x", rules: conventional.Rules{Imperative: true}},
+		{name: "past tense", message: "feat: added x", rules: conventional.Rules{Imperative: true}, want: []string{"subject-imperative"}},
+
+		{
+			name:    "several problems",
+			message: "perf: Add x.",
+			rules:   conventional.Rules{Types: []string{"feat"}, SubjectCase: conventional.LowerCase, NoTrailingPeriod: true, MaxHeaderLength: 5},
+			want:    []string{"type-enum", "subject-case", "subject-full-stop", "header-max-length"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := conventional.Validate(tt.message, tt.rules)
+			if got := ruleIDs(problems); !slices.Equal(got, tt.want) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.message, problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePositions(t *testing.T) {
+	problems := conventional.Validate("feat(db): Add x", conventional.Rules{Scopes: []string{"api"}, SubjectCase: conventional.LowerCase})
+	want := []conventional.Problem{
+		{Line: 0, Column: 5, EndColumn: 7, Rule: "scope-enum"},
+		{Line: 0, Column: 10, EndColumn: 11, Rule: "subject-case"},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("Validate = %v, want %d problems", problems, len(want))
+	}
+	for i, p := range problems {
+		if p.Line != want[i].Line || p.Column != want[i].Column || p.EndColumn != want[i].EndColumn || p.Rule != want[i].Rule {
+			t.Errorf("problem %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+}
+
+func TestValidateHints(t *testing.T) {
+	for _, p := range conventional.Validate("perf: x", conventional.Rules{Types: []string{"feat"}}) {
+		if p.Hint == "" {
+			t.Errorf("problem %v has no hint", p)
+		}
+	}
+}
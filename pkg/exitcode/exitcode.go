@@ -0,0 +1,83 @@
+// Package exitcode defines the exit codes of git-cc and an error carrying
+// one, so wrapper scripts and Go tooling can tell its failures apart.
+package exitcode
+
+import "errors"
+
+// The exit codes of git-cc. With git_exit_codes those of git commit are
+// used instead.
+const (
+	OK = 0
+	// Failure is any error without a code of its own
+	Failure = 1
+	// NothingStaged is returned when nothing is staged or queued to commit
+	NothingStaged = 2
+	// CommitFailed is returned when git commit or rewriting a commit failed,
+	// e.g. because a hook rejected it
+	CommitFailed = 3
+	// Aborted is returned when the user aborted the commit
+	Aborted = 4
+	// Invalid is returned when a message doesn't follow the rules
+	Invalid = 5
+	// Config is returned when the config has an invalid value
+	Config = 6
+	// Interrupted is returned for ctrl+c, other signals exit with 128+n as
+	// in the shell
+	Interrupted = 130
+)
+
+// names are the machine-readable names of the exit codes
+var names = map[int]string{
+	OK:            "ok",
+	Failure:       "error",
+	NothingStaged: "nothing-staged",
+	CommitFailed:  "commit-failed",
+	Aborted:       "aborted",
+	Invalid:       "invalid",
+	Config:        "config",
+	Interrupted:   "interrupted",
+}
+
+// Name returns the name of code, such as nothing-staged, or signal for the
+// exit codes of other signals
+func Name(code int) string {
+	if name, ok := names[code]; ok {
+		return name
+	}
+	if code > 128 {
+		return "signal"
+	}
+	return names[Failure]
+}
+
+// Error is an error ending git-cc with Code
+type Error struct {
+	Code int
+	Err  error
+}
+
+// New returns err with the exit code code
+func New(code int, err error) error {
+	return &Error{Code: code, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the exit code of err: OK for nil, the code of an Error it
+// wraps, else Failure
+func Code(err error) int {
+	if err == nil {
+		return OK
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return Failure
+}
@@ -0,0 +1,11 @@
+// Package locales embeds the translations of git-cc's prompts and messages
+package locales
+
+import "embed"
+
+// Catalogs holds a <language>.json file per language, mapping the English
+// strings to their translation. English, the language of the source, has
+// none.
+//
+//go:embed *.json
+var Catalogs embed.FS
@@ -0,0 +1,10 @@
+// Package man embeds the git-cc(1) manual page, so git cc help can show it
+// where the man page isn't installed
+package man
+
+import _ "embed"
+
+// Page is the Markdown source of the manual page
+//
+//go:embed git-cc.1.md
+var Page string